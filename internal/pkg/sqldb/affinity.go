@@ -0,0 +1,39 @@
+package sqldb
+
+import (
+	"context"
+	"hash/fnv"
+)
+
+type affinityKey struct{}
+
+// WithAffinityKey make eventual reads using the context always go to the same follower for the same key,
+// for example the entity id, to improve the cache hit rate of the follower
+// the mapping is stable as long as the followers list is not changed
+func WithAffinityKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, affinityKey{}, key)
+}
+
+func affinityKeyFromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(affinityKey{}).(string)
+	return key, ok
+}
+
+// affinityFollower select follower in the primary tier by the hash of the key
+func (db *DB) affinityFollower(key string) *followerDB {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+
+	db.followersMu.RLock()
+	defer db.followersMu.RUnlock()
+	candidates := db.followers
+	if len(db.tiers) > 1 {
+		candidates = make([]*followerDB, 0, len(db.followers))
+		for _, f := range db.followers {
+			if f.tier == db.tiers[0] {
+				candidates = append(candidates, f)
+			}
+		}
+	}
+	return candidates[h.Sum32()%uint32(len(candidates))]
+}
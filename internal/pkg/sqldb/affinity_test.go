@@ -0,0 +1,52 @@
+package sqldb
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func TestAffinityKey(t *testing.T) {
+	t.Parallel()
+
+	db, _, _ := newFakeDB(t)
+	servers := make([]*fakeServer, 3)
+	followers := make([]*sqlx.DB, 3)
+	for i := range servers {
+		servers[i], followers[i] = newFakeServer(t, fmt.Sprintf("follower-%d", i))
+	}
+	if err := db.SetFollowers(followers); err != nil {
+		t.Fatal(err)
+	}
+
+	// the same key always go to the same follower
+	ctx := WithAffinityKey(context.Background(), "user:42")
+	first, _ := db.reader(ctx)
+	for i := 0; i < 10; i++ {
+		if reader, _ := db.reader(ctx); reader != first {
+			t.Fatal("expecting the same key to select the same follower")
+		}
+	}
+
+	// keys are distributed across followers
+	selected := make(map[*sqlx.DB]int)
+	for i := 0; i < 300; i++ {
+		reader, target := db.reader(WithAffinityKey(context.Background(), fmt.Sprintf("user:%d", i)))
+		if target != targetFollower {
+			t.Fatalf("expecting follower target, got %s", target)
+		}
+		selected[reader]++
+	}
+	for i, f := range followers {
+		if selected[f] == 0 {
+			t.Errorf("expecting follower %d to be selected by some keys", i)
+		}
+	}
+
+	// strong consistency still read from leader
+	if _, target := db.reader(WithConsistency(ctx, Strong)); target != targetLeader {
+		t.Errorf("expecting leader target for strong read, got %s", target)
+	}
+}
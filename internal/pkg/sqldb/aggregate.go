@@ -0,0 +1,29 @@
+package sqldb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// GetAggregate scan a single aggregate value like SUM or MAX into dest that must be a pointer to scalar, for example *int64
+// aggregate over zero rows return NULL, the NULL is scanned as the zero value of dest instead of returning error
+// this is the same as wrapping the aggregate with COALESCE(SUM(amount), 0)
+func (db *DB) GetAggregate(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	value := reflect.ValueOf(dest)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return fmt.Errorf("sqldb: GetAggregate destination must be a non-nil pointer, got %T", dest)
+	}
+
+	// scan into pointer of dest type, so NULL is scanned as nil pointer
+	nullable := reflect.New(reflect.PtrTo(value.Elem().Type()))
+	if err := db.GetContext(ctx, nullable.Interface(), query, args...); err != nil {
+		return err
+	}
+	if nullable.Elem().IsNil() {
+		value.Elem().Set(reflect.Zero(value.Elem().Type()))
+		return nil
+	}
+	value.Elem().Set(nullable.Elem().Elem())
+	return nil
+}
@@ -0,0 +1,42 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+)
+
+func TestGetAggregate(t *testing.T) {
+	t.Parallel()
+
+	db, _, follower := newFakeDB(t)
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		if query == "SELECT SUM(amount) FROM orders WHERE user_id = 1" {
+			return fakeResult{columns: []string{"sum"}, rows: [][]driver.Value{{int64(42)}}}
+		}
+		// sum over empty set
+		return fakeResult{columns: []string{"sum"}, rows: [][]driver.Value{{nil}}}
+	})
+
+	total := int64(10)
+	if err := db.GetAggregate(context.Background(), &total, "SELECT SUM(amount) FROM orders WHERE user_id = 2"); err != nil {
+		t.Fatal(err)
+	}
+	if total != 0 {
+		t.Errorf("expecting 0, got %d", total)
+	}
+	if err := db.GetAggregate(context.Background(), &total, "SELECT SUM(amount) FROM orders WHERE user_id = 1"); err != nil {
+		t.Fatal(err)
+	}
+	if total != 42 {
+		t.Errorf("expecting 42, got %d", total)
+	}
+
+	// plain scan of NULL fail
+	if err := db.GetContext(context.Background(), &total, "SELECT SUM(amount) FROM orders WHERE user_id = 2"); err == nil {
+		t.Error("expecting GetContext to fail scanning NULL to int64")
+	}
+	if err := db.GetAggregate(context.Background(), total, "SELECT SUM(amount) FROM orders"); err == nil {
+		t.Error("expecting error for non-pointer destination")
+	}
+}
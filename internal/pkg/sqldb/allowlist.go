@@ -0,0 +1,29 @@
+package sqldb
+
+import "errors"
+
+var errQueryNotAllowed = errors.New("sqldb: query not in allowlist")
+
+// newAllowlist create the set of allowed fingerprint
+// the entries is fingerprinted again, so example query can also be used as an entry
+func newAllowlist(entries []string) map[string]struct{} {
+	if len(entries) == 0 {
+		return nil
+	}
+	allowlist := make(map[string]struct{}, len(entries))
+	for _, e := range entries {
+		allowlist[Fingerprint(e)] = struct{}{}
+	}
+	return allowlist
+}
+
+// checkAllowlist return error if the allowlist is enabled and the query fingerprint is not in the allowlist
+func (db *DB) checkAllowlist(q *queryInfo) error {
+	if db.allowlist == nil {
+		return nil
+	}
+	if _, ok := db.allowlist[q.fingerprint()]; !ok {
+		return errQueryNotAllowed
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+)
+
+func TestQueryAllowlist(t *testing.T) {
+	t.Parallel()
+
+	db, leader, follower := newFakeDB(t, WithQueryAllowlist([]string{
+		Fingerprint("SELECT id FROM users WHERE name = 'alice'"),
+	}))
+	handler := func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}}
+	}
+	leader.setHandler(handler)
+	follower.setHandler(handler)
+
+	cases := []struct {
+		query string
+		err   error
+	}{
+		{query: "SELECT id FROM users WHERE name = 'alice'"},
+		{query: "SELECT id FROM users WHERE name = 'bob'"},
+		{query: "select id from users where name = ?"},
+		{query: "SELECT id, password FROM users WHERE name = 'alice'", err: errQueryNotAllowed},
+		{query: "SELECT id FROM users", err: errQueryNotAllowed},
+	}
+	for _, c := range cases {
+		var id int
+		if err := db.GetContext(context.Background(), &id, c.query); err != c.err {
+			t.Errorf("%s: expecting error %v, got %v", c.query, c.err, err)
+		}
+	}
+
+	// rejected QueryRow return the error on Scan instead of a nil row
+	var id int
+	if err := db.QueryRowContext(context.Background(), "SELECT id FROM users").Scan(&id); err != errQueryNotAllowed {
+		t.Errorf("expecting QueryRow to be rejected on Scan, got %v", err)
+	}
+	if err := db.QueryRowContext(context.Background(), "SELECT id FROM users WHERE name = 'alice'").Scan(&id); err != nil || id != 1 {
+		t.Errorf("expecting allowed QueryRow to return the row, got %d and %v", id, err)
+	}
+	snapshot, closeSnapshot, err := db.ReadOnlySnapshot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeSnapshot()
+	if err := snapshot.QueryRowContext(context.Background(), "SELECT id FROM users").Scan(&id); err != errQueryNotAllowed {
+		t.Errorf("expecting snapshot QueryRow to be rejected on Scan, got %v", err)
+	}
+
+	if _, err := db.ExecContext(context.Background(), "DELETE FROM users WHERE id = 1"); err != errQueryNotAllowed {
+		t.Errorf("expecting exec to be rejected, got %v", err)
+	}
+	if n := leader.count("DELETE FROM users WHERE id = 1"); n != 0 {
+		t.Errorf("expecting rejected query to not reach the database, got %d", n)
+	}
+}
@@ -0,0 +1,131 @@
+package sqldb
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/albertwidi/go-project-example/internal/pkg/log/logger"
+)
+
+// asyncBatchSize is the maximum number of async exec executed in one transaction
+const asyncBatchSize = 100
+
+var (
+	errAsyncNotEnabled = errors.New("sqldb: async exec is not enabled")
+	errAsyncQueueFull  = errors.New("sqldb: async exec queue is full")
+	errAsyncClosed     = errors.New("sqldb: async exec queue is closed")
+)
+
+type asyncExec struct {
+	query string
+	args  []interface{}
+}
+
+// asyncQueue is the bounded queue of async exec
+type asyncQueue struct {
+	// mu protect the queue from being closed while a write is enqueued
+	mu     sync.RWMutex
+	closed bool
+	queue  chan asyncExec
+	wg     sync.WaitGroup
+}
+
+func (db *DB) startAsyncWorkers(size, workers int) {
+	if workers <= 0 {
+		workers = 1
+	}
+	db.async.queue = make(chan asyncExec, size)
+	db.async.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go db.asyncWorker()
+	}
+}
+
+// ExecAsync enqueue the query to be executed in leader in the background and return immediately
+// this is for non-critical writes, the error of the query is only logged
+// error is returned when the queue is full, the pending queries is flushed on Close
+func (db *DB) ExecAsync(query string, args ...interface{}) error {
+	if err := db.checkMutation(context.Background(), query); err != nil {
+		return err
+	}
+
+	db.async.mu.RLock()
+	defer db.async.mu.RUnlock()
+	if db.async.queue == nil {
+		return errAsyncNotEnabled
+	}
+	if db.async.closed {
+		return errAsyncClosed
+	}
+
+	select {
+	case db.async.queue <- asyncExec{query: query, args: args}:
+		return nil
+	default:
+		return errAsyncQueueFull
+	}
+}
+
+// closeAsync stop accepting async exec and wait until all pending queries is executed
+func (db *DB) closeAsync() {
+	db.async.mu.Lock()
+	if db.async.queue == nil || db.async.closed {
+		db.async.mu.Unlock()
+		return
+	}
+	db.async.closed = true
+	close(db.async.queue)
+	db.async.mu.Unlock()
+
+	db.async.wg.Wait()
+}
+
+func (db *DB) asyncWorker() {
+	defer db.async.wg.Done()
+
+	for e := range db.async.queue {
+		batch := []asyncExec{e}
+	drain:
+		for len(batch) < asyncBatchSize {
+			select {
+			case e, ok := <-db.async.queue:
+				if !ok {
+					break drain
+				}
+				batch = append(batch, e)
+			default:
+				break drain
+			}
+		}
+		db.execAsyncBatch(batch)
+	}
+}
+
+// execAsyncBatch execute the batch in one transaction
+// when the transaction is failed, the queries is executed one by one so only the failed query is dropped
+func (db *DB) execAsyncBatch(batch []asyncExec) {
+	ctx := context.Background()
+	if len(batch) > 1 {
+		err := db.WithTransaction(ctx, nil, func(ctx context.Context, tx *Tx) error {
+			for _, e := range batch {
+				if _, err := tx.ExecContext(ctx, e.query, e.args...); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err == nil {
+			return
+		}
+	}
+
+	for _, e := range batch {
+		if _, err := db.ExecContext(ctx, e.query, e.args...); err != nil && db.opts.Logger != nil {
+			db.opts.Logger.Warnw("sqldb: async exec failed", logger.KV{
+				"fingerprint": Fingerprint(e.query),
+				"error":       err.Error(),
+			})
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+)
+
+func TestExecAsync(t *testing.T) {
+	t.Parallel()
+
+	db, leader, _ := newFakeDB(t, WithAsyncExec(1000, 4))
+
+	const query = "INSERT INTO events(name) VALUES(?)"
+	for i := 0; i < 500; i++ {
+		if err := db.ExecAsync(query, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// close flush all pending writes
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := leader.count(query); n != 500 {
+		t.Errorf("expecting 500 writes, got %d", n)
+	}
+	if err := db.ExecAsync(query, 0); err != errAsyncClosed {
+		t.Errorf("expecting errAsyncClosed, got %v", err)
+	}
+}
+
+func TestExecAsyncQueueFull(t *testing.T) {
+	t.Parallel()
+
+	db, leader, _ := newFakeDB(t, WithAsyncExec(1, 1))
+	block := make(chan struct{})
+	leader.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		<-block
+		return fakeResult{}
+	})
+
+	var err error
+	for i := 0; i < 10 && err == nil; i++ {
+		err = db.ExecAsync("INSERT INTO events(name) VALUES(?)", i)
+	}
+	if err != errAsyncQueueFull {
+		t.Errorf("expecting errAsyncQueueFull, got %v", err)
+	}
+
+	close(block)
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExecAsyncNotEnabled(t *testing.T) {
+	t.Parallel()
+
+	db, _, _ := newFakeDB(t)
+	if err := db.ExecAsync("INSERT INTO events(name) VALUES(?)", 1); err != errAsyncNotEnabled {
+		t.Errorf("expecting errAsyncNotEnabled, got %v", err)
+	}
+}
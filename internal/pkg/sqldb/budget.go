@@ -0,0 +1,160 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var errByteBudgetExceeded = errors.New("sqldb: request byte budget exceeded")
+
+// byteBudget is the maximum bytes scanned by all queries using the context
+type byteBudget struct {
+	limit int64
+	used  int64
+}
+
+// consume add n bytes to the budget, return error if the budget is exceeded
+func (b *byteBudget) consume(n int64) error {
+	if atomic.AddInt64(&b.used, n) > b.limit {
+		return errByteBudgetExceeded
+	}
+	return nil
+}
+
+type byteBudgetKey struct{}
+
+// WithByteBudget limit the total bytes scanned by Get and Select using the context to n bytes
+// the size of a row is estimated from the length of string and []byte columns, and the memory size of other columns
+// the scan is stopped with error when the budget is exceeded
+func WithByteBudget(ctx context.Context, n int64) context.Context {
+	return context.WithValue(ctx, byteBudgetKey{}, &byteBudget{limit: n})
+}
+
+func byteBudgetFromContext(ctx context.Context) (*byteBudget, bool) {
+	b, ok := ctx.Value(byteBudgetKey{}).(*byteBudget)
+	return b, ok
+}
+
+// getWithBudget is GetContext that account the scanned bytes to the budget
+func getWithBudget(ctx context.Context, reader *sqlx.DB, budget *byteBudget, dest interface{}, query string, args ...interface{}) error {
+	value := reflect.ValueOf(dest)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return fmt.Errorf("sqldb: destination must be a non-nil pointer, got %T", dest)
+	}
+
+	rows, err := reader.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+	if err := scanBudgeted(rows, budget, value.Elem()); err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+// scanSliceBudgeted scan all rows into the dest slice and account the scanned bytes to the budget
+func scanSliceBudgeted(rows *sqlx.Rows, budget *byteBudget, dest interface{}) error {
+	value := reflect.ValueOf(dest)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("sqldb: destination must be a pointer to a slice, got %T", dest)
+	}
+
+	var (
+		slice    = value.Elem()
+		elemType = slice.Type().Elem()
+		isPtr    = elemType.Kind() == reflect.Ptr
+	)
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+	for rows.Next() {
+		elem := reflect.New(elemType)
+		if err := scanBudgeted(rows, budget, elem.Elem()); err != nil {
+			return err
+		}
+		if isPtr {
+			slice.Set(reflect.Append(slice, elem))
+		} else {
+			slice.Set(reflect.Append(slice, elem.Elem()))
+		}
+	}
+	return rows.Err()
+}
+
+// scanBudgeted scan the current row into v and consume the row size from the budget
+// v is scanned as a struct using the rows mapper, unless it is a scanner or has no mapped field
+func scanBudgeted(rows *sqlx.Rows, budget *byteBudget, v reflect.Value) error {
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	var fields []interface{}
+	if _, ok := v.Addr().Interface().(sql.Scanner); ok || v.Kind() != reflect.Struct || len(rows.Mapper.TypeMap(v.Type()).Index) == 0 {
+		if len(columns) != 1 {
+			return fmt.Errorf("sqldb: non-struct destination %s with %d columns", v.Type(), len(columns))
+		}
+		fields = []interface{}{v.Addr().Interface()}
+	} else {
+		traversals := rows.Mapper.TraversalsByName(v.Type(), columns)
+		fields = make([]interface{}, len(columns))
+		for i, traversal := range traversals {
+			if len(traversal) == 0 {
+				return fmt.Errorf("sqldb: missing destination name %s in %s", columns[i], v.Type())
+			}
+			fields[i] = fieldByIndexes(v, traversal).Addr().Interface()
+		}
+	}
+
+	if err := rows.Scan(fields...); err != nil {
+		return err
+	}
+	var size int64
+	for _, f := range fields {
+		size += valueSize(reflect.ValueOf(f).Elem())
+	}
+	return budget.consume(size)
+}
+
+// fieldByIndexes return the field of v by the traversal, nil pointer of embedded struct is allocated
+func fieldByIndexes(v reflect.Value, indexes []int) reflect.Value {
+	for _, i := range indexes {
+		v = reflect.Indirect(v).Field(i)
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+	}
+	return v
+}
+
+// valueSize estimate the size of scanned value in bytes
+func valueSize(v reflect.Value) int64 {
+	switch v.Kind() {
+	case reflect.String:
+		return int64(v.Len())
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return int64(v.Len())
+		}
+	case reflect.Ptr:
+		if v.IsNil() {
+			return 0
+		}
+		return valueSize(v.Elem())
+	}
+	return int64(v.Type().Size())
+}
@@ -0,0 +1,70 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+)
+
+func TestByteBudget(t *testing.T) {
+	t.Parallel()
+
+	db, _, follower := newFakeDB(t)
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		// every row is 10 bytes
+		return fakeResult{
+			columns: []string{"name", "bio"},
+			rows:    [][]driver.Value{{"alice", "hello"}, {"bobby", []byte("world")}},
+		}
+	})
+
+	type user struct {
+		Name string `db:"name"`
+		Bio  []byte `db:"bio"`
+	}
+
+	ctx := WithByteBudget(context.Background(), 35)
+	var users []user
+	if err := db.SelectContext(ctx, &users, "SELECT name, bio FROM users"); err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 || users[0].Name != "alice" || string(users[1].Bio) != "world" {
+		t.Errorf("unexpected users %+v", users)
+	}
+
+	var u user
+	if err := db.GetContext(ctx, &u, "SELECT name, bio FROM users LIMIT 1"); err != nil {
+		t.Fatalf("expecting get within budget, got %v", err)
+	}
+
+	// the budget is exceeded mid-scan by the second row
+	var more []*user
+	if err := db.SelectContext(ctx, &more, "SELECT name, bio FROM users"); err != errByteBudgetExceeded {
+		t.Errorf("expecting errByteBudgetExceeded, got %v", err)
+	}
+
+	// other context is not affected
+	if err := db.SelectContext(context.Background(), &more, "SELECT name, bio FROM users"); err != nil {
+		t.Errorf("expecting no budget without WithByteBudget, got %v", err)
+	}
+}
+
+func TestByteBudgetScalar(t *testing.T) {
+	t.Parallel()
+
+	db, _, follower := newFakeDB(t)
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}, {int64(2)}, {int64(3)}}}
+	})
+
+	var ids []int64
+	if err := db.SelectContext(WithByteBudget(context.Background(), 24), &ids, "SELECT id FROM users"); err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 3 || ids[2] != 3 {
+		t.Errorf("unexpected ids %v", ids)
+	}
+	if err := db.SelectContext(WithByteBudget(context.Background(), 16), &ids, "SELECT id FROM users"); err != errByteBudgetExceeded {
+		t.Errorf("expecting errByteBudgetExceeded, got %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package sqldb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var errBulkUpdateNoValues = errors.New("sqldb: bulk update values cannot be empty")
+
+// BulkUpdate update each row of table with keyCol equal to the key of updates to its own column values
+// the rows is updated in one UPDATE ... SET col = CASE keyCol WHEN ? THEN ? ... END WHERE keyCol IN (...) statement
+// column that is not set for a row keep its value. When the number of parameters exceed the limit,
+// the rows is split into multiple statements that is executed separately, the total rows affected is returned
+func (db *DB) BulkUpdate(ctx context.Context, table, keyCol string, updates map[interface{}]map[string]interface{}) (int64, error) {
+	if len(updates) == 0 {
+		return 0, errBulkUpdateNoValues
+	}
+	if err := validateIdentifier(table, keyCol); err != nil {
+		return 0, err
+	}
+
+	keys := make([]interface{}, 0, len(updates))
+	for key, values := range updates {
+		if len(values) == 0 {
+			return 0, fmt.Errorf("sqldb: bulk update of key %v has no column", key)
+		}
+		for col := range values {
+			if err := validateIdentifier(col); err != nil {
+				return 0, err
+			}
+		}
+		keys = append(keys, key)
+	}
+	// sort the keys so the same updates always produce the same statement
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+	})
+
+	var (
+		limit      = db.maxQueryParams()
+		total      int64
+		batch      []interface{}
+		paramCount int
+	)
+	flush := func() error {
+		query, args := db.bulkUpdateQuery(table, keyCol, batch, updates)
+		result, err := db.ExecContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		total += affected
+		batch, paramCount = batch[:0], 0
+		return nil
+	}
+	for _, key := range keys {
+		// each column take a WHEN and THEN parameter, and the key take one parameter in IN
+		rowParams := len(updates[key])*2 + 1
+		if rowParams > limit {
+			return total, fmt.Errorf("sqldb: too many parameters for bulk update of key %v, limit is %d", key, limit)
+		}
+		if paramCount+rowParams > limit {
+			if err := flush(); err != nil {
+				return total, err
+			}
+		}
+		batch = append(batch, key)
+		paramCount += rowParams
+	}
+	if err := flush(); err != nil {
+		return total, err
+	}
+	return total, nil
+}
+
+// bulkUpdateQuery build a rebound bulk update query of keys
+func (db *DB) bulkUpdateQuery(table, keyCol string, keys []interface{}, updates map[interface{}]map[string]interface{}) (string, []interface{}) {
+	columnSet := make(map[string]bool)
+	for _, key := range keys {
+		for col := range updates[key] {
+			columnSet[col] = true
+		}
+	}
+	columns := make([]string, 0, len(columnSet))
+	for col := range columnSet {
+		columns = append(columns, col)
+	}
+	sort.Strings(columns)
+
+	var (
+		quotedKey = db.QuoteIdentifier(keyCol)
+		sets      = make([]string, len(columns))
+		args      []interface{}
+	)
+	for i, col := range columns {
+		quoted := db.QuoteIdentifier(col)
+		var set strings.Builder
+		fmt.Fprintf(&set, "%s = CASE %s", quoted, quotedKey)
+		for _, key := range keys {
+			value, ok := updates[key][col]
+			if !ok {
+				continue
+			}
+			set.WriteString(" WHEN ? THEN ?")
+			args = append(args, key, value)
+		}
+		fmt.Fprintf(&set, " ELSE %s END", quoted)
+		sets[i] = set.String()
+	}
+	args = append(args, keys...)
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(keys)), ", ")
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s IN (%s)", db.QuoteIdentifier(table), strings.Join(sets, ", "), quotedKey, placeholders)
+	return db.Rebind(query), args
+}
@@ -0,0 +1,133 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// bulkUpdateTable apply the bulk update statement to rows, to assert the value of each row
+type bulkUpdateTable struct {
+	mu         sync.Mutex
+	rows       map[int64]map[string]driver.Value
+	statements int
+}
+
+var bulkUpdateSetRegex = regexp.MustCompile(`(\w+) = CASE id((?: WHEN \? THEN \?)+) ELSE \w+ END`)
+
+func (bt *bulkUpdateTable) handle(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+	bt.mu.Lock()
+	defer bt.mu.Unlock()
+	bt.statements++
+
+	sets := bulkUpdateSetRegex.FindAllStringSubmatch(query, -1)
+	i := 0
+	updates := make(map[int64]map[string]driver.Value)
+	for _, set := range sets {
+		for n := strings.Count(set[2], "WHEN"); n > 0; n-- {
+			key := args[i].Value.(int64)
+			if updates[key] == nil {
+				updates[key] = make(map[string]driver.Value)
+			}
+			updates[key][set[1]] = args[i+1].Value
+			i += 2
+		}
+	}
+
+	var affected int64
+	for _, arg := range args[i:] {
+		row, ok := bt.rows[arg.Value.(int64)]
+		if !ok {
+			continue
+		}
+		for col, value := range updates[arg.Value.(int64)] {
+			row[col] = value
+		}
+		affected++
+	}
+	return fakeResult{rowsAffected: affected}
+}
+
+func newBulkUpdateTable() *bulkUpdateTable {
+	return &bulkUpdateTable{rows: map[int64]map[string]driver.Value{
+		1: {"name": "a", "status": "new"},
+		2: {"name": "b", "status": "new"},
+		3: {"name": "c", "status": "new"},
+	}}
+}
+
+func TestBulkUpdate(t *testing.T) {
+	t.Parallel()
+
+	db, leader, _ := newFakeDB(t)
+	table := newBulkUpdateTable()
+	leader.setHandler(table.handle)
+
+	affected, err := db.BulkUpdate(context.Background(), "users", "id", map[interface{}]map[string]interface{}{
+		int64(1): {"name": "alice", "status": "active"},
+		int64(2): {"status": "blocked"},
+		int64(3): {"name": "carol"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if affected != 3 {
+		t.Errorf("expecting 3 rows affected, got %d", affected)
+	}
+	if table.statements != 1 {
+		t.Errorf("expecting 1 statement, got %d", table.statements)
+	}
+
+	expect := map[int64]map[string]driver.Value{
+		1: {"name": "alice", "status": "active"},
+		2: {"name": "b", "status": "blocked"},
+		3: {"name": "carol", "status": "new"},
+	}
+	if !reflect.DeepEqual(table.rows, expect) {
+		t.Errorf("expecting rows %v, got %v", expect, table.rows)
+	}
+
+	query := "UPDATE users SET name = CASE id WHEN ? THEN ? WHEN ? THEN ? ELSE name END, status = CASE id WHEN ? THEN ? WHEN ? THEN ? ELSE status END WHERE id IN (?, ?, ?)"
+	if leader.count(query) != 1 {
+		t.Errorf("expecting query %s, got %v", query, leader.Queries())
+	}
+}
+
+func TestBulkUpdateBatch(t *testing.T) {
+	t.Parallel()
+
+	// each row take 3 parameters, so only one row fit in a statement
+	db, leader, _ := newFakeDB(t, WithMaxQueryParams(5))
+	table := newBulkUpdateTable()
+	leader.setHandler(table.handle)
+
+	affected, err := db.BulkUpdate(context.Background(), "users", "id", map[interface{}]map[string]interface{}{
+		int64(1): {"status": "active"},
+		int64(2): {"status": "blocked"},
+		int64(3): {"status": "active"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if affected != 3 {
+		t.Errorf("expecting 3 rows affected, got %d", affected)
+	}
+	if table.statements != 3 {
+		t.Errorf("expecting 3 statements, got %d", table.statements)
+	}
+	for id, status := range map[int64]string{1: "active", 2: "blocked", 3: "active"} {
+		if got := table.rows[id]["status"]; got != status {
+			t.Errorf("row %d: expecting status %s, got %v", id, status, got)
+		}
+	}
+
+	if _, err := db.BulkUpdate(context.Background(), "users", "id", map[interface{}]map[string]interface{}{
+		int64(1): {"name": "alice", "status": "active", "email": "alice@example.com"},
+	}); err == nil {
+		t.Error("expecting error when one row exceed the parameter limit")
+	}
+}
@@ -0,0 +1,119 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jmoiron/sqlx/reflectx"
+)
+
+var errBulkUpsertNoRows = errors.New("sqldb: bulk upsert rows cannot be empty")
+
+var _valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+
+// BulkUpsert insert rows that must be a slice of struct into table, or update the updateCols when the conflictCols is conflicted
+// the columns is the db tag of the struct fields, all rows is inserted in one multi-VALUES statement using the same conflict clause
+// as Upsert. When the number of parameters exceed the limit, the rows is split into multiple statements that is executed separately,
+// the total rows affected reported by the driver is returned. Mysql count an updated row as 2 rows affected
+func (db *DB) BulkUpsert(ctx context.Context, table string, rows interface{}, conflictCols, updateCols []string) (int64, error) {
+	value := reflect.Indirect(reflect.ValueOf(rows))
+	if value.Kind() != reflect.Slice {
+		return 0, fmt.Errorf("sqldb: BulkUpsert rows must be a slice of struct, got %T", rows)
+	}
+	if value.Len() == 0 {
+		return 0, errBulkUpsertNoRows
+	}
+	if len(conflictCols) == 0 {
+		return 0, errUpsertNoConflictCols
+	}
+	elemType := value.Type().Elem()
+	for elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return 0, fmt.Errorf("sqldb: BulkUpsert rows must be a slice of struct, got %T", rows)
+	}
+
+	columns, fields := bulkUpsertColumns(db.leader.Mapper.TypeMap(elemType))
+	if err := validateIdentifier(table); err != nil {
+		return 0, err
+	}
+	if err := validateIdentifier(columns...); err != nil {
+		return 0, err
+	}
+	if err := validateIdentifier(conflictCols...); err != nil {
+		return 0, err
+	}
+	if err := validateIdentifier(updateCols...); err != nil {
+		return 0, err
+	}
+	columnSet := make(map[string]bool, len(columns))
+	for _, col := range columns {
+		columnSet[col] = true
+	}
+	for _, col := range append(append([]string(nil), conflictCols...), updateCols...) {
+		if !columnSet[col] {
+			return 0, fmt.Errorf("sqldb: bulk upsert column %s is not in %s", col, elemType)
+		}
+	}
+
+	limit := db.maxQueryParams()
+	if len(columns) > limit {
+		return 0, fmt.Errorf("sqldb: too many parameters for bulk upsert row, limit is %d", limit)
+	}
+	rowsPerBatch := limit / len(columns)
+
+	var total int64
+	for start := 0; start < value.Len(); start += rowsPerBatch {
+		end := start + rowsPerBatch
+		if end > value.Len() {
+			end = value.Len()
+		}
+		args := make([]interface{}, 0, (end-start)*len(columns))
+		for i := start; i < end; i++ {
+			row := reflect.Indirect(value.Index(i))
+			if !row.IsValid() {
+				return total, fmt.Errorf("sqldb: bulk upsert row %d is nil", i)
+			}
+			for _, field := range fields {
+				args = append(args, reflectx.FieldByIndexesReadOnly(row, field).Interface())
+			}
+		}
+
+		query := db.upsertQuery(table, columns, end-start, conflictCols, updateCols)
+		result, err := db.ExecContext(ctx, query, args...)
+		if err != nil {
+			return total, err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += affected
+	}
+	return total, nil
+}
+
+// bulkUpsertColumns return the column name and field index of the struct fields that is a column
+// nested struct is not a column unless it implements driver.Valuer or is time.Time, the fields of embedded struct is a column
+func bulkUpsertColumns(tm *reflectx.StructMap) ([]string, [][]int) {
+	var (
+		columns []string
+		fields  [][]int
+	)
+	for _, fi := range tm.Index {
+		if fi.Embedded || strings.Contains(fi.Path, ".") {
+			continue
+		}
+		if len(fi.Children) > 0 && fi.Field.Type != _timeType && !fi.Field.Type.Implements(_valuerType) && !reflect.PtrTo(fi.Field.Type).Implements(_valuerType) {
+			continue
+		}
+		columns = append(columns, fi.Name)
+		fields = append(fields, fi.Index)
+	}
+	return columns, fields
+}
@@ -0,0 +1,108 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// bulkUpsertTable apply the bulk upsert statement to rows keyed by id, to assert the final state of the table
+type bulkUpsertTable struct {
+	mu         sync.Mutex
+	rows       map[int64]map[string]driver.Value
+	statements int
+}
+
+var (
+	bulkUpsertColumnsRegex = regexp.MustCompile(`INSERT INTO \w+ \(([^)]+)\) VALUES`)
+	bulkUpsertSetRegex     = regexp.MustCompile(`(\w+) = EXCLUDED\.\w+`)
+)
+
+func (bt *bulkUpsertTable) handle(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+	bt.mu.Lock()
+	defer bt.mu.Unlock()
+	bt.statements++
+
+	columns := strings.Split(bulkUpsertColumnsRegex.FindStringSubmatch(query)[1], ", ")
+	sets := bulkUpsertSetRegex.FindAllStringSubmatch(query, -1)
+	var affected int64
+	for i := 0; i < len(args); i += len(columns) {
+		values := make(map[string]driver.Value, len(columns))
+		for j, col := range columns {
+			values[col] = args[i+j].Value
+		}
+		id := values["id"].(int64)
+		row, ok := bt.rows[id]
+		if !ok {
+			bt.rows[id] = values
+			affected++
+			continue
+		}
+		for _, set := range sets {
+			row[set[1]] = values[set[1]]
+		}
+		affected++
+	}
+	return fakeResult{rowsAffected: affected}
+}
+
+func TestBulkUpsert(t *testing.T) {
+	t.Parallel()
+
+	type base struct {
+		ID int64 `db:"id"`
+	}
+	type user struct {
+		base
+		Name   string `db:"name"`
+		Status string `db:"status"`
+		cache  string
+	}
+
+	// each row take 3 parameters, so two rows fit in a statement
+	db, leader, _ := newFakeDB(t, WithMaxQueryParams(7))
+	table := &bulkUpsertTable{rows: map[int64]map[string]driver.Value{
+		1: {"id": int64(1), "name": "a", "status": "new"},
+		2: {"id": int64(2), "name": "b", "status": "new"},
+	}}
+	leader.setHandler(table.handle)
+
+	users := []user{
+		{base: base{ID: 1}, Name: "alice", Status: "active"},
+		{base: base{ID: 3}, Name: "carol", Status: "active"},
+		{base: base{ID: 2}, Name: "bob", Status: "blocked"},
+	}
+	affected, err := db.BulkUpsert(context.Background(), "users", users, []string{"id"}, []string{"status"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if affected != 3 {
+		t.Errorf("expecting 3 rows affected, got %d", affected)
+	}
+	if table.statements != 2 {
+		t.Errorf("expecting 2 statements, got %d", table.statements)
+	}
+	expect := map[int64]map[string]driver.Value{
+		// name is not in the update columns, so the existing name is kept
+		1: {"id": int64(1), "name": "a", "status": "active"},
+		2: {"id": int64(2), "name": "b", "status": "blocked"},
+		3: {"id": int64(3), "name": "carol", "status": "active"},
+	}
+	if !reflect.DeepEqual(table.rows, expect) {
+		t.Errorf("expecting rows %v, got %v", expect, table.rows)
+	}
+
+	if _, err := db.BulkUpsert(context.Background(), "users", []user{}, []string{"id"}, nil); err != errBulkUpsertNoRows {
+		t.Errorf("expecting errBulkUpsertNoRows, got %v", err)
+	}
+	if _, err := db.BulkUpsert(context.Background(), "users", users, []string{"email"}, nil); err == nil {
+		t.Error("expecting error when the conflict column is not a field")
+	}
+	if _, err := db.BulkUpsert(context.Background(), "users", []int64{1}, []string{"id"}, nil); err == nil {
+		t.Error("expecting error when rows is not a slice of struct")
+	}
+}
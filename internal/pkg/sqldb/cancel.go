@@ -0,0 +1,52 @@
+package sqldb
+
+import (
+	"context"
+	"sync"
+)
+
+// inflightQueries track the cancel function of all in-flight queries
+type inflightQueries struct {
+	mu      sync.Mutex
+	seq     uint64
+	cancels map[uint64]context.CancelFunc
+}
+
+// track the context of a query, done must be called when the query is finished
+// the context is canceled on done if cancelOnDone is true
+func (iq *inflightQueries) track(ctx context.Context, cancelOnDone bool) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	iq.mu.Lock()
+	if iq.cancels == nil {
+		iq.cancels = make(map[uint64]context.CancelFunc)
+	}
+	iq.seq++
+	id := iq.seq
+	iq.cancels[id] = cancel
+	iq.mu.Unlock()
+
+	return ctx, func() {
+		iq.mu.Lock()
+		delete(iq.cancels, id)
+		iq.mu.Unlock()
+		if cancelOnDone {
+			cancel()
+		}
+	}
+}
+
+func (iq *inflightQueries) cancelAll() {
+	iq.mu.Lock()
+	defer iq.mu.Unlock()
+	for id, cancel := range iq.cancels {
+		cancel()
+		delete(iq.cancels, id)
+	}
+}
+
+// CancelAll cancel all in-flight queries
+// rows that already returned to the caller by Query and QueryRow is not canceled
+func (db *DB) CancelAll() {
+	db.inflight.cancelAll()
+}
@@ -0,0 +1,58 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCancelAll(t *testing.T) {
+	t.Parallel()
+
+	db, leader, follower := newFakeDB(t)
+	started := make(chan struct{}, 10)
+	blocking := func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		started <- struct{}{}
+		<-ctx.Done()
+		return fakeResult{err: ctx.Err()}
+	}
+	leader.setHandler(blocking)
+	follower.setHandler(blocking)
+
+	var (
+		wg   sync.WaitGroup
+		errs = make(chan error, 4)
+	)
+	for i := 0; i < 2; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			var id int
+			errs <- db.GetContext(context.Background(), &id, "SELECT pg_sleep(100)")
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := db.ExecContext(context.Background(), "UPDATE users SET name = pg_sleep(100)")
+			errs <- err
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		select {
+		case <-started:
+		case <-time.After(time.Second * 3):
+			t.Fatal("queries are not started")
+		}
+	}
+
+	db.CancelAll()
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expecting context canceled error, got %v", err)
+		}
+	}
+}
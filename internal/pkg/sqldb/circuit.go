@@ -0,0 +1,148 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+)
+
+var errCircuitOpen = errors.New("sqldb: query circuit open")
+
+// CircuitBreaker configure the per-fingerprint circuit breaker
+// a fingerprint's circuit is opened when the failure rate in Window reach ErrorRate after at least MinRequests,
+// query with open circuit fail fast until Cooldown is passed, then one query is let through to probe the database
+type CircuitBreaker struct {
+	// MinRequests is the minimum number of query in the window before the circuit can be opened
+	MinRequests int
+	// ErrorRate is the failure rate to open the circuit, from 0 to 1
+	ErrorRate float64
+	// LatencyThreshold count query slower than the threshold as failure, disabled when zero
+	LatencyThreshold time.Duration
+	// Window is the duration to count the failure rate
+	Window time.Duration
+	// Cooldown is the duration of open circuit
+	Cooldown time.Duration
+}
+
+func (cb CircuitBreaker) enabled() bool {
+	return cb.MinRequests > 0 && cb.ErrorRate > 0
+}
+
+type circuitState int
+
+// list of circuit state
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+type circuit struct {
+	state       circuitState
+	windowStart time.Time
+	requests    int
+	failures    int
+	openUntil   time.Time
+	// probing is true when a query is let through in half-open state
+	probing bool
+}
+
+// circuitBreakers hold the circuit of each query fingerprint
+type circuitBreakers struct {
+	mu       sync.Mutex
+	circuits map[string]*circuit
+	// now is used to get the current time, replaced in tests
+	now func() time.Time
+}
+
+func (cbs *circuitBreakers) timeNow() time.Time {
+	if cbs.now != nil {
+		return cbs.now()
+	}
+	return time.Now()
+}
+
+// allow return errCircuitOpen if the circuit of the fingerprint is open
+func (cbs *circuitBreakers) allow(fingerprint string) error {
+	cbs.mu.Lock()
+	defer cbs.mu.Unlock()
+
+	c, ok := cbs.circuits[fingerprint]
+	if !ok {
+		return nil
+	}
+	switch c.state {
+	case circuitOpen:
+		if cbs.timeNow().Before(c.openUntil) {
+			return errCircuitOpen
+		}
+		c.state = circuitHalfOpen
+		c.probing = true
+		return nil
+	case circuitHalfOpen:
+		if c.probing {
+			return errCircuitOpen
+		}
+		c.probing = true
+	}
+	return nil
+}
+
+// record the result of a query
+func (cbs *circuitBreakers) record(cfg CircuitBreaker, fingerprint string, duration time.Duration, err error) {
+	failed := isCircuitFailure(err) || (cfg.LatencyThreshold > 0 && duration >= cfg.LatencyThreshold)
+	now := cbs.timeNow()
+
+	cbs.mu.Lock()
+	defer cbs.mu.Unlock()
+
+	if cbs.circuits == nil {
+		cbs.circuits = make(map[string]*circuit)
+	}
+	c, ok := cbs.circuits[fingerprint]
+	if !ok {
+		c = &circuit{windowStart: now}
+		cbs.circuits[fingerprint] = c
+	}
+
+	if c.state == circuitHalfOpen {
+		c.probing = false
+		if failed {
+			c.state = circuitOpen
+			c.openUntil = now.Add(cfg.Cooldown)
+			return
+		}
+		*c = circuit{windowStart: now}
+		return
+	}
+	// query that is started before the circuit is opened
+	if c.state == circuitOpen {
+		return
+	}
+
+	if cfg.Window > 0 && now.Sub(c.windowStart) >= cfg.Window {
+		c.windowStart = now
+		c.requests = 0
+		c.failures = 0
+	}
+	c.requests++
+	if failed {
+		c.failures++
+	}
+	if c.requests >= cfg.MinRequests && float64(c.failures)/float64(c.requests) >= cfg.ErrorRate {
+		c.state = circuitOpen
+		c.openUntil = now.Add(cfg.Cooldown)
+	}
+}
+
+// isCircuitFailure return true if the error indicate the query is unhealthy
+// no rows, canceled query and constraint violation is caused by the caller or the data
+func isCircuitFailure(err error) bool {
+	if err == nil || err == sql.ErrNoRows || err == context.Canceled {
+		return false
+	}
+	var ce *ConstraintError
+	return !errors.As(err, &ce)
+}
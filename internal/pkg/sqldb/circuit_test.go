@@ -0,0 +1,113 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (fc *fakeClock) Now() time.Time {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return fc.now
+}
+
+func (fc *fakeClock) Add(d time.Duration) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.now = fc.now.Add(d)
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	db, _, follower := newFakeDB(t, WithCircuitBreaker(CircuitBreaker{
+		MinRequests: 3,
+		ErrorRate:   0.5,
+		Window:      time.Minute,
+		Cooldown:    time.Second * 10,
+	}))
+	clock := &fakeClock{now: time.Now()}
+	db.breakers.now = clock.Now
+
+	var (
+		mu      sync.Mutex
+		healthy bool
+	)
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		mu.Lock()
+		defer mu.Unlock()
+		if query == "SELECT id FROM reports" && !healthy {
+			return fakeResult{err: errors.New("statement timeout")}
+		}
+		return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}}
+	})
+
+	var id int64
+	for i := 0; i < 3; i++ {
+		if err := db.GetContext(context.Background(), &id, "SELECT id FROM reports"); err == nil || err == errCircuitOpen {
+			t.Fatalf("expecting query error, got %v", err)
+		}
+	}
+
+	before := follower.count("SELECT id FROM reports")
+	if err := db.GetContext(context.Background(), &id, "SELECT id FROM reports"); err != errCircuitOpen {
+		t.Fatalf("expecting errCircuitOpen, got %v", err)
+	}
+	if follower.count("SELECT id FROM reports") != before {
+		t.Error("expecting query with open circuit to not reach the database")
+	}
+
+	// other fingerprint is not affected
+	if err := db.GetContext(context.Background(), &id, "SELECT id FROM users"); err != nil {
+		t.Fatalf("expecting other query to succeed, got %v", err)
+	}
+
+	// probe after cooldown close the circuit when the query succeed
+	mu.Lock()
+	healthy = true
+	mu.Unlock()
+	clock.Add(time.Second * 10)
+	if err := db.GetContext(context.Background(), &id, "SELECT id FROM reports"); err != nil {
+		t.Fatalf("expecting probe to succeed, got %v", err)
+	}
+	if err := db.GetContext(context.Background(), &id, "SELECT id FROM reports"); err != nil {
+		t.Fatalf("expecting circuit to be closed, got %v", err)
+	}
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	t.Parallel()
+
+	cfg := CircuitBreaker{MinRequests: 1, ErrorRate: 1, LatencyThreshold: time.Second, Cooldown: time.Second}
+	clock := &fakeClock{now: time.Now()}
+	cbs := &circuitBreakers{now: clock.Now}
+
+	// slow query count as failure
+	cbs.record(cfg, "q", time.Second*2, nil)
+	if err := cbs.allow("q"); err != errCircuitOpen {
+		t.Fatalf("expecting errCircuitOpen, got %v", err)
+	}
+
+	clock.Add(time.Second)
+	if err := cbs.allow("q"); err != nil {
+		t.Fatalf("expecting probe to be allowed, got %v", err)
+	}
+	if err := cbs.allow("q"); err != errCircuitOpen {
+		t.Fatalf("expecting only one probe, got %v", err)
+	}
+
+	// failed probe open the circuit again
+	cbs.record(cfg, "q", 0, errors.New("connection refused"))
+	if err := cbs.allow("q"); err != errCircuitOpen {
+		t.Fatalf("expecting errCircuitOpen after failed probe, got %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// ColumnBatch is the column-oriented result of QueryColumnar, for example to feed Arrow-based processing
+type ColumnBatch struct {
+	Columns []Column
+	// Rows is the number of rows
+	Rows int
+}
+
+// Column of ColumnBatch
+type Column struct {
+	Name         string
+	DatabaseType string
+	// Values is the values of the column by the database type, one of []int64, []float64, []bool, []time.Time, [][]byte or []string
+	// the value of NULL is the zero value of the type
+	Values interface{}
+	// Validity is the validity bitmap like Arrow, bit i%8 of byte i/8 is set when row i is not NULL
+	Validity []byte
+}
+
+// Valid return false when the value of row is NULL
+func (c *Column) Valid(row int) bool {
+	return c.Validity[row/8]&(1<<uint(row%8)) != 0
+}
+
+// columnBuilder append the scanned value of a column
+type columnBuilder struct {
+	// dest is the nullable scan destination
+	dest interface{}
+	// append the scanned dest to the column values, return false when the value is NULL
+	append func() bool
+}
+
+// QueryColumnar scan the query result into column-oriented values
+// the type of each column is decided by the database type name: integer to int64, float to float64, boolean to bool,
+// timestamp and date to time.Time, binary to []byte, and other type including numeric to string. mysql require parseTime
+func (db *DB) QueryColumnar(ctx context.Context, query string, args ...interface{}) (*ColumnBatch, error) {
+	reader, info, err := db.readQueryer(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	var batch *ColumnBatch
+	err = db.run(ctx, info, func(ctx context.Context) error {
+		rows, err := reader.QueryContext(ctx, db.withTimeoutComment(ctx, query), args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		types, err := rows.ColumnTypes()
+		if err != nil {
+			return err
+		}
+		b := &ColumnBatch{Columns: make([]Column, len(types))}
+		builders := make([]columnBuilder, len(types))
+		dest := make([]interface{}, len(types))
+		for i, ct := range types {
+			b.Columns[i] = Column{Name: ct.Name(), DatabaseType: ct.DatabaseTypeName()}
+			builders[i] = newColumnBuilder(&b.Columns[i])
+			dest[i] = builders[i].dest
+		}
+
+		for rows.Next() {
+			if err := rows.Scan(dest...); err != nil {
+				return err
+			}
+			if b.Rows%8 == 0 {
+				for i := range b.Columns {
+					b.Columns[i].Validity = append(b.Columns[i].Validity, 0)
+				}
+			}
+			for i, builder := range builders {
+				if builder.append() {
+					b.Columns[i].Validity[b.Rows/8] |= 1 << uint(b.Rows%8)
+				}
+			}
+			b.Rows++
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		batch = b
+		return rows.Close()
+	})
+	return batch, err
+}
+
+// _integerTypes is the database type names scanned as int64, mysql unsigned type is prefixed with UNSIGNED
+var _integerTypes = map[string]bool{
+	"INT": true, "INT2": true, "INT4": true, "INT8": true, "INTEGER": true,
+	"BIGINT": true, "SMALLINT": true, "TINYINT": true, "MEDIUMINT": true,
+	"SERIAL": true, "SERIAL2": true, "SERIAL4": true, "SERIAL8": true, "SMALLSERIAL": true, "BIGSERIAL": true,
+}
+
+func newColumnBuilder(c *Column) columnBuilder {
+	typeName := strings.ToUpper(c.DatabaseType)
+	switch {
+	case _integerTypes[strings.TrimPrefix(typeName, "UNSIGNED ")]:
+		var values []int64
+		dest := &sql.NullInt64{}
+		c.Values = values
+		return columnBuilder{dest: dest, append: func() bool {
+			values = append(values, dest.Int64)
+			c.Values = values
+			return dest.Valid
+		}}
+	case strings.HasPrefix(typeName, "FLOAT") || typeName == "DOUBLE" || typeName == "REAL":
+		var values []float64
+		dest := &sql.NullFloat64{}
+		c.Values = values
+		return columnBuilder{dest: dest, append: func() bool {
+			values = append(values, dest.Float64)
+			c.Values = values
+			return dest.Valid
+		}}
+	case strings.HasPrefix(typeName, "BOOL"):
+		var values []bool
+		dest := &sql.NullBool{}
+		c.Values = values
+		return columnBuilder{dest: dest, append: func() bool {
+			values = append(values, dest.Bool)
+			c.Values = values
+			return dest.Valid
+		}}
+	case strings.HasPrefix(typeName, "TIMESTAMP") || typeName == "DATE" || typeName == "DATETIME":
+		var values []time.Time
+		dest := &sql.NullTime{}
+		c.Values = values
+		return columnBuilder{dest: dest, append: func() bool {
+			values = append(values, dest.Time)
+			c.Values = values
+			return dest.Valid
+		}}
+	case typeName == "BYTEA" || strings.HasSuffix(typeName, "BLOB") || strings.HasSuffix(typeName, "BINARY"):
+		var values [][]byte
+		dest := &[]byte{}
+		c.Values = values
+		return columnBuilder{dest: dest, append: func() bool {
+			values = append(values, *dest)
+			c.Values = values
+			return *dest != nil
+		}}
+	}
+	var values []string
+	dest := &sql.NullString{}
+	c.Values = values
+	return columnBuilder{dest: dest, append: func() bool {
+		values = append(values, dest.String)
+		c.Values = values
+		return dest.Valid
+	}}
+}
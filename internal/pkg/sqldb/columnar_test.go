@@ -0,0 +1,124 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestQueryColumnar(t *testing.T) {
+	t.Parallel()
+
+	const query = "SELECT id, price, active, created_at, name, payload, amount FROM products"
+	createdAt := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	db, _, follower := newFakeDB(t)
+	follower.setHandler(func(ctx context.Context, q string, args []driver.NamedValue) fakeResult {
+		res := fakeResult{
+			columns: []string{"id", "price", "active", "created_at", "name", "payload", "amount"},
+			columnTypes: []fakeColumnType{
+				{databaseType: "INT8"}, {databaseType: "FLOAT8"}, {databaseType: "BOOL"}, {databaseType: "TIMESTAMPTZ"},
+				{databaseType: "VARCHAR"}, {databaseType: "BYTEA"}, {databaseType: "NUMERIC"},
+			},
+		}
+		// 9 rows so the validity bitmap take more than one byte
+		for i := int64(1); i <= 9; i++ {
+			row := []driver.Value{i, float64(i) / 2, i%2 == 0, createdAt.Add(time.Duration(i) * time.Hour), "product", []byte{byte(i)}, "10.50"}
+			if i%3 == 0 {
+				row = []driver.Value{i, nil, nil, nil, nil, nil, nil}
+			}
+			res.rows = append(res.rows, row)
+		}
+		return res
+	})
+
+	batch, err := db.QueryColumnar(context.Background(), query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if batch.Rows != 9 || len(batch.Columns) != 7 {
+		t.Fatalf("expecting 9 rows and 7 columns, got %d rows and %d columns", batch.Rows, len(batch.Columns))
+	}
+
+	type product struct {
+		ID        int64           `db:"id"`
+		Price     sql.NullFloat64 `db:"price"`
+		Active    sql.NullBool    `db:"active"`
+		CreatedAt sql.NullTime    `db:"created_at"`
+		Name      sql.NullString  `db:"name"`
+		Payload   []byte          `db:"payload"`
+		Amount    sql.NullString  `db:"amount"`
+	}
+	var products []product
+	if err := db.SelectContext(context.Background(), &products, query); err != nil {
+		t.Fatal(err)
+	}
+
+	ids := batch.Columns[0].Values.([]int64)
+	prices := batch.Columns[1].Values.([]float64)
+	actives := batch.Columns[2].Values.([]bool)
+	createdAts := batch.Columns[3].Values.([]time.Time)
+	names := batch.Columns[4].Values.([]string)
+	payloads := batch.Columns[5].Values.([][]byte)
+	amounts := batch.Columns[6].Values.([]string)
+	for i, p := range products {
+		if !batch.Columns[0].Valid(i) || ids[i] != p.ID {
+			t.Errorf("row %d: expecting id %d, got %d", i, p.ID, ids[i])
+		}
+		if batch.Columns[1].Valid(i) != p.Price.Valid || prices[i] != p.Price.Float64 {
+			t.Errorf("row %d: expecting price %v, got %v valid %v", i, p.Price, prices[i], batch.Columns[1].Valid(i))
+		}
+		if batch.Columns[2].Valid(i) != p.Active.Valid || actives[i] != p.Active.Bool {
+			t.Errorf("row %d: expecting active %v, got %v", i, p.Active, actives[i])
+		}
+		if batch.Columns[3].Valid(i) != p.CreatedAt.Valid || !createdAts[i].Equal(p.CreatedAt.Time) {
+			t.Errorf("row %d: expecting created_at %v, got %v", i, p.CreatedAt, createdAts[i])
+		}
+		if batch.Columns[4].Valid(i) != p.Name.Valid || names[i] != p.Name.String {
+			t.Errorf("row %d: expecting name %v, got %v", i, p.Name, names[i])
+		}
+		if batch.Columns[5].Valid(i) != (p.Payload != nil) || !reflect.DeepEqual(payloads[i], p.Payload) {
+			t.Errorf("row %d: expecting payload %v, got %v", i, p.Payload, payloads[i])
+		}
+		if batch.Columns[6].Valid(i) != p.Amount.Valid || amounts[i] != p.Amount.String {
+			t.Errorf("row %d: expecting amount %v, got %v", i, p.Amount, amounts[i])
+		}
+	}
+	if len(products) != batch.Rows {
+		t.Errorf("expecting %d rows scanned row-wise, got %d", batch.Rows, len(products))
+	}
+}
+
+func TestQueryColumnarTypeNames(t *testing.T) {
+	t.Parallel()
+
+	db, _, follower := newFakeDB(t)
+	follower.setHandler(func(ctx context.Context, q string, args []driver.NamedValue) fakeResult {
+		return fakeResult{
+			columns: []string{"id", "quantity", "duration", "location"},
+			columnTypes: []fakeColumnType{
+				{databaseType: "BIGSERIAL"}, {databaseType: "UNSIGNED INT"}, {databaseType: "INTERVAL"}, {databaseType: "POINT"},
+			},
+			rows: [][]driver.Value{{int64(1), int64(2), "01:30:00", "(1,2)"}},
+		}
+	})
+
+	batch, err := db.QueryColumnar(context.Background(), "SELECT id, quantity, duration, location FROM trips")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if values, ok := batch.Columns[i].Values.([]int64); !ok || values[0] != int64(i+1) {
+			t.Errorf("%s: expecting int64 column, got %#v", batch.Columns[i].DatabaseType, batch.Columns[i].Values)
+		}
+	}
+	// INTERVAL and POINT contain INT but is not an integer type
+	if values, ok := batch.Columns[2].Values.([]string); !ok || values[0] != "01:30:00" {
+		t.Errorf("INTERVAL: expecting string column, got %#v", batch.Columns[2].Values)
+	}
+	if values, ok := batch.Columns[3].Values.([]string); !ok || values[0] != "(1,2)" {
+		t.Errorf("POINT: expecting string column, got %#v", batch.Columns[3].Values)
+	}
+}
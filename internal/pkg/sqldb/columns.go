@@ -0,0 +1,59 @@
+package sqldb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ColumnInfo is the metadata of a result column
+type ColumnInfo struct {
+	Name string
+	// DatabaseType is the database system name of the column type, for example VARCHAR or INT4
+	// empty if not supported by the driver
+	DatabaseType string
+	// Nullable is false when the column is not nullable or the driver doesn't support it
+	Nullable bool
+	// Length is the length of variable length column type, zero if not variable length or not supported by the driver
+	Length   int64
+	ScanType reflect.Type
+}
+
+// Columns return the column metadata of the query result without reading any row
+// the query is wrapped with SELECT * FROM (query) LIMIT 0, so it must be a SELECT query
+func (db *DB) Columns(ctx context.Context, query string, args ...interface{}) ([]ColumnInfo, error) {
+	query = fmt.Sprintf("SELECT * FROM (%s) AS q LIMIT 0", strings.TrimRight(strings.TrimSpace(query), "; "))
+
+	var columns []ColumnInfo
+	reader, info, err := db.readQueryer(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	err = db.run(ctx, info, func(ctx context.Context) error {
+		rows, err := reader.QueryContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		types, err := rows.ColumnTypes()
+		if err != nil {
+			return err
+		}
+		columns = make([]ColumnInfo, len(types))
+		for i, ct := range types {
+			nullable, _ := ct.Nullable()
+			length, _ := ct.Length()
+			columns[i] = ColumnInfo{
+				Name:         ct.Name(),
+				DatabaseType: ct.DatabaseTypeName(),
+				Nullable:     nullable,
+				Length:       length,
+				ScanType:     ct.ScanType(),
+			}
+		}
+		return rows.Close()
+	})
+	return columns, err
+}
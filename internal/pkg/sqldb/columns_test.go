@@ -0,0 +1,48 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+)
+
+func TestColumns(t *testing.T) {
+	t.Parallel()
+
+	db, _, follower := newFakeDB(t)
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{
+			columns: []string{"id", "name", "deleted_at"},
+			columnTypes: []fakeColumnType{
+				{databaseType: "INT8"},
+				{databaseType: "VARCHAR", length: 255},
+				{databaseType: "TIMESTAMPTZ", nullable: true},
+			},
+		}
+	})
+
+	columns, err := db.Columns(context.Background(), "SELECT id, name, deleted_at FROM users WHERE id = $1;", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []ColumnInfo{
+		{Name: "id", DatabaseType: "INT8"},
+		{Name: "name", DatabaseType: "VARCHAR", Length: 255},
+		{Name: "deleted_at", DatabaseType: "TIMESTAMPTZ", Nullable: true},
+	}
+	if len(columns) != len(expect) {
+		t.Fatalf("expecting %d columns, got %d", len(expect), len(columns))
+	}
+	for i, e := range expect {
+		c := columns[i]
+		if c.Name != e.Name || c.DatabaseType != e.DatabaseType || c.Nullable != e.Nullable || c.Length != e.Length {
+			t.Errorf("column %d: expecting %+v, got %+v", i, e, c)
+		}
+	}
+
+	const wrapped = "SELECT * FROM (SELECT id, name, deleted_at FROM users WHERE id = $1) AS q LIMIT 0"
+	if follower.count(wrapped) != 1 {
+		t.Errorf("expecting query to be wrapped with LIMIT 0, got %v", follower.Queries())
+	}
+}
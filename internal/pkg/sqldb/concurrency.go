@@ -0,0 +1,33 @@
+package sqldb
+
+import "context"
+
+type maxConcurrencyKey struct{}
+
+// WithMaxConcurrency limit the number of concurrent queries issued using the context to n
+// this is useful to bound the database usage of a background job, so it doesn't starve online traffic
+// the query is blocked until it get the slot or the context is done
+// the slot is released when the query function return, so only queries that read the result before returning are covered,
+// like Exec, Get, Select, Reduce and Iterate. QueryContext, QueryRowContext and NamedQuery release the slot once the rows
+// is returned, not when it is closed, so the rows being read by the caller doesn't count toward n
+func WithMaxConcurrency(ctx context.Context, n int) context.Context {
+	if n <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, maxConcurrencyKey{}, make(chan struct{}, n))
+}
+
+// acquireConcurrency acquire a query slot from the context, release must be called when the query is finished
+func acquireConcurrency(ctx context.Context) (release func(), err error) {
+	sem, ok := ctx.Value(maxConcurrencyKey{}).(chan struct{})
+	if !ok {
+		return func() {}, nil
+	}
+
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,53 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithMaxConcurrency(t *testing.T) {
+	t.Parallel()
+
+	db, _, follower := newFakeDB(t)
+	var (
+		current int64
+		max     int64
+	)
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		n := atomic.AddInt64(&current, 1)
+		defer atomic.AddInt64(&current, -1)
+		for {
+			m := atomic.LoadInt64(&max)
+			if n <= m || atomic.CompareAndSwapInt64(&max, m, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond * 20)
+		return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}}
+	})
+
+	ctx := WithMaxConcurrency(context.Background(), 2)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var id int
+			if err := db.GetContext(ctx, &id, "SELECT id FROM users"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if max > 2 {
+		t.Errorf("expecting at most 2 concurrent queries, got %d", max)
+	}
+	if n := len(follower.Queries()); n != 8 {
+		t.Errorf("expecting all 8 queries to be executed, got %d", n)
+	}
+}
@@ -0,0 +1,158 @@
+package sqldb
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// Config of the database connection, used to build the dsn of the driver
+type Config struct {
+	Driver   string
+	Host     string
+	Port     int
+	User     string
+	Password string
+	DBName   string
+	// SSLMode is the postgres sslmode, only supported by postgres
+	SSLMode string
+	// Charset is the mysql connection charset, only supported by mysql
+	Charset string
+	// ParseTime parse mysql DATE and DATETIME to time.Time, only supported by mysql
+	ParseTime bool
+	// Params is the additional driver parameter
+	Params map[string]string
+}
+
+// list of parameter that is only supported by one driver
+var (
+	_postgresOnlyParams = map[string]bool{
+		"sslmode":                   true,
+		"sslcert":                   true,
+		"sslkey":                    true,
+		"sslrootcert":               true,
+		"search_path":               true,
+		"application_name":          true,
+		"fallback_application_name": true,
+		"connect_timeout":           true,
+	}
+	_mysqlOnlyParams = map[string]bool{
+		"charset":              true,
+		"collation":            true,
+		"parseTime":            true,
+		"loc":                  true,
+		"tls":                  true,
+		"timeout":              true,
+		"readTimeout":          true,
+		"writeTimeout":         true,
+		"allowNativePasswords": true,
+		"interpolateParams":    true,
+		"multiStatements":      true,
+	}
+)
+
+// DSN validate the config for the driver and return the dsn
+// field or parameter that is not supported by the driver is returned as error instead of rejected by the driver when connect
+func (c *Config) DSN() (string, error) {
+	if c == nil {
+		return "", errConfigNil
+	}
+	if err := c.validate(); err != nil {
+		return "", err
+	}
+
+	if c.Driver == "mysql" {
+		return c.mysqlDSN(), nil
+	}
+	return c.postgresDSN(), nil
+}
+
+func (c *Config) validate() error {
+	var onlyIn map[string]bool
+	switch c.Driver {
+	case "postgres":
+		onlyIn = _mysqlOnlyParams
+		if c.Charset != "" {
+			return fieldDriverError("Charset", "mysql", c.Driver)
+		}
+		if c.ParseTime {
+			return fieldDriverError("ParseTime", "mysql", c.Driver)
+		}
+	case "mysql":
+		onlyIn = _postgresOnlyParams
+		if c.SSLMode != "" {
+			return fieldDriverError("SSLMode", "postgres", c.Driver)
+		}
+	default:
+		return fmt.Errorf("sqldb: driver %s is not supported", c.Driver)
+	}
+
+	for key := range c.Params {
+		if onlyIn[key] {
+			other := "postgres"
+			if c.Driver == "postgres" {
+				other = "mysql"
+			}
+			return fieldDriverError(fmt.Sprintf("Params[%q]", key), other, c.Driver)
+		}
+	}
+	return nil
+}
+
+func fieldDriverError(field, supported, driver string) error {
+	return fmt.Errorf("sqldb: config %s is only supported by %s driver, got driver %s", field, supported, driver)
+}
+
+func (c *Config) address(defaultPort int) string {
+	host, port := c.Host, c.Port
+	if host == "" {
+		host = "localhost"
+	}
+	if port == 0 {
+		port = defaultPort
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+func (c *Config) postgresDSN() string {
+	query := url.Values{}
+	for key, value := range c.Params {
+		query.Set(key, value)
+	}
+	if c.SSLMode != "" {
+		query.Set("sslmode", c.SSLMode)
+	}
+	u := url.URL{
+		Scheme:   "postgres",
+		Host:     c.address(5432),
+		Path:     "/" + c.DBName,
+		RawQuery: query.Encode(),
+	}
+	if c.User != "" {
+		u.User = url.UserPassword(c.User, c.Password)
+	}
+	return u.String()
+}
+
+func (c *Config) mysqlDSN() string {
+	config := mysql.NewConfig()
+	config.Net = "tcp"
+	config.Addr = c.address(3306)
+	config.User = c.User
+	config.Passwd = c.Password
+	config.DBName = c.DBName
+	config.ParseTime = c.ParseTime
+	if len(c.Params) > 0 || c.Charset != "" {
+		config.Params = make(map[string]string, len(c.Params)+1)
+		for key, value := range c.Params {
+			config.Params[key] = value
+		}
+		if c.Charset != "" {
+			config.Params["charset"] = c.Charset
+		}
+	}
+	return config.FormatDSN()
+}
@@ -0,0 +1,143 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+var errBackendPIDNotSupported = errors.New("sqldb: backend pid is not supported for this driver")
+
+// list of query to get the server process id of the connection for each driver
+var backendPIDQueries = map[string]string{
+	"postgres": "SELECT pg_backend_pid()",
+	"mysql":    "SELECT CONNECTION_ID()",
+}
+
+// Conn is a dedicated connection to leader, all queries using Conn run on the same server process
+// Close must be called to return the connection to the pool
+type Conn struct {
+	*sql.Conn
+	driver string
+}
+
+// Conn return a dedicated connection to leader
+func (db *DB) Conn(ctx context.Context) (*Conn, error) {
+	conn, err := db.leader.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Conn{Conn: conn, driver: db.driver}, nil
+}
+
+// BackendPID return the server process id of the connection, the id is the same for all queries using the connection
+// postgres use pg_backend_pid() and mysql use CONNECTION_ID()
+func (c *Conn) BackendPID(ctx context.Context) (int, error) {
+	query, ok := backendPIDQueries[c.driver]
+	if !ok {
+		return 0, errBackendPIDNotSupported
+	}
+
+	var pid int
+	if err := c.QueryRowContext(ctx, query).Scan(&pid); err != nil {
+		return 0, err
+	}
+	return pid, nil
+}
+
+// BackendPID return the server process id of a connection from the leader pool
+// the next query is not guaranteed to run on the same connection, use Conn to get the id of a specific connection
+func (db *DB) BackendPID(ctx context.Context) (int, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+	return conn.BackendPID(ctx)
+}
+
+var (
+	errInvalidBackendPID         = errors.New("sqldb: backend pid must be positive")
+	errBackendSignalNotSupported = errors.New("sqldb: cancel backend is not supported for this driver")
+	// ErrBackendPermissionDenied is returned when the connection is not allowed to cancel or terminate the backend
+	ErrBackendPermissionDenied = errors.New("sqldb: permission denied to signal backend")
+)
+
+// BackendPermissionError wrap the driver error when the connection is not allowed to cancel or terminate the backend
+type BackendPermissionError struct {
+	Err error
+}
+
+// Error return the permission denied message with the driver error
+func (e *BackendPermissionError) Error() string {
+	return ErrBackendPermissionDenied.Error() + ": " + e.Err.Error()
+}
+
+// Unwrap return the driver error
+func (e *BackendPermissionError) Unwrap() error {
+	return e.Err
+}
+
+// Is return true for ErrBackendPermissionDenied
+func (e *BackendPermissionError) Is(target error) bool {
+	return target == ErrBackendPermissionDenied
+}
+
+// CancelBackend cancel the running query of the server process with pid from leader, the connection is kept
+// false is returned when postgres cannot find the process. mysql use KILL QUERY and always return true on success
+func (db *DB) CancelBackend(ctx context.Context, pid int) (bool, error) {
+	return db.signalBackend(ctx, pid, "pg_cancel_backend", "KILL QUERY")
+}
+
+// TerminateBackend terminate the server process with pid from leader, the connection is closed
+// false is returned when postgres cannot find the process. mysql use KILL and always return true on success
+func (db *DB) TerminateBackend(ctx context.Context, pid int) (bool, error) {
+	return db.signalBackend(ctx, pid, "pg_terminate_backend", "KILL")
+}
+
+func (db *DB) signalBackend(ctx context.Context, pid int, postgresFunc, mysqlStatement string) (bool, error) {
+	if pid <= 0 {
+		return false, errInvalidBackendPID
+	}
+
+	var (
+		signaled bool
+		err      error
+	)
+	switch db.driver {
+	case "postgres":
+		query := "SELECT " + postgresFunc + "($1)"
+		// the signal is run as write, so it is never executed again by resource usage sampling or explain
+		err = db.run(ctx, &queryInfo{query: query, args: []interface{}{pid}, target: targetLeader, write: true}, func(ctx context.Context) error {
+			return db.leader.GetContext(ctx, &signaled, db.withTimeoutComment(ctx, query), pid)
+		})
+	case "mysql":
+		// KILL doesn't accept placeholder, the pid is validated as a positive integer
+		_, err = db.ExecContext(ctx, fmt.Sprintf("%s %d", mysqlStatement, pid))
+		signaled = err == nil
+	default:
+		return false, errBackendSignalNotSupported
+	}
+	return signaled, backendPermissionError(err)
+}
+
+// backendPermissionError return BackendPermissionError if err is caused by missing privilege
+func backendPermissionError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var (
+		pqErr    *pq.Error
+		mysqlErr *mysql.MySQLError
+	)
+	// 42501 is postgres insufficient_privilege, 1095 is mysql you are not owner of thread
+	if (errors.As(err, &pqErr) && pqErr.Code == "42501") || (errors.As(err, &mysqlErr) && mysqlErr.Number == 1095) {
+		return &BackendPermissionError{Err: err}
+	}
+	return err
+}
@@ -0,0 +1,217 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+// backendPIDHandler return the fake connection id as the backend pid
+func backendPIDHandler(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+	if query == "SELECT pg_backend_pid()" {
+		return fakeResult{columns: []string{"pg_backend_pid"}, rows: [][]driver.Value{{fakeConnID(ctx)}}}
+	}
+	return fakeResult{}
+}
+
+func TestBackendPID(t *testing.T) {
+	t.Parallel()
+
+	db, leader, _ := newFakeDB(t)
+	db.driver = "postgres"
+	leader.setHandler(backendPIDHandler)
+	ctx := context.Background()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	pid, err := conn.BackendPID(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pid <= 0 {
+		t.Errorf("expecting positive pid, got %d", pid)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := conn.ExecContext(ctx, "UPDATE users SET name = $1 WHERE id = $2", "name", 1); err != nil {
+			t.Fatal(err)
+		}
+		if again, err := conn.BackendPID(ctx); err != nil || again != pid {
+			t.Errorf("expecting pid %d to be stable on the same connection, got %d %v", pid, again, err)
+		}
+	}
+
+	// the dedicated connection is in use, so another connection is opened
+	other, err := db.BackendPID(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == pid {
+		t.Errorf("expecting different pid for another connection, got %d", other)
+	}
+
+	db.driver = "sqlite"
+	if _, err := db.BackendPID(ctx); err != errBackendPIDNotSupported {
+		t.Errorf("expecting errBackendPIDNotSupported, got %v", err)
+	}
+}
+
+func TestCancelBackend(t *testing.T) {
+	t.Parallel()
+
+	db, leader, _ := newFakeDB(t)
+	db.driver = "postgres"
+	var (
+		mu      sync.Mutex
+		running = make(map[int64]chan struct{})
+		started = make(chan struct{})
+	)
+	leader.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		switch query {
+		case "SELECT pg_sleep(60)":
+			cancel := make(chan struct{})
+			mu.Lock()
+			running[fakeConnID(ctx)] = cancel
+			mu.Unlock()
+			close(started)
+			<-cancel
+			return fakeResult{err: &pq.Error{Code: "57014", Message: "canceling statement due to user request"}}
+		case "SELECT pg_cancel_backend($1)":
+			mu.Lock()
+			defer mu.Unlock()
+			cancel, ok := running[args[0].Value.(int64)]
+			if ok {
+				close(cancel)
+				delete(running, args[0].Value.(int64))
+			}
+			return fakeResult{columns: []string{"pg_cancel_backend"}, rows: [][]driver.Value{{ok}}}
+		}
+		return backendPIDHandler(ctx, query, args)
+	})
+	ctx := context.Background()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	pid, err := conn.BackendPID(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := conn.ExecContext(ctx, "SELECT pg_sleep(60)")
+		errs <- err
+	}()
+	select {
+	case <-started:
+	case <-time.After(time.Second * 5):
+		t.Fatal("expecting long query to start")
+	}
+
+	canceled, err := db.CancelBackend(ctx, pid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !canceled {
+		t.Error("expecting backend to be canceled")
+	}
+	select {
+	case err := <-errs:
+		var pqErr *pq.Error
+		if !errors.As(err, &pqErr) || pqErr.Code != "57014" {
+			t.Errorf("expecting query canceled error, got %v", err)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("expecting long query to be canceled")
+	}
+
+	if canceled, err := db.CancelBackend(ctx, pid); err != nil || canceled {
+		t.Errorf("expecting false for backend without running query, got %v %v", canceled, err)
+	}
+	if _, err := db.CancelBackend(ctx, 0); err != errInvalidBackendPID {
+		t.Errorf("expecting errInvalidBackendPID, got %v", err)
+	}
+}
+
+func TestBackendPermissionDenied(t *testing.T) {
+	t.Parallel()
+
+	db, leader, _ := newFakeDB(t)
+	db.driver = "mysql"
+	leader.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		if query == "KILL 42" {
+			return fakeResult{err: &mysql.MySQLError{Number: 1095, Message: "You are not owner of thread 42"}}
+		}
+		return fakeResult{}
+	})
+
+	if terminated, err := db.TerminateBackend(context.Background(), 42); !errors.Is(err, ErrBackendPermissionDenied) || terminated {
+		t.Errorf("expecting ErrBackendPermissionDenied, got %v %v", terminated, err)
+	}
+	if _, err := db.TerminateBackend(context.Background(), 42); !errors.As(err, new(*mysql.MySQLError)) {
+		t.Errorf("expecting the driver error to be unwrapped, got %v", err)
+	}
+	if canceled, err := db.CancelBackend(context.Background(), 43); err != nil || !canceled {
+		t.Errorf("expecting backend to be canceled, got %v %v", canceled, err)
+	}
+	if n := leader.count("KILL QUERY 43"); n != 1 {
+		t.Errorf("expecting KILL QUERY to be executed, got %d", n)
+	}
+}
+
+func TestSignalBackendNotSampled(t *testing.T) {
+	t.Parallel()
+
+	l := &fakeLogger{}
+	db, leader, _ := newFakeDB(t, WithLogger(l), WithResourceUsageSampling(1))
+	db.driver = "postgres"
+	const plan = `[{"Plan": {"Actual Rows": 1}, "Execution Time": 1}]`
+	leader.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		if strings.HasPrefix(query, "EXPLAIN") {
+			return fakeResult{columns: []string{"QUERY PLAN"}, rows: [][]driver.Value{{[]byte(plan)}}}
+		}
+		if query == "SELECT 1" {
+			return fakeResult{columns: []string{"n"}, rows: [][]driver.Value{{int64(1)}}}
+		}
+		return fakeResult{columns: []string{"signaled"}, rows: [][]driver.Value{{true}}}
+	})
+
+	ctx := WithExplain(context.Background())
+	if terminated, err := db.TerminateBackend(ctx, 42); err != nil || !terminated {
+		t.Fatalf("expecting backend to be terminated, got %v %v", terminated, err)
+	}
+	// the sampled read show the sampler has run
+	var n int
+	if err := db.GetFromLeader(ctx, &n, "SELECT 1"); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second * 5)
+	for !l.contains("sqldb: query resource usage") {
+		if time.Now().After(deadline) {
+			t.Fatal("expecting the read to be sampled")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	time.Sleep(time.Millisecond * 50)
+
+	for _, q := range leader.Queries() {
+		if strings.HasPrefix(q, "EXPLAIN") && strings.Contains(q, "pg_terminate_backend") {
+			t.Errorf("expecting the signal to not be explained or sampled, got %s", q)
+		}
+	}
+	if n := leader.count("SELECT pg_terminate_backend($1)"); n != 1 {
+		t.Errorf("expecting the backend to be signaled once, got %d", n)
+	}
+}
@@ -0,0 +1,153 @@
+package sqldb
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"os"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+func TestConnectWithConnector(t *testing.T) {
+	t.Parallel()
+
+	_, fake := newFakeServer(t, "leader")
+	var (
+		called bool
+		delay  = time.Millisecond * 20
+	)
+	connector := func(ctx context.Context, driver, dsn string) (*sqlx.DB, error) {
+		called = true
+		time.Sleep(delay)
+		return fake, nil
+	}
+
+	start := time.Now()
+	db, err := Connect(context.Background(), fakeDriverName, "unused", &ConnectOptions{Connector: connector})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !called {
+		t.Error("expecting connector to be used")
+	}
+	if db != fake {
+		t.Error("expecting connection from connector")
+	}
+	if time.Since(start) < delay {
+		t.Error("expecting latency from connector")
+	}
+}
+
+func TestConnectError(t *testing.T) {
+	t.Parallel()
+
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+	cases := []struct {
+		name     string
+		err      error
+		kind     ConnectErrorKind
+		attempts int
+	}{
+		{name: "connection refused", err: refused, kind: ConnectNotReady, attempts: 3},
+		{name: "postgres starting up", err: &pq.Error{Code: "57P03"}, kind: ConnectNotReady, attempts: 3},
+		{name: "postgres invalid password", err: &pq.Error{Code: "28P01"}, kind: ConnectAuthFailed, attempts: 1},
+		{name: "mysql access denied", err: &mysql.MySQLError{Number: 1045}, kind: ConnectAuthFailed, attempts: 1},
+		{name: "host not found", err: &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Name: "db.invalid", IsNotFound: true}}, kind: ConnectHostNotFound, attempts: 1},
+		{name: "other", err: errors.New("other"), kind: ConnectUnknown, attempts: 3},
+	}
+	for _, c := range cases {
+		var attempts int
+		connector := func(ctx context.Context, driver, dsn string) (*sqlx.DB, error) {
+			attempts++
+			return nil, c.err
+		}
+		_, err := Connect(context.Background(), fakeDriverName, "unused", &ConnectOptions{Connector: connector, Retry: 3, RetryInterval: time.Millisecond})
+		var ce *ConnectError
+		if !errors.As(err, &ce) {
+			t.Fatalf("%s: expecting ConnectError, got %v", c.name, err)
+		}
+		if ce.Kind != c.kind {
+			t.Errorf("%s: expecting kind %s, got %s", c.name, c.kind, ce.Kind)
+		}
+		if !errors.Is(err, c.err) {
+			t.Errorf("%s: expecting original error to be wrapped", c.name)
+		}
+		if attempts != c.attempts || ce.Attempts != c.attempts {
+			t.Errorf("%s: expecting %d attempts, got %d", c.name, c.attempts, attempts)
+		}
+	}
+}
+
+func TestConnectRetryUntilReady(t *testing.T) {
+	t.Parallel()
+
+	_, fake := newFakeServer(t, "leader")
+	var attempts int
+	connector := func(ctx context.Context, driver, dsn string) (*sqlx.DB, error) {
+		attempts++
+		if attempts < 3 {
+			return nil, &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+		}
+		return fake, nil
+	}
+	db, err := Connect(context.Background(), fakeDriverName, "unused", &ConnectOptions{Connector: connector, Retry: 5, RetryInterval: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db != fake || attempts != 3 {
+		t.Errorf("expecting connection after 3 attempts, got %d", attempts)
+	}
+}
+
+func TestConnectBackoff(t *testing.T) {
+	t.Parallel()
+
+	connect := func(seed int64) []time.Duration {
+		var sleeps []time.Duration
+		opts := &ConnectOptions{
+			Connector: func(ctx context.Context, driver, dsn string) (*sqlx.DB, error) {
+				return nil, errors.New("not ready")
+			},
+			Retry:           8,
+			RetryInterval:   time.Millisecond * 100,
+			RetryMaxBackoff: time.Second,
+			RandSource:      rand.NewSource(seed),
+			sleep: func(ctx context.Context, d time.Duration) error {
+				sleeps = append(sleeps, d)
+				return nil
+			},
+		}
+		if _, err := Connect(context.Background(), fakeDriverName, "unused", opts); err == nil {
+			t.Fatal("expecting error")
+		}
+		return sleeps
+	}
+
+	sleeps := connect(42)
+	if len(sleeps) != 7 {
+		t.Fatalf("expecting 7 backoffs between 8 attempts, got %d", len(sleeps))
+	}
+	for i, d := range sleeps {
+		limit := time.Millisecond * 100 << uint(i)
+		if limit > time.Second {
+			limit = time.Second
+		}
+		if d < 0 || d >= limit {
+			t.Errorf("attempt %d: expecting backoff between 0 and %s, got %s", i+1, limit, d)
+		}
+	}
+	if !reflect.DeepEqual(sleeps, connect(42)) {
+		t.Error("expecting the same backoff with the same seed")
+	}
+	if reflect.DeepEqual(sleeps, connect(7)) {
+		t.Error("expecting different backoff with different seed")
+	}
+}
@@ -0,0 +1,81 @@
+package sqldb
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+// ConnectErrorKind is the kind of failure to connect to the database
+type ConnectErrorKind string
+
+// list of connect error kind
+const (
+	// ConnectNotReady is returned when the connection is refused or the database is starting up, connect is retried
+	ConnectNotReady ConnectErrorKind = "not_ready"
+	// ConnectAuthFailed is returned when the credentials is rejected, connect is not retried
+	ConnectAuthFailed ConnectErrorKind = "auth_failed"
+	// ConnectHostNotFound is returned when the database host cannot be resolved, connect is not retried
+	ConnectHostNotFound ConnectErrorKind = "host_not_found"
+	// ConnectUnknown is returned for other failure, connect is retried
+	ConnectUnknown ConnectErrorKind = "unknown"
+)
+
+// ConnectError is returned by Connect when failed to connect to the database
+type ConnectError struct {
+	Kind ConnectErrorKind
+	// Attempts is the number of connect attempt before the error is returned
+	Attempts int
+	Err      error
+}
+
+// Error return the kind and original error
+func (ce *ConnectError) Error() string {
+	return fmt.Sprintf("sqldb: failed connect to database (%s): %s", ce.Kind, ce.Err.Error())
+}
+
+// Unwrap return the original error
+func (ce *ConnectError) Unwrap() error {
+	return ce.Err
+}
+
+// Retryable return true when connect might succeed by retrying
+func (kind ConnectErrorKind) Retryable() bool {
+	return kind == ConnectNotReady || kind == ConnectUnknown
+}
+
+// classifyConnectError return the kind of connect error
+func classifyConnectError(err error) ConnectErrorKind {
+	var (
+		dnsErr   *net.DNSError
+		pqErr    *pq.Error
+		mysqlErr *mysql.MySQLError
+	)
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ConnectNotReady
+	case errors.As(err, &dnsErr):
+		if dnsErr.IsNotFound {
+			return ConnectHostNotFound
+		}
+	case errors.As(err, &pqErr):
+		switch pqErr.Code {
+		// invalid_password and invalid_authorization_specification
+		case "28P01", "28000":
+			return ConnectAuthFailed
+		// cannot_connect_now, the database is starting up
+		case "57P03":
+			return ConnectNotReady
+		}
+	case errors.As(err, &mysqlErr):
+		// access denied for user
+		if mysqlErr.Number == 1045 {
+			return ConnectAuthFailed
+		}
+	}
+	return ConnectUnknown
+}
@@ -0,0 +1,115 @@
+package sqldb
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type consistencyKind int
+
+// list of consistency kind
+const (
+	consistencyEventual consistencyKind = iota
+	consistencyBoundedStaleness
+	consistencyStrong
+)
+
+// ConsistencyLevel of a read
+type ConsistencyLevel struct {
+	kind   consistencyKind
+	maxLag time.Duration
+}
+
+// list of consistency level
+var (
+	// Eventual read from any follower
+	Eventual = ConsistencyLevel{kind: consistencyEventual}
+	// Strong always read from leader
+	Strong = ConsistencyLevel{kind: consistencyStrong}
+)
+
+// BoundedStaleness read from follower with replication lag within maxLag, otherwise read from leader
+// follower with unknown replication lag is not used
+func BoundedStaleness(maxLag time.Duration) ConsistencyLevel {
+	return ConsistencyLevel{kind: consistencyBoundedStaleness, maxLag: maxLag}
+}
+
+type consistencyKey struct{}
+
+// WithConsistency set the consistency level of all reads using the context
+func WithConsistency(ctx context.Context, level ConsistencyLevel) context.Context {
+	return context.WithValue(ctx, consistencyKey{}, level)
+}
+
+// consistency return the consistency level of the context, the target of the operation policy is used when not set
+// read forced by WithFollowerRead is always eventual
+func (db *DB) consistency(ctx context.Context) ConsistencyLevel {
+	if followerReadFromContext(ctx) {
+		return Eventual
+	}
+	if level, ok := ctx.Value(consistencyKey{}).(ConsistencyLevel); ok {
+		return level
+	}
+	if level, ok := db.policyConsistency(ctx); ok {
+		return level
+	}
+	return Eventual
+}
+
+// reader return the database connection for read and the target name
+// follower that is down is not used, read that is sent to leader is counted by the degradation reason
+func (db *DB) reader(ctx context.Context) (*sqlx.DB, string) {
+	if written(ctx) {
+		return db.degradeToLeader(degradedStickyRead)
+	}
+
+	level := db.consistency(ctx)
+	switch level.kind {
+	case consistencyStrong:
+		return db.degradeToLeader(degradedForced)
+	case consistencyBoundedStaleness:
+		available := false
+		f := db.selectPreferredFollower(func(f *followerDB) bool {
+			if !f.available() {
+				return false
+			}
+			available = true
+			lag, ok := f.replicationLag()
+			return ok && lag <= level.maxLag
+		})
+		if f != nil {
+			return db.followerReader(f)
+		}
+		if !available {
+			return db.degradeToLeader(degradedFollowerDown)
+		}
+		return db.degradeToLeader(degradedLagExceeded)
+	}
+
+	if key, ok := affinityKeyFromContext(ctx); ok {
+		if f := db.affinityFollower(key); f.available() {
+			return f.db, targetFollower
+		}
+	}
+	f := db.selectPreferredFollower((*followerDB).available)
+	if f == nil {
+		return db.degradeToLeader(degradedFollowerDown)
+	}
+	return db.followerReader(f)
+}
+
+// followerReader return the follower for read, read that fall back from the primary tier is counted by the tier
+func (db *DB) followerReader(f *followerDB) (*sqlx.DB, string) {
+	if f.tier != db.primaryTier() {
+		_sqldbReadTierFallbackCount.WithLabelValues(strconv.Itoa(f.tier)).Inc()
+	}
+	return f.db, targetFollower
+}
+
+func (db *DB) degradeToLeader(reason string) (*sqlx.DB, string) {
+	_sqldbReadDegradedCount.WithLabelValues(reason).Inc()
+	return db.leader, targetLeader
+}
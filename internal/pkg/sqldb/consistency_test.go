@@ -0,0 +1,95 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func lagHandler(lag float64) fakeHandler {
+	return func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		if query == "SELECT lag" {
+			return fakeResult{columns: []string{"lag"}, rows: [][]driver.Value{{lag}}}
+		}
+		return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}}
+	}
+}
+
+func TestConsistencyLevel(t *testing.T) {
+	t.Parallel()
+
+	db, leader, _ := newFakeDB(t, WithReplicationLagQuery("SELECT lag"))
+	leader.setHandler(lagHandler(0))
+	fresh, freshDB := newFakeServer(t, "fresh")
+	fresh.setHandler(lagHandler(1))
+	stale, staleDB := newFakeServer(t, "stale")
+	stale.setHandler(lagHandler(10))
+
+	if err := db.SetFollowers([]*sqlx.DB{freshDB, staleDB}); err != nil {
+		t.Fatal(err)
+	}
+	db.checkFollowers(context.Background())
+
+	const query = "SELECT id FROM users"
+	cases := []struct {
+		name   string
+		level  ConsistencyLevel
+		expect []*fakeServer
+	}{
+		{
+			name:   "eventual",
+			level:  Eventual,
+			expect: []*fakeServer{fresh, stale},
+		},
+		{
+			name:   "bounded staleness within lag",
+			level:  BoundedStaleness(time.Second * 5),
+			expect: []*fakeServer{fresh},
+		},
+		{
+			name:   "bounded staleness exceeded",
+			level:  BoundedStaleness(time.Millisecond * 500),
+			expect: []*fakeServer{leader},
+		},
+		{
+			name:   "strong",
+			level:  Strong,
+			expect: []*fakeServer{leader},
+		},
+	}
+
+	for _, c := range cases {
+		servers := []*fakeServer{leader, fresh, stale}
+		before := make(map[*fakeServer]int)
+		for _, s := range servers {
+			before[s] = s.count(query)
+		}
+
+		ctx := WithConsistency(context.Background(), c.level)
+		for i := 0; i < 4; i++ {
+			var id int
+			if err := db.GetContext(ctx, &id, query); err != nil {
+				t.Fatalf("%s: %v", c.name, err)
+			}
+		}
+
+		for _, s := range servers {
+			expected := false
+			for _, e := range c.expect {
+				if e == s {
+					expected = true
+				}
+			}
+			got := s.count(query) - before[s]
+			if expected && got == 0 {
+				t.Errorf("%s: expecting %s to receive read", c.name, s.name)
+			}
+			if !expected && got > 0 {
+				t.Errorf("%s: expecting %s to not receive read, got %d", c.name, s.name, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package sqldb
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+// ConstraintKind is the kind of violated constraint
+type ConstraintKind string
+
+// list of constraint kind
+const (
+	ConstraintUnique     ConstraintKind = "unique"
+	ConstraintForeignKey ConstraintKind = "foreign_key"
+	ConstraintNotNull    ConstraintKind = "not_null"
+	ConstraintCheck      ConstraintKind = "check"
+)
+
+var (
+	// mysql doesn't return the constraint name as a field, so it is parsed from the message
+	mysqlDuplicateKeyRegex = regexp.MustCompile("for key '([^']+)'")
+	mysqlForeignKeyRegex   = regexp.MustCompile("CONSTRAINT `([^`]+)`")
+	mysqlCheckRegex        = regexp.MustCompile("Check constraint '([^']+)'")
+)
+
+// ConstraintError is returned when a query violate a database constraint
+type ConstraintError struct {
+	Kind ConstraintKind
+	// Constraint is the name of the constraint or index that is violated
+	Constraint string
+	Table      string
+	// Message is the user-facing message from Options.ConstraintMessages, empty if not mapped
+	Message string
+	Err     error
+}
+
+// Error return the mapped message, or the original error if the constraint is not mapped
+func (ce *ConstraintError) Error() string {
+	if ce.Message != "" {
+		return ce.Message
+	}
+	return fmt.Sprintf("sqldb: %s constraint %s violated: %s", ce.Kind, ce.Constraint, ce.Err.Error())
+}
+
+// Unwrap return the original driver error
+func (ce *ConstraintError) Unwrap() error {
+	return ce.Err
+}
+
+// ParseConstraintError parse postgres and mysql constraint violation error into ConstraintError
+// false is returned if the error is not a constraint violation
+func (db *DB) ParseConstraintError(err error) (*ConstraintError, bool) {
+	if err == nil {
+		return nil, false
+	}
+
+	var ce *ConstraintError
+	if errors.As(err, &ce) {
+		return ce, true
+	}
+
+	var (
+		pqErr    *pq.Error
+		mysqlErr *mysql.MySQLError
+	)
+	switch {
+	case errors.As(err, &pqErr):
+		ce = &ConstraintError{Constraint: pqErr.Constraint, Table: pqErr.Table, Err: err}
+		switch pqErr.Code {
+		case "23505":
+			ce.Kind = ConstraintUnique
+		case "23503":
+			ce.Kind = ConstraintForeignKey
+		case "23502":
+			ce.Kind = ConstraintNotNull
+			ce.Constraint = pqErr.Column
+		case "23514":
+			ce.Kind = ConstraintCheck
+		default:
+			return nil, false
+		}
+	case errors.As(err, &mysqlErr):
+		ce = &ConstraintError{Err: err}
+		var matches []string
+		switch mysqlErr.Number {
+		case 1062:
+			ce.Kind = ConstraintUnique
+			matches = mysqlDuplicateKeyRegex.FindStringSubmatch(mysqlErr.Message)
+		case 1451, 1452:
+			ce.Kind = ConstraintForeignKey
+			matches = mysqlForeignKeyRegex.FindStringSubmatch(mysqlErr.Message)
+		case 1048:
+			ce.Kind = ConstraintNotNull
+		case 3819:
+			ce.Kind = ConstraintCheck
+			matches = mysqlCheckRegex.FindStringSubmatch(mysqlErr.Message)
+		default:
+			return nil, false
+		}
+		if len(matches) == 2 {
+			ce.Constraint = matches[1]
+		}
+	default:
+		return nil, false
+	}
+
+	ce.Message = db.opts.ConstraintMessages[ce.Constraint]
+	return ce, true
+}
+
+// constraintError return ConstraintError if err is a constraint violation, otherwise return err as it is
+func (db *DB) constraintError(err error) error {
+	if ce, ok := db.ParseConstraintError(err); ok {
+		return ce
+	}
+	return err
+}
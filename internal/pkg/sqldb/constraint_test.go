@@ -0,0 +1,99 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+func TestParseConstraintError(t *testing.T) {
+	t.Parallel()
+
+	db := &DB{opts: Options{ConstraintMessages: map[string]string{
+		"users_email_active_idx": "email is already registered",
+	}}}
+
+	cases := []struct {
+		name       string
+		err        error
+		ok         bool
+		kind       ConstraintKind
+		constraint string
+		message    string
+	}{
+		{
+			name:       "postgres partial unique index",
+			err:        &pq.Error{Code: "23505", Constraint: "users_email_active_idx", Table: "users"},
+			ok:         true,
+			kind:       ConstraintUnique,
+			constraint: "users_email_active_idx",
+			message:    "email is already registered",
+		},
+		{
+			name:       "postgres foreign key",
+			err:        &pq.Error{Code: "23503", Constraint: "orders_user_id_fkey", Table: "orders"},
+			ok:         true,
+			kind:       ConstraintForeignKey,
+			constraint: "orders_user_id_fkey",
+		},
+		{
+			name:       "mysql duplicate entry",
+			err:        &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'a@example.com' for key 'users_email_active_idx'"},
+			ok:         true,
+			kind:       ConstraintUnique,
+			constraint: "users_email_active_idx",
+			message:    "email is already registered",
+		},
+		{
+			name: "not a constraint error",
+			err:  &pq.Error{Code: "42P01"},
+		},
+		{
+			name: "generic error",
+			err:  errors.New("connection refused"),
+		},
+	}
+
+	for _, c := range cases {
+		ce, ok := db.ParseConstraintError(c.err)
+		if ok != c.ok {
+			t.Errorf("%s: expecting ok %v, got %v", c.name, c.ok, ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if ce.Kind != c.kind || ce.Constraint != c.constraint || ce.Message != c.message {
+			t.Errorf("%s: unexpected constraint error %+v", c.name, ce)
+		}
+		if c.message != "" && ce.Error() != c.message {
+			t.Errorf("%s: expecting error message %s, got %s", c.name, c.message, ce.Error())
+		}
+	}
+}
+
+func TestExecConstraintError(t *testing.T) {
+	t.Parallel()
+
+	db, leader, _ := newFakeDB(t, WithConstraintMessages(map[string]string{"users_email_key": "email is already registered"}))
+	leader.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{err: &pq.Error{Code: "23505", Constraint: "users_email_key"}}
+	})
+
+	_, err := db.Exec("INSERT INTO users(email) VALUES(?)", "a@example.com")
+	var ce *ConstraintError
+	if !errors.As(err, &ce) {
+		t.Fatalf("expecting constraint error, got %v", err)
+	}
+	if ce.Error() != "email is already registered" {
+		t.Errorf("expecting mapped message, got %s", ce.Error())
+	}
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		t.Error("expecting original driver error to be unwrapped")
+	}
+}
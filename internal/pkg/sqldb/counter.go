@@ -0,0 +1,97 @@
+package sqldb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/albertwidi/go-project-example/internal/pkg/log/logger"
+)
+
+// counterKey is the key of a buffered counter, one key is one row and column to update
+type counterKey struct {
+	table    string
+	column   string
+	whereCol string
+	whereVal interface{}
+}
+
+// counterBuffer buffer counter delta in memory before flushed to the database
+type counterBuffer struct {
+	mu     sync.Mutex
+	deltas map[counterKey]int64
+}
+
+func (cb *counterBuffer) add(key counterKey, delta int64) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if cb.deltas == nil {
+		cb.deltas = make(map[counterKey]int64)
+	}
+	cb.deltas[key] += delta
+}
+
+func (cb *counterBuffer) swap() map[counterKey]int64 {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	deltas := cb.deltas
+	cb.deltas = nil
+	return deltas
+}
+
+// IncrementCounter buffer the increment of a counter column in memory
+// the buffered delta is written to leader using a single UPDATE per row when the counters is flushed
+// whereVal must be a comparable value, for example string or int
+func (db *DB) IncrementCounter(ctx context.Context, table, col, whereCol string, whereVal interface{}, delta int64) error {
+	if err := validateIdentifier(table, col, whereCol); err != nil {
+		return err
+	}
+	if whereVal == nil || !reflect.TypeOf(whereVal).Comparable() {
+		return fmt.Errorf("sqldb: counter where value must be comparable, got %T", whereVal)
+	}
+
+	db.counters.add(counterKey{table: table, column: col, whereCol: whereCol, whereVal: whereVal}, delta)
+	return nil
+}
+
+// FlushCounters write all buffered counter delta to the database
+// delta that failed to be written is put back to the buffer
+func (db *DB) FlushCounters(ctx context.Context) error {
+	var firstErr error
+	for key, delta := range db.counters.swap() {
+		if delta == 0 {
+			continue
+		}
+		var (
+			table    = db.QuoteIdentifier(key.table)
+			column   = db.QuoteIdentifier(key.column)
+			whereCol = db.QuoteIdentifier(key.whereCol)
+			query    = db.Rebind(fmt.Sprintf("UPDATE %s SET %s = %s + ? WHERE %s = ?", table, column, column, whereCol))
+		)
+		if _, err := db.ExecContext(ctx, query, delta, key.whereVal); err != nil {
+			db.counters.add(key, delta)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+func (db *DB) counterFlushLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-db.done:
+			return
+		case <-ticker.C:
+			if err := db.FlushCounters(context.Background()); err != nil && db.opts.Logger != nil {
+				db.opts.Logger.Errorw("sqldb: failed to flush counters", logger.KV{"error": err.Error()})
+			}
+		}
+	}
+}
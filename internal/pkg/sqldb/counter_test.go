@@ -0,0 +1,51 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+)
+
+func TestIncrementCounter(t *testing.T) {
+	t.Parallel()
+
+	db, leader, _ := newFakeDB(t)
+	var (
+		query string
+		delta int64
+	)
+	leader.setHandler(func(ctx context.Context, q string, args []driver.NamedValue) fakeResult {
+		query = q
+		delta = args[0].Value.(int64)
+		return fakeResult{rowsAffected: 1}
+	})
+
+	for i := 0; i < 100; i++ {
+		if err := db.IncrementCounter(context.Background(), "posts", "view_count", "id", 10, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(leader.Queries()) != 0 {
+		t.Fatal("expecting counters to be buffered before flush")
+	}
+
+	if err := db.FlushCounters(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(leader.Queries()); n != 1 {
+		t.Fatalf("expecting 1 flushed update, got %d", n)
+	}
+	if query != "UPDATE posts SET view_count = view_count + ? WHERE id = ?" {
+		t.Errorf("unexpected flush query %s", query)
+	}
+	if delta != 100 {
+		t.Errorf("expecting aggregated delta of 100, got %d", delta)
+	}
+
+	if err := db.IncrementCounter(context.Background(), "posts; DROP TABLE posts", "view_count", "id", 10, 1); err == nil {
+		t.Error("expecting error for invalid table name")
+	}
+	if err := db.IncrementCounter(context.Background(), "posts", "view_count", "id", []byte("10"), 1); err == nil {
+		t.Error("expecting error for non comparable where value")
+	}
+}
@@ -0,0 +1,68 @@
+package sqldb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	errInvalidBatchSize  = errors.New("sqldb: batch size must be greater than zero")
+	errInvalidDeleteStmt = errors.New("sqldb: query is not a valid DELETE statement")
+	errDeleteWithLimit   = errors.New("sqldb: DELETE query for batches must not contain LIMIT")
+
+	deleteStmtRegex = regexp.MustCompile(`(?is)^\s*DELETE\s+FROM\s+([^\s;]+)(?:\s+WHERE\s+(.+?))?\s*;?\s*$`)
+	limitRegex      = regexp.MustCompile(`(?i)\bLIMIT\b`)
+)
+
+// DeleteInBatches delete rows in batches of batchSize until no rows remain, and return total deleted rows
+// this keeps each delete small, to avoid long locks and replication lag spikes
+// mysql uses DELETE ... LIMIT, while postgres which doesn't support DELETE ... LIMIT uses ctid sub-query
+func (db *DB) DeleteInBatches(ctx context.Context, query string, batchSize int, args ...interface{}) (int64, error) {
+	if batchSize <= 0 {
+		return 0, errInvalidBatchSize
+	}
+
+	batchQuery, err := deleteBatchQuery(db.driver, query, batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for {
+		result, err := db.ExecContext(ctx, batchQuery, args...)
+		if err != nil {
+			return total, err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += affected
+		if affected < int64(batchSize) {
+			return total, nil
+		}
+	}
+}
+
+func deleteBatchQuery(driver, query string, batchSize int) (string, error) {
+	matches := deleteStmtRegex.FindStringSubmatch(query)
+	if matches == nil {
+		return "", errInvalidDeleteStmt
+	}
+	table, where := matches[1], matches[2]
+	if limitRegex.MatchString(where) {
+		return "", errDeleteWithLimit
+	}
+
+	if driver == "postgres" {
+		subQuery := "SELECT ctid FROM " + table
+		if where != "" {
+			subQuery += " WHERE " + where
+		}
+		return fmt.Sprintf("DELETE FROM %s WHERE ctid IN (%s LIMIT %d)", table, subQuery, batchSize), nil
+	}
+	return fmt.Sprintf("%s LIMIT %d", strings.TrimRight(strings.TrimSpace(query), ";"), batchSize), nil
+}
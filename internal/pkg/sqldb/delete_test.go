@@ -0,0 +1,81 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+)
+
+func TestDeleteBatchQuery(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		driver string
+		query  string
+		expect string
+		err    bool
+	}{
+		{
+			driver: "mysql",
+			query:  "DELETE FROM sessions WHERE expired_at < ?",
+			expect: "DELETE FROM sessions WHERE expired_at < ? LIMIT 100",
+		},
+		{
+			driver: "postgres",
+			query:  "DELETE FROM sessions WHERE expired_at < $1;",
+			expect: "DELETE FROM sessions WHERE ctid IN (SELECT ctid FROM sessions WHERE expired_at < $1 LIMIT 100)",
+		},
+		{
+			driver: "postgres",
+			query:  "DELETE FROM sessions",
+			expect: "DELETE FROM sessions WHERE ctid IN (SELECT ctid FROM sessions LIMIT 100)",
+		},
+		{
+			driver: "mysql",
+			query:  "DELETE FROM sessions WHERE id = ? LIMIT 10",
+			err:    true,
+		},
+		{
+			driver: "mysql",
+			query:  "UPDATE sessions SET expired = true",
+			err:    true,
+		},
+	}
+
+	for _, c := range cases {
+		query, err := deleteBatchQuery(c.driver, c.query, 100)
+		if (err != nil) != c.err {
+			t.Errorf("%s: expecting error %v, got %v", c.query, c.err, err)
+			continue
+		}
+		if query != c.expect {
+			t.Errorf("%s: expecting %s, got %s", c.query, c.expect, query)
+		}
+	}
+}
+
+func TestDeleteInBatches(t *testing.T) {
+	t.Parallel()
+
+	db, leader, _ := newFakeDB(t)
+	remaining := int64(250)
+	leader.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		deleted := remaining
+		if deleted > 100 {
+			deleted = 100
+		}
+		remaining -= deleted
+		return fakeResult{rowsAffected: deleted}
+	})
+
+	total, err := db.DeleteInBatches(context.Background(), "DELETE FROM sessions WHERE expired_at < ?", 100, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 250 {
+		t.Errorf("expecting 250 deleted rows, got %d", total)
+	}
+	if n := leader.count("DELETE FROM sessions WHERE expired_at < ? LIMIT 100"); n != 3 {
+		t.Errorf("expecting 3 batches of delete, got %d", n)
+	}
+}
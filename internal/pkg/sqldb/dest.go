@@ -0,0 +1,24 @@
+package sqldb
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// validateGetDest return error when dest of Get is not a non-nil pointer, so the caller get a clear error instead of sqlx reflection error
+func validateGetDest(dest interface{}) error {
+	value := reflect.ValueOf(dest)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return fmt.Errorf("sqldb: Get destination must be a non-nil pointer to a struct or scalar, got %T", dest)
+	}
+	return nil
+}
+
+// validateSelectDest return error when dest of Select is not a non-nil pointer to a slice
+func validateSelectDest(dest interface{}) error {
+	value := reflect.ValueOf(dest)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("sqldb: Select destination must be a pointer to a slice, got %T", dest)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+)
+
+func TestDestinationValidation(t *testing.T) {
+	t.Parallel()
+
+	db, _, follower := newFakeDB(t)
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}}
+	})
+	type user struct {
+		ID int64 `db:"id"`
+	}
+	var (
+		users  []user
+		u      user
+		nilPtr *[]user
+	)
+
+	selectCases := []struct {
+		dest   interface{}
+		expect string
+	}{
+		{dest: users, expect: "sqldb: Select destination must be a pointer to a slice, got []sqldb.user"},
+		{dest: &u, expect: "sqldb: Select destination must be a pointer to a slice, got *sqldb.user"},
+		{dest: nilPtr, expect: "sqldb: Select destination must be a pointer to a slice, got *[]sqldb.user"},
+		{dest: nil, expect: "sqldb: Select destination must be a pointer to a slice, got <nil>"},
+	}
+	for _, c := range selectCases {
+		if err := db.SelectContext(context.Background(), c.dest, "SELECT id FROM users"); err == nil || err.Error() != c.expect {
+			t.Errorf("expecting error %q, got %v", c.expect, err)
+		}
+	}
+
+	getCases := []struct {
+		dest   interface{}
+		expect string
+	}{
+		{dest: u, expect: "sqldb: Get destination must be a non-nil pointer to a struct or scalar, got sqldb.user"},
+		{dest: (*user)(nil), expect: "sqldb: Get destination must be a non-nil pointer to a struct or scalar, got *sqldb.user"},
+	}
+	for _, c := range getCases {
+		if err := db.GetContext(context.Background(), c.dest, "SELECT id FROM users"); err == nil || err.Error() != c.expect {
+			t.Errorf("expecting error %q, got %v", c.expect, err)
+		}
+	}
+	if n := follower.count("SELECT id FROM users"); n != 0 {
+		t.Errorf("expecting invalid destination not to be queried, got %d queries", n)
+	}
+
+	if err := db.SelectContext(context.Background(), &users, "SELECT id FROM users"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.GetContext(context.Background(), &u, "SELECT id FROM users"); err != nil {
+		t.Fatal(err)
+	}
+}
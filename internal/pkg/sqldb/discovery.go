@@ -0,0 +1,42 @@
+package sqldb
+
+import (
+	"context"
+	"time"
+
+	"github.com/albertwidi/go-project-example/internal/pkg/log/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+// FollowerDiscoveryFunc return the current follower set
+// the same *sqlx.DB should be returned for follower that is not changed, so its connection pool and state is kept
+type FollowerDiscoveryFunc func(ctx context.Context) ([]*sqlx.DB, error)
+
+func (db *DB) followerDiscoveryLoop(ticks <-chan time.Time, stop func()) {
+	defer stop()
+
+	for {
+		select {
+		case <-db.done:
+			return
+		case <-ticks:
+			db.discoverFollowers()
+		}
+	}
+}
+
+// discoverFollowers replace the followers with the result of FollowerDiscovery
+// removed follower is closed after its in-flight queries are finished, the followers is kept when discovery is failed
+func (db *DB) discoverFollowers() {
+	// the discovery must finish before the next refresh
+	ctx, cancel := context.WithTimeout(context.Background(), db.opts.FollowerDiscoveryInterval)
+	defer cancel()
+
+	followers, err := db.opts.FollowerDiscovery(ctx)
+	if err == nil {
+		err = db.SetFollowers(followers)
+	}
+	if err != nil && db.opts.Logger != nil {
+		db.opts.Logger.Warnw("sqldb: failed to refresh followers from discovery", logger.KV{"error": err.Error()})
+	}
+}
@@ -0,0 +1,79 @@
+package sqldb
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func TestFollowerDiscovery(t *testing.T) {
+	t.Parallel()
+
+	_, first := newFakeServer(t, "first")
+	_, second := newFakeServer(t, "second")
+	var (
+		mu           sync.Mutex
+		discovered   = []*sqlx.DB{first}
+		discoveryErr error
+	)
+	discover := func(ctx context.Context) ([]*sqlx.DB, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return discovered, discoveryErr
+	}
+
+	log := &fakeLogger{}
+	db, _, _ := newFakeDB(t, WithLogger(log), WithFollowerDiscovery(time.Hour, discover))
+	defer db.Close()
+
+	// the loop handle one tick at a time, so sending the next tick wait for the previous refresh
+	ticks := make(chan time.Time)
+	go db.followerDiscoveryLoop(ticks, func() {})
+	tick := func() {
+		ticks <- time.Now()
+		ticks <- time.Now()
+	}
+
+	tick()
+	if followers := db.Followers(); len(followers) != 1 || followers[0] != first {
+		t.Fatalf("expecting discovered follower, got %v", followers)
+	}
+
+	mu.Lock()
+	discovered = []*sqlx.DB{first, second}
+	mu.Unlock()
+	tick()
+	if followers := db.Followers(); len(followers) != 2 || followers[1] != second {
+		t.Fatalf("expecting new follower to be added, got %v", followers)
+	}
+
+	mu.Lock()
+	discovered = []*sqlx.DB{second}
+	mu.Unlock()
+	tick()
+	if followers := db.Followers(); len(followers) != 1 || followers[0] != second {
+		t.Fatalf("expecting removed follower to be dropped, got %v", followers)
+	}
+	deadline := time.Now().Add(time.Second)
+	for first.Ping() == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("expecting removed follower to be closed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	mu.Lock()
+	discoveryErr = errors.New("discovery unavailable")
+	mu.Unlock()
+	tick()
+	if followers := db.Followers(); len(followers) != 1 || followers[0] != second {
+		t.Errorf("expecting followers to be kept when discovery failed, got %v", followers)
+	}
+	if !log.contains("discovery unavailable") {
+		t.Error("expecting discovery error to be logged")
+	}
+}
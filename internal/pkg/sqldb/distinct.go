@@ -0,0 +1,68 @@
+package sqldb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// QueryDistinct select into dest and remove the duplicate values, the first-seen order is preserved
+// dest must be a pointer to slice of comparable type, for example *[]int64
+func QueryDistinct(ctx context.Context, db *DB, dest interface{}, query string, args ...interface{}) error {
+	value := reflect.ValueOf(dest)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("sqldb: QueryDistinct destination must be a pointer to a slice, got %T", dest)
+	}
+	if !value.Elem().Type().Elem().Comparable() {
+		return fmt.Errorf("sqldb: QueryDistinct destination element must be comparable, got %s", value.Elem().Type().Elem())
+	}
+
+	if err := db.SelectContext(ctx, dest, query, args...); err != nil {
+		return err
+	}
+
+	slice := value.Elem()
+	seen := make(map[interface{}]struct{}, slice.Len())
+	n := 0
+	for i := 0; i < slice.Len(); i++ {
+		v := slice.Index(i)
+		if !hashable(v) {
+			return fmt.Errorf("sqldb: QueryDistinct value %d is not comparable, got %T", i, v.Interface())
+		}
+		key := v.Interface()
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		slice.Index(n).Set(v)
+		n++
+	}
+	slice.Set(slice.Slice(0, n))
+	return nil
+}
+
+// hashable return true when v can be used as map key, interface type is comparable but its dynamic value might not be
+func hashable(v reflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Interface:
+		return v.IsNil() || hashable(v.Elem())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !hashable(v.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if !hashable(v.Index(i)) {
+				return false
+			}
+		}
+		return true
+	}
+	return v.Type().Comparable()
+}
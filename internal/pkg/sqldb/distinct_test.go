@@ -0,0 +1,45 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+)
+
+func TestQueryDistinct(t *testing.T) {
+	t.Parallel()
+
+	db, _, follower := newFakeDB(t)
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{
+			columns: []string{"user_id"},
+			rows:    [][]driver.Value{{int64(3)}, {int64(1)}, {int64(3)}, {int64(2)}, {int64(1)}},
+		}
+	})
+
+	var ids []int64
+	if err := QueryDistinct(context.Background(), db, &ids, "SELECT o.user_id FROM orders o JOIN items i ON i.order_id = o.id"); err != nil {
+		t.Fatal(err)
+	}
+	if expect := []int64{3, 1, 2}; !reflect.DeepEqual(ids, expect) {
+		t.Errorf("expecting %v, got %v", expect, ids)
+	}
+
+	var invalid [][]byte
+	if err := QueryDistinct(context.Background(), db, &invalid, "SELECT data FROM orders"); err == nil {
+		t.Error("expecting error for non comparable element")
+	}
+
+	// interface element is comparable, but the scanned []byte is not
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{columns: []string{"data"}, rows: [][]driver.Value{{[]byte("a")}, {[]byte("a")}}}
+	})
+	var values []interface{}
+	if err := QueryDistinct(context.Background(), db, &values, "SELECT data FROM orders"); err == nil {
+		t.Error("expecting error for non comparable value")
+	}
+	if !hashable(reflect.ValueOf(struct{ V interface{} }{V: 1})) || hashable(reflect.ValueOf([1]interface{}{[]byte("a")})) {
+		t.Error("expecting hashable to check the dynamic value")
+	}
+}
@@ -0,0 +1,61 @@
+package sqldb
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// list of drain default
+const (
+	defaultFollowerDrainTimeout = time.Second * 30
+	drainPollInterval           = time.Millisecond * 10
+)
+
+var errDrainTimeout = errors.New("sqldb: timeout waiting follower in-flight queries to finish")
+
+// followerByName return the follower with the name
+func (db *DB) followerByName(name string) (*followerDB, error) {
+	for _, f := range db.followerStates() {
+		if f.name == name {
+			return f, nil
+		}
+	}
+	return nil, fmt.Errorf("sqldb: follower %s not found", name)
+}
+
+// DrainFollower stop new reads to the follower and wait until its in-flight queries is finished
+// the in-flight queries is the connections in use, including rows that is not closed yet
+// errDrainTimeout is returned when the queries is not finished within Options.FollowerDrainTimeout,
+// the follower stay drained until ResumeFollower is called
+func (db *DB) DrainFollower(name string) error {
+	f, err := db.followerByName(name)
+	if err != nil {
+		return err
+	}
+	atomic.StoreInt32(&f.drained, 1)
+
+	timeout := db.opts.FollowerDrainTimeout
+	if timeout <= 0 {
+		timeout = defaultFollowerDrainTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	for f.db.Stats().InUse > 0 {
+		if time.Now().After(deadline) {
+			return errDrainTimeout
+		}
+		time.Sleep(drainPollInterval)
+	}
+	return nil
+}
+
+// ResumeFollower allow reads to the drained follower again
+func (db *DB) ResumeFollower(name string) error {
+	f, err := db.followerByName(name)
+	if err != nil {
+		return err
+	}
+	atomic.StoreInt32(&f.drained, 0)
+	return nil
+}
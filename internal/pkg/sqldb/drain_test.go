@@ -0,0 +1,148 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDrainFollower(t *testing.T) {
+	t.Parallel()
+
+	db, _, _ := newFakeDB(t)
+	serverA, followerA := newFakeServer(t, "a")
+	serverB, followerB := newFakeServer(t, "b")
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	serverA.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		if query == "SELECT slow" {
+			started <- struct{}{}
+			<-release
+		}
+		return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}}
+	})
+	serverB.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(2)}}}
+	})
+	if err := db.SetNamedFollowers([]NamedFollower{{Name: "a", DB: followerA}, {Name: "b", DB: followerB}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// start in-flight read on follower a
+	inflight := make(chan error, 1)
+	go func() {
+		var id int
+		inflight <- followerA.Get(&id, "SELECT slow")
+	}()
+	<-started
+
+	drained := make(chan error, 1)
+	go func() {
+		drained <- db.DrainFollower("a")
+	}()
+
+	// wait until the follower is drained
+	deadline := time.Now().Add(time.Second * 5)
+	for db.followerStates()[0].available() {
+		if time.Now().After(deadline) {
+			t.Fatal("expecting follower a to be drained")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	for i := 0; i < 10; i++ {
+		var id int
+		if err := db.GetContext(context.Background(), &id, "SELECT id FROM users"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := serverA.count("SELECT id FROM users"); n != 0 {
+		t.Errorf("expecting new reads to avoid drained follower, got %d", n)
+	}
+	if n := serverB.count("SELECT id FROM users"); n != 10 {
+		t.Errorf("expecting new reads to go to follower b, got %d", n)
+	}
+
+	select {
+	case err := <-drained:
+		t.Fatalf("expecting drain to wait for in-flight read, got %v", err)
+	default:
+	}
+	close(release)
+	if err := <-inflight; err != nil {
+		t.Errorf("expecting in-flight read to complete, got %v", err)
+	}
+	if err := <-drained; err != nil {
+		t.Errorf("expecting drain to succeed, got %v", err)
+	}
+
+	if err := db.ResumeFollower("a"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		var id int
+		db.GetContext(context.Background(), &id, "SELECT id FROM users")
+	}
+	if serverA.count("SELECT id FROM users") == 0 {
+		t.Error("expecting resumed follower to receive reads")
+	}
+
+	if err := db.DrainFollower("unknown"); err == nil {
+		t.Error("expecting error for unknown follower")
+	}
+}
+
+func TestDrainFollowerTimeout(t *testing.T) {
+	t.Parallel()
+
+	db, _, follower := newFakeDB(t, WithFollowerDrainTimeout(time.Millisecond*50))
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}}
+	})
+
+	// rows that is not closed keep the connection in use
+	rows, err := db.Follower().Query("SELECT id FROM users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	if err := db.DrainFollower(defaultFollowerName(0)); err != errDrainTimeout {
+		t.Errorf("expecting errDrainTimeout, got %v", err)
+	}
+}
+
+func TestDrainFollowerFallback(t *testing.T) {
+	t.Parallel()
+
+	db, _, _ := newFakeDB(t)
+	_, followerA := newFakeServer(t, "a")
+	_, followerB := newFakeServer(t, "b")
+	if err := db.SetNamedFollowers([]NamedFollower{{Name: "a", DB: followerA}, {Name: "b", DB: followerB}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// follower a is down and b is drained, the down follower is still preferred over leader
+	atomic.StoreInt32(&db.followerStates()[0].down, 1)
+	if err := db.DrainFollower("b"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		if f := db.Follower(); f != followerA {
+			t.Fatalf("expecting the follower that is not drained, got %p", f)
+		}
+	}
+
+	// all followers drained
+	if err := db.DrainFollower("a"); err != nil {
+		t.Fatal(err)
+	}
+	if f := db.Follower(); f != db.Leader() {
+		t.Errorf("expecting leader when all followers are drained, got %p", f)
+	}
+	if f := db.FollowerSQLDB(); f != db.LeaderSQLDB() {
+		t.Errorf("expecting leader sql db when all followers are drained, got %p", f)
+	}
+}
@@ -0,0 +1,24 @@
+package sqldb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// SelectOrEmpty is SelectContext that set dest to a non-nil empty slice when there are no rows
+// so the result is serialized to [] instead of null in JSON
+func (db *DB) SelectOrEmpty(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	value := reflect.ValueOf(dest)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("sqldb: SelectOrEmpty destination must be a pointer to a slice, got %T", dest)
+	}
+
+	if err := db.SelectContext(ctx, dest, query, args...); err != nil {
+		return err
+	}
+	if slice := value.Elem(); slice.IsNil() {
+		slice.Set(reflect.MakeSlice(slice.Type(), 0, 0))
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"testing"
+)
+
+func TestSelectOrEmpty(t *testing.T) {
+	t.Parallel()
+
+	db, _, follower := newFakeDB(t)
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{columns: []string{"id"}}
+	})
+
+	var ids []int64
+	if err := db.SelectOrEmpty(context.Background(), &ids, "SELECT id FROM users"); err != nil {
+		t.Fatal(err)
+	}
+	if ids == nil {
+		t.Fatal("expecting non-nil empty slice")
+	}
+	out, err := json.Marshal(ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "[]" {
+		t.Errorf("expecting [], got %s", out)
+	}
+
+	var id int64
+	if err := db.SelectOrEmpty(context.Background(), &id, "SELECT id FROM users"); err == nil {
+		t.Error("expecting error for non-slice destination")
+	}
+}
@@ -0,0 +1,70 @@
+package sqldb
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidEnumValue is returned when an enum value is not in the allowed set
+var ErrInvalidEnumValue = errors.New("sqldb: invalid enum value")
+
+// EnumSet is the allowed values of a string-backed enum
+// use it to implement sql.Scanner and driver.Valuer of the enum type, for example:
+//
+//	var statusEnum = sqldb.NewEnumSet("status", "active", "inactive")
+//
+//	func (s *Status) Scan(src interface{}) error { return statusEnum.Scan((*string)(s), src) }
+//	func (s Status) Value() (driver.Value, error) { return statusEnum.Value(string(s)) }
+type EnumSet struct {
+	name   string
+	values map[string]struct{}
+}
+
+// NewEnumSet create a new enum set, name is used in the error message
+func NewEnumSet(name string, values ...string) *EnumSet {
+	s := &EnumSet{
+		name:   name,
+		values: make(map[string]struct{}, len(values)),
+	}
+	for _, v := range values {
+		s.values[v] = struct{}{}
+	}
+	return s
+}
+
+// Valid return true if the value is in the allowed set
+func (s *EnumSet) Valid(v string) bool {
+	_, ok := s.values[v]
+	return ok
+}
+
+// Scan src into dest, return ErrInvalidEnumValue if the value is not in the allowed set
+// NULL is not a valid enum value, use a pointer of the enum type for nullable column
+func (s *EnumSet) Scan(dest *string, src interface{}) error {
+	var v string
+	switch src := src.(type) {
+	case string:
+		v = src
+	case []byte:
+		v = string(src)
+	case nil:
+		return fmt.Errorf("%w: %s cannot be NULL", ErrInvalidEnumValue, s.name)
+	default:
+		return fmt.Errorf("sqldb: cannot scan %T into %s enum", src, s.name)
+	}
+
+	if !s.Valid(v) {
+		return fmt.Errorf("%w: %s %q", ErrInvalidEnumValue, s.name, v)
+	}
+	*dest = v
+	return nil
+}
+
+// Value return the driver value of v, return ErrInvalidEnumValue if the value is not in the allowed set
+func (s *EnumSet) Value(v string) (driver.Value, error) {
+	if !s.Valid(v) {
+		return nil, fmt.Errorf("%w: %s %q", ErrInvalidEnumValue, s.name, v)
+	}
+	return v, nil
+}
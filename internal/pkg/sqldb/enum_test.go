@@ -0,0 +1,77 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testStatus string
+
+var testStatusEnum = NewEnumSet("status", "active", "inactive")
+
+func (s *testStatus) Scan(src interface{}) error  { return testStatusEnum.Scan((*string)(s), src) }
+func (s testStatus) Value() (driver.Value, error) { return testStatusEnum.Value(string(s)) }
+
+func TestEnumScan(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name   string
+		src    interface{}
+		expect testStatus
+		err    bool
+	}{
+		{name: "string", src: "active", expect: "active"},
+		{name: "bytes", src: []byte("inactive"), expect: "inactive"},
+		{name: "unknown value", src: "deleted", err: true},
+		{name: "null", src: nil, err: true},
+	}
+
+	for _, c := range cases {
+		var s testStatus
+		err := s.Scan(c.src)
+		if c.err {
+			if !errors.Is(err, ErrInvalidEnumValue) {
+				t.Errorf("%s: expecting ErrInvalidEnumValue, got %v", c.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if s != c.expect {
+			t.Errorf("%s: expecting %s, got %s", c.name, c.expect, s)
+		}
+	}
+}
+
+func TestEnumValue(t *testing.T) {
+	t.Parallel()
+
+	if v, err := testStatus("active").Value(); err != nil || v != "active" {
+		t.Errorf("expecting active, got %v %v", v, err)
+	}
+	if _, err := testStatus("deleted").Value(); !errors.Is(err, ErrInvalidEnumValue) {
+		t.Errorf("expecting ErrInvalidEnumValue, got %v", err)
+	}
+}
+
+func TestEnumScanFromQuery(t *testing.T) {
+	t.Parallel()
+
+	db, _, follower := newFakeDB(t)
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{columns: []string{"status"}, rows: [][]driver.Value{{"active"}, {"deleted"}}}
+	})
+
+	var statuses []testStatus
+	// database/sql doesn't wrap the scan error before go 1.16, so the message is checked
+	err := db.SelectContext(context.Background(), &statuses, "SELECT status FROM users")
+	if err == nil || !strings.Contains(err.Error(), `sqldb: invalid enum value: status "deleted"`) {
+		t.Fatalf("expecting invalid enum value error, got %v", err)
+	}
+}
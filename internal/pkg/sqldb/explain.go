@@ -0,0 +1,80 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/albertwidi/go-project-example/internal/pkg/log/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+type explainKey struct{}
+
+// WithExplain return a context that EXPLAIN every read using the context and log the plan at debug level
+// the EXPLAIN is executed after the read on the same connection pool, use this to debug a single query path
+func WithExplain(ctx context.Context) context.Context {
+	return context.WithValue(ctx, explainKey{}, true)
+}
+
+func explainEnabled(ctx context.Context) bool {
+	enabled, _ := ctx.Value(explainKey{}).(bool)
+	return enabled
+}
+
+// shouldExplain return true when the plan of the successful read should be logged
+func (db *DB) shouldExplain(ctx context.Context, q *queryInfo) bool {
+	return db.opts.Logger != nil && !q.write && q.handle != nil && explainEnabled(ctx)
+}
+
+// logQueryPlan EXPLAIN the query and log the plan
+func (db *DB) logQueryPlan(ctx context.Context, q *queryInfo) {
+	plan, err := explainQuery(ctx, q.handle, q.query, q.args...)
+	if err != nil {
+		db.opts.Logger.Warnw("sqldb: failed to explain query", logger.KV{
+			"fingerprint": q.fingerprint(),
+			"error":       err.Error(),
+		})
+		return
+	}
+	db.opts.Logger.Debugw("sqldb: query plan", logger.KV{
+		"query":  q.query,
+		"target": q.target,
+		"plan":   plan,
+	})
+}
+
+// explainQuery return the EXPLAIN output of the query, each row in a line and the columns is separated by " | "
+// postgres return one plan line per row, mysql return one table per row
+func explainQuery(ctx context.Context, handle *sqlx.DB, query string, args ...interface{}) (string, error) {
+	rows, err := handle.QueryContext(ctx, "EXPLAIN "+query, args...)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+	var lines []string
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return "", err
+		}
+		fields := make([]string, len(values))
+		for i, v := range values {
+			fields[i] = v.String
+		}
+		lines = append(lines, strings.Join(fields, " | "))
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+	return strings.Join(lines, "\n"), nil
+}
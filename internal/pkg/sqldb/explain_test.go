@@ -0,0 +1,52 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+)
+
+func TestWithExplain(t *testing.T) {
+	t.Parallel()
+
+	l := &fakeLogger{}
+	db, _, follower := newFakeDB(t, WithLogger(l))
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		if strings.HasPrefix(query, "EXPLAIN ") {
+			return fakeResult{columns: []string{"QUERY PLAN"}, rows: [][]driver.Value{
+				{"Index Scan using orders_user_id_idx on orders"},
+				{"  Index Cond: (user_id = $1)"},
+			}}
+		}
+		return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}}
+	})
+
+	var ids []int
+	if err := db.SelectContext(context.Background(), &ids, "SELECT id FROM users"); err != nil {
+		t.Fatal(err)
+	}
+	if follower.count("EXPLAIN SELECT id FROM users") != 0 || l.contains("sqldb: query plan") {
+		t.Fatal("expecting query without WithExplain to not be explained")
+	}
+
+	if err := db.SelectContext(WithExplain(context.Background()), &ids, "SELECT id FROM orders WHERE user_id = ?", 1); err != nil {
+		t.Fatal(err)
+	}
+	if follower.count("EXPLAIN SELECT id FROM orders WHERE user_id = ?") != 1 {
+		t.Error("expecting query with WithExplain to be explained")
+	}
+	for _, s := range []string{"[DEBUG] sqldb: query plan", "Index Scan using orders_user_id_idx on orders\n  Index Cond: (user_id = $1)"} {
+		if !l.contains(s) {
+			t.Errorf("expecting log to contain %q", s)
+		}
+	}
+
+	// write is never explained
+	if _, err := db.ExecContext(WithExplain(context.Background()), "DELETE FROM orders WHERE id = 1"); err != nil {
+		t.Fatal(err)
+	}
+	if l.contains("DELETE FROM orders") {
+		t.Error("expecting write to not be explained")
+	}
+}
@@ -0,0 +1,49 @@
+package sqldb
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// FaultInjector inject fault to queries for chaos testing
+// Inject is called before each query, the query is delayed by delay and failed with err when err is not nil
+// return driver.ErrBadConn to simulate a dropped connection
+type FaultInjector interface {
+	Inject(ctx context.Context, query, target string) (delay time.Duration, err error)
+}
+
+// faultInjector hold the current fault injector, faults is not injected when nil
+type faultInjector struct {
+	mu       sync.RWMutex
+	injector FaultInjector
+}
+
+// SetFaultInjector replace the fault injector at runtime, set nil to disable fault injection
+func (db *DB) SetFaultInjector(fi FaultInjector) {
+	db.faults.mu.Lock()
+	db.faults.injector = fi
+	db.faults.mu.Unlock()
+}
+
+// injectFault delay and return the error of the fault injector
+func (db *DB) injectFault(ctx context.Context, q *queryInfo) error {
+	db.faults.mu.RLock()
+	fi := db.faults.injector
+	db.faults.mu.RUnlock()
+	if fi == nil {
+		return nil
+	}
+
+	delay, err := fi.Inject(ctx, q.query, q.target)
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return err
+}
@@ -0,0 +1,90 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var errInjected = errors.New("injected fault")
+
+// everyOtherReadInjector fail every other read to follower
+type everyOtherReadInjector struct {
+	n int64
+}
+
+func (fi *everyOtherReadInjector) Inject(ctx context.Context, query, target string) (time.Duration, error) {
+	if target != targetFollower {
+		return 0, nil
+	}
+	if atomic.AddInt64(&fi.n, 1)%2 == 0 {
+		return 0, errInjected
+	}
+	return 0, nil
+}
+
+type delayInjector time.Duration
+
+func (di delayInjector) Inject(ctx context.Context, query, target string) (time.Duration, error) {
+	return time.Duration(di), nil
+}
+
+func TestFaultInjector(t *testing.T) {
+	t.Parallel()
+
+	db, leader, follower := newFakeDB(t, WithFaultInjector(&everyOtherReadInjector{}))
+	handler := func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}, rowsAffected: 1}
+	}
+	leader.setHandler(handler)
+	follower.setHandler(handler)
+
+	const reads = 100
+	failed := 0
+	for i := 0; i < reads; i++ {
+		var id int
+		if err := db.GetContext(context.Background(), &id, "SELECT id FROM users"); err != nil {
+			if err != errInjected {
+				t.Fatalf("unexpected error %v", err)
+			}
+			failed++
+		}
+	}
+	if failed != reads/2 {
+		t.Errorf("expecting %d failed reads, got %d", reads/2, failed)
+	}
+	if n := follower.count("SELECT id FROM users"); n != reads-failed {
+		t.Errorf("expecting failed reads to not reach the database, got %d queries", n)
+	}
+	if _, err := db.ExecContext(context.Background(), "UPDATE users SET name = 'a' WHERE id = 1"); err != nil {
+		t.Errorf("expecting write to be unaffected, got %v", err)
+	}
+
+	// disabled injector doesn't fail any read
+	db.SetFaultInjector(nil)
+	for i := 0; i < 10; i++ {
+		var id int
+		if err := db.GetContext(context.Background(), &id, "SELECT id FROM users"); err != nil {
+			t.Fatalf("expecting no error after injector is disabled, got %v", err)
+		}
+	}
+}
+
+func TestFaultInjectorDelay(t *testing.T) {
+	t.Parallel()
+
+	db, _, follower := newFakeDB(t, WithFaultInjector(delayInjector(time.Hour)))
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancel()
+	var id int
+	if err := db.GetContext(ctx, &id, "SELECT id FROM users"); err != context.DeadlineExceeded {
+		t.Errorf("expecting delay to respect the context deadline, got %v", err)
+	}
+}
@@ -0,0 +1,32 @@
+package sqldb
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	fingerprintCommentRegex     = regexp.MustCompile(`(?s)--[^\n]*|/\*.*?\*/`)
+	fingerprintStringRegex      = regexp.MustCompile(`'(?:[^']|'')*'`)
+	fingerprintPlaceholderRegex = regexp.MustCompile(`\$\d+|@[A-Za-z_][A-Za-z0-9_]*`)
+	// named placeholder is not preceded by colon, to not match postgres type cast like ::jsonb
+	fingerprintNamedRegex  = regexp.MustCompile(`(^|[^:]):[A-Za-z_][A-Za-z0-9_]*`)
+	fingerprintNumberRegex = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+	fingerprintListRegex   = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)*\s*\)`)
+	fingerprintSpaceRegex  = regexp.MustCompile(`\s+`)
+)
+
+// Fingerprint return the normalized form of a query
+// literals and placeholders are replaced with ?, and list of values is collapsed
+// so queries with the same shape have the same fingerprint. For example:
+// SELECT * FROM users WHERE id IN (1, 2, 3) have the same fingerprint with SELECT * FROM users WHERE id IN (?)
+func Fingerprint(query string) string {
+	fp := fingerprintCommentRegex.ReplaceAllString(query, " ")
+	fp = fingerprintStringRegex.ReplaceAllString(fp, "?")
+	fp = fingerprintPlaceholderRegex.ReplaceAllString(fp, "?")
+	fp = fingerprintNamedRegex.ReplaceAllString(fp, "${1}?")
+	fp = fingerprintNumberRegex.ReplaceAllString(fp, "?")
+	fp = fingerprintListRegex.ReplaceAllString(fp, "(?)")
+	fp = fingerprintSpaceRegex.ReplaceAllString(fp, " ")
+	return strings.ToLower(strings.TrimSpace(fp))
+}
@@ -0,0 +1,39 @@
+package sqldb
+
+import "testing"
+
+func TestFingerprint(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		query  string
+		expect string
+	}{
+		{
+			query:  "SELECT * FROM users WHERE id = 10",
+			expect: "select * from users where id = ?",
+		},
+		{
+			query:  "select *  from users\n where id = $1 -- by id",
+			expect: "select * from users where id = ?",
+		},
+		{
+			query:  "SELECT * FROM users WHERE name = 'it''s' AND id IN (1, 2, 3)",
+			expect: "select * from users where name = ? and id in (?)",
+		},
+		{
+			query:  "SELECT * FROM users WHERE name = :name AND data::jsonb @> :data",
+			expect: "select * from users where name = ? and data::jsonb @> ?",
+		},
+		{
+			query:  "SELECT * FROM users_2019 WHERE id = ?",
+			expect: "select * from users_2019 where id = ?",
+		},
+	}
+
+	for _, c := range cases {
+		if got := Fingerprint(c.query); got != c.expect {
+			t.Errorf("%s: expecting %s, got %s", c.query, c.expect, got)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package sqldb
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrFollowerReadInTx is returned when a read forced to follower by WithFollowerRead is done inside a leader transaction
+// the follower doesn't see the uncommitted writes of the transaction, so mixing both is never consistent
+var ErrFollowerReadInTx = errors.New("sqldb: cannot read from follower inside a leader transaction")
+
+type followerReadKey struct{}
+
+// WithFollowerRead force reads using the context to go to follower regardless of the operation policy
+// the read return ErrFollowerReadInTx when the context is inside WithTransaction or a began unit of work
+func WithFollowerRead(ctx context.Context) context.Context {
+	return context.WithValue(ctx, followerReadKey{}, true)
+}
+
+func followerReadFromContext(ctx context.Context) bool {
+	forced, _ := ctx.Value(followerReadKey{}).(bool)
+	return forced
+}
+
+type txKey struct{}
+
+// contextTx is the transaction of WithTransaction context
+type contextTx struct {
+	db *DB
+	tx *Tx
+}
+
+func withContextTx(ctx context.Context, db *DB, tx *Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, &contextTx{db: db, tx: tx})
+}
+
+// contextTx return the transaction of WithTransaction context, transaction began in other DB is not returned
+func (db *DB) contextTx(ctx context.Context) (*Tx, bool) {
+	ctxTx, ok := ctx.Value(txKey{}).(*contextTx)
+	if !ok || ctxTx.db != db {
+		return nil, false
+	}
+	return ctxTx.tx, true
+}
+
+// txReader return the transaction that reads using the context go to, instead of follower
+func (db *DB) txReader(ctx context.Context) (*Tx, bool, error) {
+	tx, ok, _ := db.unitOfWorkTx(ctx, false)
+	if ok && followerReadFromContext(ctx) {
+		return nil, false, ErrFollowerReadInTx
+	}
+	return tx, ok, nil
+}
+
+// readQueryer return the transaction of txReader when the context is inside one, and the reader of queryReader otherwise
+// the returned queryInfo has the target and handle of the chosen queryer
+func (db *DB) readQueryer(ctx context.Context, query string, args []interface{}) (sqlx.QueryerContext, *queryInfo, error) {
+	tx, ok, err := db.txReader(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ok {
+		return tx, &queryInfo{query: query, args: args, target: targetLeader}, nil
+	}
+	reader, target := db.queryReader(ctx, query)
+	return reader, &queryInfo{query: query, args: args, target: target, handle: reader}, nil
+}
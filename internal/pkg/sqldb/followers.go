@@ -0,0 +1,273 @@
+package sqldb
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var errFollowersEmpty = errors.New("sqldb: followers cannot be empty")
+
+// NamedFollower is a follower connection with a name, the name is used to refer the follower at runtime
+type NamedFollower struct {
+	Name string
+	// AZ is the availability zone of the follower, follower in Options.PreferredAZ is preferred for read
+	AZ string
+	// Tier of the follower, lower tier is preferred. Follower in higher tier, for example in secondary region,
+	// is only used when no follower in lower tier is available
+	Tier int
+	DB   *sqlx.DB
+}
+
+// followerDB hold a follower connection and its state
+type followerDB struct {
+	name string
+	az   string
+	tier int
+	db   *sqlx.DB
+	// lag is the last known replication lag in nanosecond, -1 if unknown
+	lag int64
+	// down is 1 when the last health check is failed
+	down int32
+	// drained is 1 when the follower is drained by DrainFollower
+	drained int32
+	// version is the last known schema version, nil if unknown
+	version atomic.Value
+}
+
+func newFollower(name string, db *sqlx.DB) *followerDB {
+	return &followerDB{name: name, db: db, lag: -1}
+}
+
+// defaultFollowerName is the name of follower that is not set by SetNamedFollowers
+func defaultFollowerName(i int) string {
+	return fmt.Sprintf("follower-%d", i)
+}
+
+// replicationLag return the last known replication lag, false if unknown
+func (f *followerDB) replicationLag() (time.Duration, bool) {
+	lag := atomic.LoadInt64(&f.lag)
+	if lag < 0 {
+		return 0, false
+	}
+	return time.Duration(lag), true
+}
+
+func (f *followerDB) setReplicationLag(lag time.Duration) {
+	atomic.StoreInt64(&f.lag, int64(lag))
+}
+
+// schemaVersion return the last known schema version, false if unknown
+func (f *followerDB) schemaVersion() (string, bool) {
+	version, ok := f.version.Load().(string)
+	return version, ok
+}
+
+// available return false if the follower is down on the last health check or drained
+func (f *followerDB) available() bool {
+	return atomic.LoadInt32(&f.down) == 0 && atomic.LoadInt32(&f.drained) == 0
+}
+
+func (f *followerDB) setAvailable(available bool) {
+	var down int32
+	if !available {
+		down = 1
+	}
+	atomic.StoreInt32(&f.down, down)
+}
+
+// SetFollowers swap the followers of DB at runtime
+// followers that are removed from the list is closed after all in-flight queries are finished
+// retained followers keep their name, AZ and tier, new followers is named follower-<index>
+func (db *DB) SetFollowers(followers []*sqlx.DB) error {
+	existing := make(map[*sqlx.DB]*followerDB)
+	for _, f := range db.followerStates() {
+		existing[f.db] = f
+	}
+	named := make([]NamedFollower, len(followers))
+	for i, f := range followers {
+		named[i] = NamedFollower{Name: defaultFollowerName(i), DB: f}
+		if ef, ok := existing[f]; ok {
+			named[i].Name, named[i].AZ, named[i].Tier = ef.name, ef.az, ef.tier
+		}
+	}
+	return db.SetNamedFollowers(named)
+}
+
+// checkFollowerDriver return error when the follower driver is different with the leader, unless AllowDriverMismatch is set
+func (db *DB) checkFollowerDriver(follower *sqlx.DB) error {
+	if db.opts.AllowDriverMismatch || follower.DriverName() == db.driver {
+		return nil
+	}
+	return fmt.Errorf("sqldb: leader and follower driver is not matched. leader = %s follower = %s", db.driver, follower.DriverName())
+}
+
+// SetNamedFollowers swap the followers of DB at runtime, the name of each follower must be unique
+// followers that are removed from the list is closed after all in-flight queries are finished
+func (db *DB) SetNamedFollowers(followers []NamedFollower) error {
+	if len(followers) == 0 {
+		return errFollowersEmpty
+	}
+	names := make(map[string]bool, len(followers))
+	for _, f := range followers {
+		if err := db.checkFollowerDriver(f.DB); err != nil {
+			return err
+		}
+		if f.Name == "" || names[f.Name] {
+			return fmt.Errorf("sqldb: follower name %q is empty or duplicated", f.Name)
+		}
+		names[f.Name] = true
+	}
+
+	db.followersMu.Lock()
+	if db.mapper != nil {
+		for _, f := range followers {
+			f.DB.Mapper = db.mapper
+		}
+	}
+	oldFollowers := db.followers
+	existing := make(map[*sqlx.DB]*followerDB, len(oldFollowers))
+	for _, f := range oldFollowers {
+		existing[f.db] = f
+	}
+	newFollowers := make([]*followerDB, len(followers))
+	for i, f := range followers {
+		// keep the state of follower that still exist
+		if ef, ok := existing[f.DB]; ok && ef.name == f.Name && ef.az == f.AZ && ef.tier == f.Tier {
+			newFollowers[i] = ef
+			continue
+		}
+		newFollowers[i] = newFollower(f.Name, f.DB)
+		newFollowers[i].az = f.AZ
+		newFollowers[i].tier = f.Tier
+	}
+	db.followers = newFollowers
+	db.tiers = followerTiers(newFollowers)
+	db.followersMu.Unlock()
+
+	keep := make(map[*sqlx.DB]bool, len(newFollowers)+1)
+	keep[db.leader] = true
+	for _, f := range newFollowers {
+		keep[f.db] = true
+	}
+	for _, f := range oldFollowers {
+		if keep[f.db] {
+			continue
+		}
+		_sqldbFollowerLagGauge.DeleteLabelValues(f.name, f.az)
+		db.stmts.purge(f.db)
+		// close wait for all in-flight queries to finish
+		go Disconnect(f.db)
+	}
+	return nil
+}
+
+// Followers return all follower database connection
+func (db *DB) Followers() []*sqlx.DB {
+	followers := db.followerStates()
+	dbs := make([]*sqlx.DB, len(followers))
+	for i, f := range followers {
+		dbs[i] = f.db
+	}
+	return dbs
+}
+
+func (db *DB) followerStates() []*followerDB {
+	db.followersMu.RLock()
+	defer db.followersMu.RUnlock()
+	followers := make([]*followerDB, len(db.followers))
+	copy(followers, db.followers)
+	return followers
+}
+
+// nextFollower select available follower using round-robin, any follower that is not drained is selected when none is available
+// leader is returned when all followers are drained
+func (db *DB) nextFollower() *sqlx.DB {
+	if f := db.selectPreferredFollower((*followerDB).available); f != nil {
+		return f.db
+	}
+	if f := db.selectFollower(func(f *followerDB) bool { return atomic.LoadInt32(&f.drained) == 0 }); f != nil {
+		return f.db
+	}
+	leader, _ := db.degradeToLeader(degradedFollowerDown)
+	return leader
+}
+
+// followerTiers return the sorted unique tiers of followers
+func followerTiers(followers []*followerDB) []int {
+	var tiers []int
+	seen := make(map[int]bool)
+	for _, f := range followers {
+		if !seen[f.tier] {
+			seen[f.tier] = true
+			tiers = append(tiers, f.tier)
+		}
+	}
+	sort.Ints(tiers)
+	return tiers
+}
+
+// primaryTier return the lowest follower tier
+func (db *DB) primaryTier() int {
+	db.followersMu.RLock()
+	defer db.followersMu.RUnlock()
+	if len(db.tiers) == 0 {
+		return 0
+	}
+	return db.tiers[0]
+}
+
+// selectPreferredFollower select follower that pass the filter from the lowest tier that has one
+// within the tier, follower in Options.PreferredAZ is selected first
+func (db *DB) selectPreferredFollower(filter func(f *followerDB) bool) *followerDB {
+	db.followersMu.RLock()
+	tiers := db.tiers
+	db.followersMu.RUnlock()
+	if len(tiers) == 0 {
+		tiers = []int{0}
+	}
+
+	for _, tier := range tiers {
+		tier := tier
+		if az := db.opts.PreferredAZ; az != "" {
+			if f := db.selectFollower(func(f *followerDB) bool { return f.tier == tier && f.az == az && filter(f) }); f != nil {
+				return f
+			}
+		}
+		if f := db.selectFollower(func(f *followerDB) bool { return f.tier == tier && filter(f) }); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+// selectFollower select follower using round-robin from followers that pass the filter
+// nil is returned if no follower pass the filter
+func (db *DB) selectFollower(filter func(f *followerDB) bool) *followerDB {
+	db.followersMu.RLock()
+	defer db.followersMu.RUnlock()
+
+	if filter == nil {
+		if len(db.followers) == 1 {
+			return db.followers[0]
+		}
+		n := atomic.AddUint64(&db.next, 1)
+		return db.followers[n%uint64(len(db.followers))]
+	}
+
+	candidates := make([]*followerDB, 0, len(db.followers))
+	for _, f := range db.followers {
+		if filter(f) {
+			candidates = append(candidates, f)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	n := atomic.AddUint64(&db.next, 1)
+	return candidates[n%uint64(len(candidates))]
+}
@@ -0,0 +1,201 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"sync"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestSetFollowers(t *testing.T) {
+	t.Parallel()
+
+	db, _, oldFollower := newFakeDB(t)
+	handler := func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}}
+	}
+	oldFollower.setHandler(handler)
+
+	newServer1, newFollower1 := newFakeServer(t, "follower1")
+	newServer2, newFollower2 := newFakeServer(t, "follower2")
+	newServer1.setHandler(handler)
+	newServer2.setHandler(handler)
+
+	var (
+		wg   sync.WaitGroup
+		stop = make(chan struct{})
+		errs = make(chan error, 100)
+	)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				var id int
+				if err := db.Get(&id, "SELECT id FROM users"); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+
+	if err := db.SetFollowers([]*sqlx.DB{newFollower1, newFollower2}); err != nil {
+		t.Fatal(err)
+	}
+	close(stop)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("unexpected error when reading: %v", err)
+	}
+
+	before1, before2 := len(newServer1.Queries()), len(newServer2.Queries())
+	for i := 0; i < 10; i++ {
+		var id int
+		if err := db.Get(&id, "SELECT id FROM users"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(newServer1.Queries()) == before1 || len(newServer2.Queries()) == before2 {
+		t.Error("expecting all new followers to receive traffic")
+	}
+	if len(db.Followers()) != 2 {
+		t.Errorf("expecting 2 followers, got %d", len(db.Followers()))
+	}
+}
+
+func TestSetFollowersValidation(t *testing.T) {
+	t.Parallel()
+
+	db, _, _ := newFakeDB(t)
+	if err := db.SetFollowers(nil); err != errFollowersEmpty {
+		t.Errorf("expecting error %v, got %v", errFollowersEmpty, err)
+	}
+
+	mismatch, err := sqlx.Open("postgres", "postgres://localhost/test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SetFollowers([]*sqlx.DB{mismatch}); err == nil {
+		t.Error("expecting error when follower driver is not matched")
+	}
+}
+
+func TestPreferredAZ(t *testing.T) {
+	t.Parallel()
+
+	db, _, _ := newFakeDB(t, WithPreferredAZ("az-a"))
+	var (
+		servers = make(map[string]*fakeServer)
+		named   []NamedFollower
+	)
+	for _, f := range []struct{ name, az string }{{"a-1", "az-a"}, {"b-1", "az-b"}, {"b-2", "az-b"}} {
+		server, follower := newFakeServer(t, f.name)
+		server.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+			return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}}
+		})
+		servers[f.name] = server
+		named = append(named, NamedFollower{Name: f.name, AZ: f.az, DB: follower})
+	}
+	if err := db.SetNamedFollowers(named); err != nil {
+		t.Fatal(err)
+	}
+
+	read := func() {
+		t.Helper()
+		for i := 0; i < 6; i++ {
+			var id int
+			if err := db.Get(&id, "SELECT id FROM users"); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	read()
+	if n := servers["a-1"].count("SELECT id FROM users"); n != 6 {
+		t.Errorf("expecting all reads to go to the same AZ follower, got %d", n)
+	}
+	if n := servers["b-1"].count("SELECT id FROM users") + servers["b-2"].count("SELECT id FROM users"); n != 0 {
+		t.Errorf("expecting no read to other AZ, got %d", n)
+	}
+
+	servers["a-1"].setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{err: driver.ErrBadConn}
+	})
+	db.checkFollowers(context.Background())
+	read()
+	if n := servers["a-1"].count("SELECT id FROM users"); n != 6 {
+		t.Errorf("expecting no read to the unhealthy follower, got %d more", n-6)
+	}
+	if servers["b-1"].count("SELECT id FROM users") == 0 || servers["b-2"].count("SELECT id FROM users") == 0 {
+		t.Error("expecting reads to fall back to all followers in other AZ")
+	}
+}
+
+// TestFollowerTiers is not run in parallel so the tier fallback counter is not changed by other tests
+func TestFollowerTiers(t *testing.T) {
+	db, _, _ := newFakeDB(t)
+	var (
+		servers = make(map[string]*fakeServer)
+		named   []NamedFollower
+	)
+	for _, f := range []struct {
+		name string
+		tier int
+	}{{"primary-1", 0}, {"primary-2", 0}, {"secondary-1", 1}} {
+		server, follower := newFakeServer(t, f.name)
+		server.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+			return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}}
+		})
+		servers[f.name] = server
+		named = append(named, NamedFollower{Name: f.name, Tier: f.tier, DB: follower})
+	}
+	if err := db.SetNamedFollowers(named); err != nil {
+		t.Fatal(err)
+	}
+
+	read := func(ctx context.Context) {
+		t.Helper()
+		for i := 0; i < 4; i++ {
+			var id int
+			if err := db.GetContext(ctx, &id, "SELECT id FROM users"); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	fallback := func() float64 {
+		return testutil.ToFloat64(_sqldbReadTierFallbackCount.WithLabelValues("1"))
+	}
+
+	before := fallback()
+	read(context.Background())
+	read(WithAffinityKey(context.Background(), "user-1"))
+	if n := servers["secondary-1"].count("SELECT id FROM users"); n != 0 {
+		t.Errorf("expecting no read to secondary tier while primary is available, got %d", n)
+	}
+	if got := fallback(); got != before {
+		t.Errorf("expecting no tier fallback, got %v", got-before)
+	}
+
+	down := func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{err: driver.ErrBadConn}
+	}
+	servers["primary-1"].setHandler(down)
+	servers["primary-2"].setHandler(down)
+	db.checkFollowers(context.Background())
+	read(context.Background())
+	if n := servers["secondary-1"].count("SELECT id FROM users"); n != 4 {
+		t.Errorf("expecting reads to fall back to secondary tier, got %d", n)
+	}
+	if got := fallback(); got != before+4 {
+		t.Errorf("expecting 4 tier fallback, got %v", got-before)
+	}
+}
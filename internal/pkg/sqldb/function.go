@@ -0,0 +1,38 @@
+package sqldb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// CallFunction call the set-returning function with args and scan the result rows into dest, for example:
+//
+//	var orders []Order
+//	db.CallFunction(ctx, &orders, "public.user_orders", userID, since)
+//
+// execute SELECT * FROM public.user_orders($1, $2). The function is called on follower,
+// function set by WithWriteFunctions is called on leader as write, in the transaction of WithTransaction or a unit of work
+func (db *DB) CallFunction(ctx context.Context, dest interface{}, funcName string, args ...interface{}) error {
+	if err := validateIdentifier(funcName); err != nil {
+		return err
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ")
+	query := db.Rebind(fmt.Sprintf("SELECT * FROM %s(%s)", db.QuoteIdentifier(funcName), placeholders))
+
+	if !db.opts.WriteFunctions[funcName] {
+		return db.SelectContext(ctx, dest, query, args...)
+	}
+	tx, ok, err := db.unitOfWorkTx(ctx, true)
+	if err != nil {
+		return err
+	}
+	return db.run(ctx, &queryInfo{query: query, args: args, target: targetLeader, write: true}, func(ctx context.Context) error {
+		if ok {
+			atomic.StoreInt32(&tx.wrote, 1)
+			return tx.SelectContext(ctx, dest, db.withTimeoutComment(ctx, query), args...)
+		}
+		return db.leader.SelectContext(ctx, dest, db.withTimeoutComment(ctx, query), args...)
+	})
+}
@@ -0,0 +1,51 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+)
+
+func TestCallFunction(t *testing.T) {
+	t.Parallel()
+
+	db, leader, follower := newFakeDB(t, WithWriteFunctions("archive_orders"))
+	db.driver = "postgres"
+
+	handler := func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		res := fakeResult{columns: []string{"id", "amount"}}
+		for i := int64(1); i <= 3; i++ {
+			res.rows = append(res.rows, []driver.Value{i, i * 100})
+		}
+		return res
+	}
+	leader.setHandler(handler)
+	follower.setHandler(handler)
+
+	type order struct {
+		ID     int64 `db:"id"`
+		Amount int64 `db:"amount"`
+	}
+	var orders []order
+	if err := db.CallFunction(context.Background(), &orders, "public.user_orders", 1, "2019-01-01"); err != nil {
+		t.Fatal(err)
+	}
+	if len(orders) != 3 || orders[2].ID != 3 || orders[2].Amount != 300 {
+		t.Errorf("unexpected orders %+v", orders)
+	}
+	if follower.count("SELECT * FROM public.user_orders($1, $2)") != 1 {
+		t.Errorf("expecting read function to be called on follower, got %v", follower.Queries())
+	}
+
+	orders = nil
+	if err := db.CallFunction(context.Background(), &orders, "archive_orders"); err != nil {
+		t.Fatal(err)
+	}
+	if leader.count("SELECT * FROM archive_orders()") != 1 {
+		t.Errorf("expecting write function to be called on leader, got %v", leader.Queries())
+	}
+
+	if err := db.CallFunction(context.Background(), &orders, "user_orders(1); DROP TABLE users; --"); err == nil {
+		t.Error("expecting error for invalid function name")
+	}
+}
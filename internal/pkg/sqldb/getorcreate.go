@@ -0,0 +1,28 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// GetOrCreate select the row into dest, and insert the row when it is not exist
+// when the insert is failed by unique violation because of concurrent insert, the row is selected again
+// all queries go to leader, so the row inserted by other process is always visible
+// created is true when the row is inserted by this call
+func (db *DB) GetOrCreate(ctx context.Context, dest interface{}, selectQuery string, selectArgs []interface{}, insertQuery string, insertArgs []interface{}) (created bool, err error) {
+	err = db.GetFromLeader(ctx, dest, selectQuery, selectArgs...)
+	if err != sql.ErrNoRows {
+		return false, err
+	}
+
+	_, err = db.ExecContext(ctx, insertQuery, insertArgs...)
+	if err != nil {
+		var ce *ConstraintError
+		if !errors.As(err, &ce) || ce.Kind != ConstraintUnique {
+			return false, err
+		}
+	}
+	created = err == nil
+	return created, db.GetFromLeader(ctx, dest, selectQuery, selectArgs...)
+}
@@ -0,0 +1,80 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"sync"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestGetOrCreate(t *testing.T) {
+	t.Parallel()
+
+	const (
+		selectQuery = "SELECT id FROM users WHERE email = ?"
+		insertQuery = "INSERT INTO users(email) VALUES(?)"
+	)
+
+	cases := []struct {
+		name string
+		// exist is true when the row is exist before the select
+		exist bool
+		// race is true when the row is inserted by other process between the select and insert
+		race          bool
+		expectCreated bool
+		expectInserts int
+	}{
+		{name: "found", exist: true, expectCreated: false, expectInserts: 0},
+		{name: "created", expectCreated: true, expectInserts: 1},
+		{name: "concurrent insert", race: true, expectCreated: false, expectInserts: 1},
+	}
+
+	for _, c := range cases {
+		db, leader, follower := newFakeDB(t)
+		var (
+			mu    sync.Mutex
+			exist = c.exist
+			race  = c.race
+		)
+		leader.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+			mu.Lock()
+			defer mu.Unlock()
+			switch query {
+			case selectQuery:
+				if !exist {
+					// the other process insert the row right after this select
+					exist = race
+					return fakeResult{columns: []string{"id"}}
+				}
+				return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(7)}}}
+			case insertQuery:
+				if exist {
+					return fakeResult{err: &pq.Error{Code: "23505", Constraint: "users_email_key"}}
+				}
+				exist = true
+				return fakeResult{rowsAffected: 1}
+			}
+			return fakeResult{}
+		})
+
+		var id int64
+		created, err := db.GetOrCreate(context.Background(), &id, selectQuery, []interface{}{"a@example.com"}, insertQuery, []interface{}{"a@example.com"})
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if created != c.expectCreated {
+			t.Errorf("%s: expecting created %v, got %v", c.name, c.expectCreated, created)
+		}
+		if id != 7 {
+			t.Errorf("%s: expecting id 7, got %d", c.name, id)
+		}
+		if n := leader.count(insertQuery); n != c.expectInserts {
+			t.Errorf("%s: expecting %d insert, got %d", c.name, c.expectInserts, n)
+		}
+		if len(follower.Queries()) != 0 {
+			t.Errorf("%s: expecting no query to follower, got %v", c.name, follower.Queries())
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package sqldb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/albertwidi/go-project-example/internal/pkg/log/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+var errReplicationLagNotSupported = errors.New("sqldb: replication lag is not supported for this driver")
+
+// list of query to check replication lag in seconds for each driver
+var replicationLagQueries = map[string]string{
+	"postgres": "SELECT COALESCE(EXTRACT(EPOCH FROM (now() - pg_last_xact_replay_timestamp())), 0)",
+}
+
+// ReplicationLag return the replication lag of a follower
+func (db *DB) ReplicationLag(ctx context.Context, follower *sqlx.DB) (time.Duration, error) {
+	query := db.opts.ReplicationLagQuery
+	if query == "" {
+		query = replicationLagQueries[db.driver]
+	}
+	if query == "" {
+		return 0, errReplicationLagNotSupported
+	}
+
+	var seconds float64
+	if err := follower.GetContext(ctx, &seconds, query); err != nil {
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// checkFollowers update the state of all followers
+func (db *DB) checkFollowers(ctx context.Context) {
+	for _, f := range db.followerStates() {
+		db.checkFollower(ctx, f)
+		observeReplicationLag(f)
+	}
+}
+
+// checkFollower update the availability and replication lag of the follower
+func (db *DB) checkFollower(ctx context.Context, f *followerDB) {
+	if err := f.db.PingContext(ctx); err != nil {
+		f.setAvailable(false)
+		f.setReplicationLag(-1)
+		if db.opts.Logger != nil {
+			db.opts.Logger.Warnw("sqldb: follower is down", logger.KV{"error": err.Error()})
+		}
+		return
+	}
+	f.setAvailable(true)
+
+	lag, err := db.ReplicationLag(ctx, f.db)
+	if err != nil {
+		// mark lag as unknown, so the follower is not used for bounded staleness read
+		f.setReplicationLag(-1)
+		if err != errReplicationLagNotSupported && db.opts.Logger != nil {
+			db.opts.Logger.Warnw("sqldb: failed to check replication lag", logger.KV{"error": err.Error()})
+		}
+		return
+	}
+	f.setReplicationLag(lag)
+}
+
+func (db *DB) healthCheckLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-db.done:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			db.checkFollowers(ctx)
+			if err := db.CheckSchemaVersion(ctx); err != nil && db.opts.Logger != nil {
+				db.opts.Logger.Warnw("sqldb: failed to check schema version", logger.KV{"error": err.Error()})
+			}
+			cancel()
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// defaultIdempotencyTable is the table of idempotency key used by ExecIdempotent
+const defaultIdempotencyTable = "sqldb_idempotency_keys"
+
+var errIdempotencyKeyEmpty = errors.New("sqldb: idempotency key cannot be empty")
+
+// idempotentResult is the recorded result of ExecIdempotent
+type idempotentResult struct {
+	lastInsertID int64
+	rowsAffected int64
+}
+
+// LastInsertId implement sql.Result
+func (r idempotentResult) LastInsertId() (int64, error) {
+	return r.lastInsertID, nil
+}
+
+// RowsAffected implement sql.Result
+func (r idempotentResult) RowsAffected() (int64, error) {
+	return r.rowsAffected, nil
+}
+
+// ExecIdempotent execute the write at most once for the idempotency key, for example the id of a retried request
+// the key and the result is recorded in Options.IdempotencyTable in the same transaction as the write,
+// when the key is already recorded the write is not executed and the recorded result is returned. The table must exist:
+//
+//	CREATE TABLE sqldb_idempotency_keys (
+//		idempotency_key VARCHAR(255) PRIMARY KEY,
+//		last_insert_id BIGINT NOT NULL,
+//		rows_affected BIGINT NOT NULL
+//	)
+//
+// the recorded last insert id is zero when the driver doesn't support LastInsertId, for example postgres
+func (db *DB) ExecIdempotent(ctx context.Context, key string, query string, args ...interface{}) (sql.Result, error) {
+	if key == "" {
+		return nil, errIdempotencyKeyEmpty
+	}
+	table := db.opts.IdempotencyTable
+	if table == "" {
+		table = defaultIdempotencyTable
+	}
+	if err := validateIdentifier(table); err != nil {
+		return nil, err
+	}
+	table = db.QuoteIdentifier(table)
+
+	var (
+		result    idempotentResult
+		duplicate bool
+	)
+	err := db.WithTransaction(ctx, nil, func(ctx context.Context, tx *Tx) error {
+		insertQuery := db.Rebind(fmt.Sprintf("INSERT INTO %s (idempotency_key, last_insert_id, rows_affected) VALUES (?, 0, 0)", table))
+		if _, err := tx.ExecContext(ctx, insertQuery, key); err != nil {
+			if ce, ok := db.ParseConstraintError(err); ok && ce.Kind == ConstraintUnique {
+				duplicate = true
+			}
+			return err
+		}
+
+		// the write go through ExecContext, so it is checked and observed like any other write in the transaction
+		res, err := db.ExecContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		if result.rowsAffected, err = res.RowsAffected(); err != nil {
+			return err
+		}
+		// postgres doesn't support LastInsertId
+		result.lastInsertID, _ = res.LastInsertId()
+
+		updateQuery := db.Rebind(fmt.Sprintf("UPDATE %s SET last_insert_id = ?, rows_affected = ? WHERE idempotency_key = ?", table))
+		_, err = tx.ExecContext(ctx, updateQuery, result.lastInsertID, result.rowsAffected, key)
+		return err
+	})
+	if duplicate {
+		selectQuery := db.Rebind(fmt.Sprintf("SELECT last_insert_id, rows_affected FROM %s WHERE idempotency_key = ?", table))
+		var recorded struct {
+			LastInsertID int64 `db:"last_insert_id"`
+			RowsAffected int64 `db:"rows_affected"`
+		}
+		if err := db.GetFromLeader(ctx, &recorded, selectQuery, key); err != nil {
+			return nil, err
+		}
+		return idempotentResult{lastInsertID: recorded.LastInsertID, rowsAffected: recorded.RowsAffected}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	markWritten(ctx)
+	return result, nil
+}
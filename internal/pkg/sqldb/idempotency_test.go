@@ -0,0 +1,89 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"sync"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestExecIdempotent(t *testing.T) {
+	t.Parallel()
+
+	db, leader, _ := newFakeDB(t)
+	db.driver = "postgres"
+
+	const write = "UPDATE accounts SET balance = balance - 10 WHERE id = $1"
+	var (
+		mu   sync.Mutex
+		keys = make(map[string]int64)
+	)
+	leader.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		mu.Lock()
+		defer mu.Unlock()
+		switch query {
+		case "INSERT INTO sqldb_idempotency_keys (idempotency_key, last_insert_id, rows_affected) VALUES ($1, 0, 0)":
+			key := args[0].Value.(string)
+			if _, ok := keys[key]; ok {
+				return fakeResult{err: &pq.Error{Code: "23505", Constraint: "sqldb_idempotency_keys_pkey"}}
+			}
+			keys[key] = 0
+			return fakeResult{rowsAffected: 1}
+		case "UPDATE sqldb_idempotency_keys SET last_insert_id = $1, rows_affected = $2 WHERE idempotency_key = $3":
+			keys[args[2].Value.(string)] = args[1].Value.(int64)
+			return fakeResult{rowsAffected: 1}
+		case "SELECT last_insert_id, rows_affected FROM sqldb_idempotency_keys WHERE idempotency_key = $1":
+			return fakeResult{columns: []string{"last_insert_id", "rows_affected"}, rows: [][]driver.Value{{int64(0), keys[args[0].Value.(string)]}}}
+		case write:
+			return fakeResult{rowsAffected: 1}
+		}
+		return fakeResult{}
+	})
+
+	for i := 0; i < 2; i++ {
+		result, err := db.ExecIdempotent(context.Background(), "request-1", write, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if affected, _ := result.RowsAffected(); affected != 1 {
+			t.Errorf("attempt %d: expecting 1 row affected, got %d", i, affected)
+		}
+	}
+	if n := leader.count(write); n != 1 {
+		t.Errorf("expecting the write to be executed once, got %d", n)
+	}
+	if n := leader.count("ROLLBACK"); n != 1 {
+		t.Errorf("expecting the duplicate attempt to be rolled back, got %d", n)
+	}
+
+	if _, err := db.ExecIdempotent(context.Background(), "request-2", write, 1); err != nil {
+		t.Fatal(err)
+	}
+	if n := leader.count(write); n != 2 {
+		t.Errorf("expecting the write with new key to be executed, got %d", n)
+	}
+	if _, err := db.ExecIdempotent(context.Background(), "", write, 1); err != errIdempotencyKeyEmpty {
+		t.Errorf("expecting errIdempotencyKeyEmpty, got %v", err)
+	}
+}
+
+func TestExecIdempotentRejectUnfilteredMutation(t *testing.T) {
+	t.Parallel()
+
+	db, leader, _ := newFakeDB(t, WithRejectUnfilteredMutations())
+	leader.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{rowsAffected: 1}
+	})
+
+	if _, err := db.ExecIdempotent(context.Background(), "request-1", "DELETE FROM accounts"); err != errUnfilteredMutation {
+		t.Errorf("expecting errUnfilteredMutation, got %v", err)
+	}
+	if n := leader.count("DELETE FROM accounts"); n != 0 {
+		t.Errorf("expecting the unfiltered delete to not be executed, got %d", n)
+	}
+	if n := leader.count("ROLLBACK"); n != 1 {
+		t.Errorf("expecting the idempotency key to be rolled back, got %d", n)
+	}
+}
@@ -0,0 +1,53 @@
+package sqldb
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// identifierRegex match a plain identifier, optionally qualified by schema. For example: users or public.users
+var identifierRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// validateIdentifier to make sure table or column name is safe to be put into a query
+func validateIdentifier(names ...string) error {
+	for _, name := range names {
+		if !identifierRegex.MatchString(name) {
+			return fmt.Errorf("sqldb: invalid identifier %q", name)
+		}
+	}
+	return nil
+}
+
+// IdentifierPolicy decide how identifier is written in SQL generated by sqldb
+type IdentifierPolicy int
+
+// list of identifier policy
+const (
+	// IdentifierAsIs write identifier as it is without quote
+	IdentifierAsIs IdentifierPolicy = iota
+	// IdentifierQuoted quote identifier to preserve the case, for mixed-case schema
+	IdentifierQuoted
+	// IdentifierLower write identifier in lowercase without quote, same with how postgres fold unquoted identifier
+	IdentifierLower
+)
+
+// QuoteIdentifier return the identifier based on the IdentifierPolicy
+// schema qualified identifier is quoted per part, for example "public"."Users"
+func (db *DB) QuoteIdentifier(name string) string {
+	switch db.opts.IdentifierPolicy {
+	case IdentifierQuoted:
+		quote := `"`
+		if db.driver == "mysql" {
+			quote = "`"
+		}
+		parts := strings.Split(name, ".")
+		for i, part := range parts {
+			parts[i] = quote + strings.Replace(part, quote, quote+quote, -1) + quote
+		}
+		return strings.Join(parts, ".")
+	case IdentifierLower:
+		return strings.ToLower(name)
+	}
+	return name
+}
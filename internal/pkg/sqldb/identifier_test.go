@@ -0,0 +1,34 @@
+package sqldb
+
+import "testing"
+
+func TestQuoteIdentifier(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		driver string
+		policy IdentifierPolicy
+		name   string
+		expect string
+	}{
+		{driver: "postgres", policy: IdentifierAsIs, name: "createdAt", expect: "createdAt"},
+		{driver: "postgres", policy: IdentifierQuoted, name: "createdAt", expect: `"createdAt"`},
+		{driver: "postgres", policy: IdentifierQuoted, name: "public.Users", expect: `"public"."Users"`},
+		{driver: "mysql", policy: IdentifierQuoted, name: "createdAt", expect: "`createdAt`"},
+		{driver: "postgres", policy: IdentifierLower, name: "createdAt", expect: "createdat"},
+	}
+
+	for _, c := range cases {
+		db := &DB{driver: c.driver, opts: Options{IdentifierPolicy: c.policy}}
+		if got := db.QuoteIdentifier(c.name); got != c.expect {
+			t.Errorf("%s %d %s: expecting %s, got %s", c.driver, c.policy, c.name, c.expect, got)
+		}
+	}
+
+	if err := validateIdentifier("users", "public.users", "created_at"); err != nil {
+		t.Error(err)
+	}
+	if err := validateIdentifier("users; DROP TABLE users"); err == nil {
+		t.Error("expecting error for invalid identifier")
+	}
+}
@@ -0,0 +1,130 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// defaultMaxQueryParams is the maximum number of parameters in a query for postgres and mysql
+const defaultMaxQueryParams = 65535
+
+var errInMultipleSlice = errors.New("sqldb: cannot split IN query with more than one slice argument")
+
+func (db *DB) maxQueryParams() int {
+	if db.opts.MaxQueryParams > 0 {
+		return db.opts.MaxQueryParams
+	}
+	return defaultMaxQueryParams
+}
+
+// SelectIn select into dest using query with IN (?) and slice argument, the slice argument is expanded using sqlx.In
+// when the number of parameters exceed the limit, the slice argument is split into chunks
+// each chunk is queried separately and the result is appended to dest in the order of chunk
+// duplicate value in the slice argument is removed, so the same row is not returned twice from different chunk
+// dest is always reset before the query, the existing elements is not kept
+func (db *DB) SelectIn(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Ptr || destValue.IsNil() || destValue.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("sqldb: SelectIn destination must be a pointer to a slice, got %T", dest)
+	}
+
+	sliceIndex := -1
+	paramCount := 0
+	for i, arg := range args {
+		if n, ok := inSliceLen(arg); ok {
+			if sliceIndex >= 0 {
+				sliceIndex = -2
+			} else if sliceIndex == -1 {
+				sliceIndex = i
+			}
+			paramCount += n
+			continue
+		}
+		paramCount++
+	}
+
+	result := destValue.Elem()
+	result.Set(result.Slice(0, 0))
+
+	limit := db.maxQueryParams()
+	if paramCount <= limit {
+		return db.selectIn(ctx, dest, query, args...)
+	}
+	if sliceIndex == -2 {
+		return errInMultipleSlice
+	}
+
+	values, err := uniqueValues(reflect.ValueOf(args[sliceIndex]))
+	if err != nil {
+		return err
+	}
+	chunkSize := limit - (len(args) - 1)
+	if chunkSize <= 0 {
+		return fmt.Errorf("sqldb: too many parameters for IN query, limit is %d", limit)
+	}
+
+	for start := 0; start < len(values); start += chunkSize {
+		end := start + chunkSize
+		if end > len(values) {
+			end = len(values)
+		}
+
+		chunkArgs := make([]interface{}, len(args))
+		copy(chunkArgs, args)
+		chunkArgs[sliceIndex] = values[start:end]
+
+		chunkDest := reflect.New(result.Type())
+		if err := db.selectIn(ctx, chunkDest.Interface(), query, chunkArgs...); err != nil {
+			return err
+		}
+		result.Set(reflect.AppendSlice(result, chunkDest.Elem()))
+	}
+	return nil
+}
+
+func (db *DB) selectIn(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	inQuery, inArgs, err := sqlx.In(query, args...)
+	if err != nil {
+		return err
+	}
+	return db.SelectContext(ctx, dest, db.Rebind(inQuery), inArgs...)
+}
+
+// inSliceLen return the length of argument if the argument is expanded by sqlx.In
+func inSliceLen(arg interface{}) (int, bool) {
+	if _, ok := arg.(driver.Valuer); ok {
+		return 0, false
+	}
+	v := reflect.ValueOf(arg)
+	if v.Kind() != reflect.Slice || v.Type().Elem().Kind() == reflect.Uint8 {
+		return 0, false
+	}
+	return v.Len(), true
+}
+
+// uniqueValues return the unique values of a slice, the order is preserved
+// error is returned when the element type is comparable but a value is not, for example []byte in []interface{}
+func uniqueValues(slice reflect.Value) ([]interface{}, error) {
+	values := make([]interface{}, 0, slice.Len())
+	comparable := slice.Type().Elem().Comparable()
+	seen := make(map[interface{}]struct{}, slice.Len())
+	for i := 0; i < slice.Len(); i++ {
+		v := slice.Index(i).Interface()
+		if comparable {
+			if !hashable(slice.Index(i)) {
+				return nil, fmt.Errorf("sqldb: SelectIn value %d is not comparable, got %T", i, v)
+			}
+			if _, ok := seen[v]; ok {
+				continue
+			}
+			seen[v] = struct{}{}
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
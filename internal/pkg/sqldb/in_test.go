@@ -0,0 +1,72 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+)
+
+func TestSelectInChunks(t *testing.T) {
+	t.Parallel()
+
+	db, _, follower := newFakeDB(t, WithMaxQueryParams(4))
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		// the first argument is status, the rest is the ids
+		res := fakeResult{columns: []string{"id"}}
+		for _, arg := range args[1:] {
+			res.rows = append(res.rows, []driver.Value{arg.Value})
+		}
+		return res
+	})
+
+	var ids []int64
+	err := db.SelectIn(context.Background(), &ids, "SELECT id FROM users WHERE status = ? AND id IN (?)", "active", []int64{1, 2, 3, 4, 5, 3, 6, 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := []int64{1, 2, 3, 4, 5, 6, 7}; !reflect.DeepEqual(ids, expect) {
+		t.Errorf("expecting %v, got %v", expect, ids)
+	}
+	if n := len(follower.Queries()); n != 3 {
+		t.Errorf("expecting 3 chunked queries, got %d", n)
+	}
+
+	// under the limit is executed in one query
+	ids = nil
+	if err := db.SelectIn(context.Background(), &ids, "SELECT id FROM users WHERE id IN (?)", []int64{1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(follower.Queries()); n != 4 {
+		t.Errorf("expecting 1 more query, got %d", n-3)
+	}
+
+	// dest is reset both under and over the limit
+	for _, values := range [][]int64{{1, 2}, {1, 2, 3, 4, 5}} {
+		ids = []int64{99}
+		if err := db.SelectIn(context.Background(), &ids, "SELECT id FROM users WHERE status = ? AND id IN (?)", "active", values); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(ids, values) {
+			t.Errorf("expecting dest to be reset to %v, got %v", values, ids)
+		}
+	}
+}
+
+func TestSelectInNotComparable(t *testing.T) {
+	t.Parallel()
+
+	db, _, follower := newFakeDB(t, WithMaxQueryParams(2))
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{columns: []string{"id"}}
+	})
+
+	var ids []int64
+	values := []interface{}{[]byte("a"), []byte("b"), []byte("c")}
+	if err := db.SelectIn(context.Background(), &ids, "SELECT id FROM users WHERE name IN (?)", values); err == nil {
+		t.Error("expecting error for non-comparable value")
+	}
+	if n := len(follower.Queries()); n != 0 {
+		t.Errorf("expecting no query, got %d", n)
+	}
+}
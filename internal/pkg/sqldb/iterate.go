@@ -0,0 +1,57 @@
+package sqldb
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Iterate run the query on a dedicated follower connection and call fn for each row, for example to export a large table
+// fn scan the current row using rows.Scan or rows.StructScan, and the iteration is stopped when fn return error.
+// The connection is checked out of the follower pool until the iteration is finished, so a long cursor is never recycled
+// by ConnMaxLifetime mid-fetch: database/sql only check the lifetime when the connection is returned to the pool,
+// and a connection past ConnMaxLifetime is then closed by the pool instead of reused.
+// Inside WithTransaction or a began unit of work, the query run in the transaction. The iteration is stopped with
+// ErrScanTimeout when it is not finished within Options.ScanTimeout
+func (db *DB) Iterate(ctx context.Context, fn func(rows *sqlx.Rows) error, query string, args ...interface{}) error {
+	tx, ok, err := db.txReader(ctx)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return db.run(ctx, &queryInfo{query: query, args: args, target: targetLeader}, func(ctx context.Context) error {
+			return queryScan(ctx, db.opts.ScanTimeout, func(ctx context.Context) (*sqlx.Rows, error) {
+				return tx.QueryxContext(ctx, db.withTimeoutComment(ctx, query), args...)
+			}, iterateRows(fn))
+		})
+	}
+
+	reader, target := db.queryReader(ctx, query)
+	return db.run(ctx, &queryInfo{query: query, args: args, target: target, handle: reader}, func(ctx context.Context) error {
+		conn, err := reader.Conn(ctx)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		return queryScan(ctx, db.opts.ScanTimeout, func(ctx context.Context) (*sqlx.Rows, error) {
+			sqlRows, err := conn.QueryContext(ctx, db.withTimeoutComment(ctx, query), args...)
+			if err != nil {
+				return nil, err
+			}
+			return &sqlx.Rows{Rows: sqlRows, Mapper: reader.Mapper}, nil
+		}, iterateRows(fn))
+	})
+}
+
+// iterateRows return the scan that call fn for each row
+func iterateRows(fn func(rows *sqlx.Rows) error) func(rows *sqlx.Rows) error {
+	return func(rows *sqlx.Rows) error {
+		for rows.Next() {
+			if err := fn(rows); err != nil {
+				return err
+			}
+		}
+		return rows.Err()
+	}
+}
@@ -0,0 +1,97 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func TestIterate(t *testing.T) {
+	t.Parallel()
+
+	const query = "SELECT id FROM orders"
+	db, _, follower := newFakeDB(t)
+	db.Follower().SetConnMaxLifetime(time.Millisecond * 30)
+
+	var (
+		mu      sync.Mutex
+		connIDs []int64
+	)
+	follower.setHandler(func(ctx context.Context, q string, args []driver.NamedValue) fakeResult {
+		mu.Lock()
+		connIDs = append(connIDs, fakeConnID(ctx))
+		mu.Unlock()
+		res := fakeResult{columns: []string{"id"}}
+		for i := int64(1); i <= 10; i++ {
+			res.rows = append(res.rows, []driver.Value{i})
+		}
+		// every row of the cursor carry the id of the connection running it
+		if q == query {
+			res.columns = append(res.columns, "conn_id")
+			for i := range res.rows {
+				res.rows[i] = append(res.rows[i], fakeConnID(ctx))
+			}
+			res.rowDelay = time.Millisecond * 10
+		}
+		return res
+	})
+
+	// the cursor take 100ms, longer than the connection max lifetime
+	var (
+		ids      []int64
+		rowConns = make(map[int64]bool)
+		start    = time.Now()
+		finished time.Duration
+	)
+	err := db.Iterate(context.Background(), func(rows *sqlx.Rows) error {
+		var id, connID int64
+		if err := rows.Scan(&id, &connID); err != nil {
+			return err
+		}
+		ids = append(ids, id)
+		rowConns[connID] = true
+		finished = time.Since(start)
+		return nil
+	}, query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 10 || ids[9] != 10 {
+		t.Errorf("expecting all 10 rows, got %v", ids)
+	}
+	mu.Lock()
+	if len(rowConns) != 1 || !rowConns[connIDs[0]] {
+		t.Errorf("expecting every row to be fetched on the cursor connection %d, got %v", connIDs[0], rowConns)
+	}
+	mu.Unlock()
+	if finished <= time.Millisecond*30 {
+		t.Errorf("expecting the fetch to finish after the connection max lifetime, finished after %s", finished)
+	}
+
+	// the expired connection is not reused after the cursor is finished
+	var id int64
+	if err := db.GetContext(context.Background(), &id, "SELECT id FROM orders LIMIT 1"); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	if len(connIDs) != 2 || connIDs[0] == connIDs[1] {
+		t.Errorf("expecting the expired cursor connection to be closed, got connections %v", connIDs)
+	}
+	mu.Unlock()
+
+	// error of fn stop the iteration
+	errStop := errors.New("stop")
+	n := 0
+	err = db.Iterate(context.Background(), func(rows *sqlx.Rows) error {
+		n++
+		return errStop
+	}, "SELECT id FROM orders LIMIT 10")
+	if err != errStop || n != 1 {
+		t.Errorf("expecting iteration to stop at the first error, got %v after %d rows", err, n)
+	}
+}
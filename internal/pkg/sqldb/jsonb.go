@@ -0,0 +1,59 @@
+package sqldb
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// errorValue is a query argument that fail the query with err
+// this is used by clause helper that doesn't return error
+type errorValue struct {
+	err error
+}
+
+// Value implement driver.Valuer
+func (ev errorValue) Value() (driver.Value, error) {
+	return nil, ev.err
+}
+
+// jsonbValue marshal the value to JSON when the query is executed
+type jsonbValue struct {
+	value interface{}
+}
+
+// Value implement driver.Valuer
+func (jv jsonbValue) Value() (driver.Value, error) {
+	b, err := json.Marshal(jv.value)
+	if err != nil {
+		return nil, fmt.Errorf("sqldb: failed to marshal jsonb value: %w", err)
+	}
+	return string(b), nil
+}
+
+// JSONBContains return postgres clause to check whether the jsonb column contains value, for example:
+//
+//	clause, arg := sqldb.JSONBContains("attributes", map[string]interface{}{"color": "red"})
+//	db.Select(&products, db.Rebind("SELECT * FROM products WHERE "+clause), arg)
+//
+// the value is marshaled to JSON when the query is executed
+// the query return error if the value cannot be marshaled or the column is not a valid identifier
+func JSONBContains(column string, value interface{}) (clause string, arg interface{}) {
+	if err := validateIdentifier(column); err != nil {
+		return "?", errorValue{err: err}
+	}
+	return column + " @> ?::jsonb", jsonbValue{value: value}
+}
+
+// JSONBPath return postgres expression to extract the text at path of the jsonb column, for example:
+//
+//	expr, arg := sqldb.JSONBPath("attributes", "dimension", "width")
+//	db.Select(&products, db.Rebind("SELECT * FROM products WHERE "+expr+" = ?"), arg, "10")
+func JSONBPath(column string, path ...string) (expr string, arg interface{}) {
+	if err := validateIdentifier(column); err != nil {
+		return "?", errorValue{err: err}
+	}
+	return fmt.Sprintf("(%s #>> ?::text[])", column), pq.Array(path)
+}
@@ -0,0 +1,84 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestJSONBContains(t *testing.T) {
+	t.Parallel()
+
+	db, _, follower := newFakeDB(t)
+	db.driver = "postgres"
+
+	products := []struct {
+		id         int64
+		attributes string
+	}{
+		{id: 1, attributes: `{"color": "red", "size": "L"}`},
+		{id: 2, attributes: `{"color": "blue", "size": "L"}`},
+		{id: 3, attributes: `{"color": "red", "size": "S"}`},
+	}
+	// the fake server evaluate @> on the top-level keys
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		if query != "SELECT id FROM products WHERE attributes @> $1::jsonb" {
+			t.Errorf("unexpected query %s", query)
+			return fakeResult{}
+		}
+		var filter map[string]interface{}
+		if err := json.Unmarshal([]byte(args[0].Value.(string)), &filter); err != nil {
+			return fakeResult{err: err}
+		}
+		res := fakeResult{columns: []string{"id"}}
+		for _, p := range products {
+			var attributes map[string]interface{}
+			json.Unmarshal([]byte(p.attributes), &attributes)
+			match := true
+			for k, v := range filter {
+				if attributes[k] != v {
+					match = false
+				}
+			}
+			if match {
+				res.rows = append(res.rows, []driver.Value{p.id})
+			}
+		}
+		return res
+	})
+
+	clause, arg := JSONBContains("attributes", map[string]interface{}{"color": "red"})
+	var ids []int64
+	if err := db.SelectContext(context.Background(), &ids, db.Rebind("SELECT id FROM products WHERE "+clause), arg); err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 3 {
+		t.Errorf("expecting [1 3], got %v", ids)
+	}
+
+	clause, arg = JSONBContains("attributes; DROP TABLE products", map[string]interface{}{"color": "red"})
+	if strings.Contains(clause, "DROP") {
+		t.Errorf("expecting invalid column to not be written to the clause, got %s", clause)
+	}
+	if _, err := arg.(driver.Valuer).Value(); err == nil {
+		t.Error("expecting invalid column to return error")
+	}
+}
+
+func TestJSONBPath(t *testing.T) {
+	t.Parallel()
+
+	expr, arg := JSONBPath("attributes", "dimension", "width")
+	if expr != "(attributes #>> ?::text[])" {
+		t.Errorf("unexpected expression %s", expr)
+	}
+	v, err := arg.(driver.Valuer).Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != `{"dimension","width"}` {
+		t.Errorf(`expecting {"dimension","width"}, got %v`, v)
+	}
+}
@@ -0,0 +1,116 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/albertwidi/go-project-example/internal/pkg/log/logger"
+)
+
+// defaultLayeredCacheSize is the default maximum number of entry in the LayeredGet cache
+const defaultLayeredCacheSize = 1000
+
+type layeredCacheEntry struct {
+	value   reflect.Value
+	expires time.Time
+}
+
+// layeredCache is the in-process cache of LayeredGet
+type layeredCache struct {
+	mu      sync.Mutex
+	entries map[string]layeredCacheEntry
+	// now is used to get the current time, replaced in tests
+	now func() time.Time
+}
+
+func (c *layeredCache) timeNow() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+// get the cached value of key into dest, return false when not found, expired or the value has different type
+func (c *layeredCache) get(key string, dest reflect.Value) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return false
+	}
+	if !c.timeNow().Before(entry.expires) {
+		delete(c.entries, key)
+		return false
+	}
+	if entry.value.Type() != dest.Type() {
+		return false
+	}
+	dest.Set(entry.value)
+	return true
+}
+
+// set the value of key, expired entries is removed when the cache is full, then an arbitrary entry
+func (c *layeredCache) set(key string, value reflect.Value, ttl time.Duration, size int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]layeredCacheEntry)
+	}
+	now := c.timeNow()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= size {
+		for k, entry := range c.entries {
+			if !now.Before(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+		for k := range c.entries {
+			if len(c.entries) < size {
+				break
+			}
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = layeredCacheEntry{value: value, expires: now.Add(ttl)}
+}
+
+// LayeredGet get a row from the in-process cache by cacheKey, then from follower, then from leader when the follower read is failed
+// the row is cached for ttl after it is read. This is meant for reference data that rarely change, the cached value is a shallow
+// copy of dest, so slice and map in dest is shared between reads and must not be modified. sql.ErrNoRows is not cached, and is not
+// a follower failure. The cache is not used inside a transaction, as the row might not be committed
+func (db *DB) LayeredGet(ctx context.Context, cacheKey string, ttl time.Duration, dest interface{}, query string, args ...interface{}) error {
+	if err := validateGetDest(dest); err != nil {
+		return err
+	}
+	if _, ok, _ := db.unitOfWorkTx(ctx, false); ok {
+		return db.GetContext(ctx, dest, query, args...)
+	}
+
+	value := reflect.ValueOf(dest).Elem()
+	if db.layered.get(cacheKey, value) {
+		return nil
+	}
+
+	err := db.GetContext(ctx, dest, query, args...)
+	if err != nil && err != sql.ErrNoRows && ctx.Err() == nil && !errors.Is(err, ErrFollowerReadInTx) {
+		if db.opts.Logger != nil {
+			db.opts.Logger.Warnw("sqldb: layered read from follower failed, read from leader", logger.KV{"error": err.Error()})
+		}
+		err = db.GetFromLeader(ctx, dest, query, args...)
+	}
+	if err != nil {
+		return err
+	}
+
+	size := db.opts.LayeredCacheSize
+	if size <= 0 {
+		size = defaultLayeredCacheSize
+	}
+	copied := reflect.New(value.Type()).Elem()
+	copied.Set(value)
+	db.layered.set(cacheKey, copied, ttl, size)
+	return nil
+}
@@ -0,0 +1,108 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLayeredGet(t *testing.T) {
+	t.Parallel()
+
+	const query = "SELECT code, name FROM currencies WHERE code = ?"
+	db, leader, follower := newFakeDB(t)
+	clock := &fakeClock{now: time.Now()}
+	db.layered.now = clock.Now
+
+	var (
+		mu           sync.Mutex
+		followerDown bool
+	)
+	handler := func(name string) fakeHandler {
+		return func(ctx context.Context, q string, args []driver.NamedValue) fakeResult {
+			if args[0].Value == "XXX" {
+				return fakeResult{columns: []string{"code", "name"}}
+			}
+			return fakeResult{columns: []string{"code", "name"}, rows: [][]driver.Value{{args[0].Value, name}}}
+		}
+	}
+	leader.setHandler(handler("leader"))
+	follower.setHandler(func(ctx context.Context, q string, args []driver.NamedValue) fakeResult {
+		mu.Lock()
+		defer mu.Unlock()
+		if followerDown {
+			return fakeResult{err: errors.New("sqldb: fake: follower is down")}
+		}
+		return handler("follower")(ctx, q, args)
+	})
+
+	type currency struct {
+		Code string `db:"code"`
+		Name string `db:"name"`
+	}
+	get := func(code string) (currency, error) {
+		var c currency
+		err := db.LayeredGet(context.Background(), "currency:"+code, time.Minute, &c, query, code)
+		return c, err
+	}
+
+	// cache miss read from follower
+	c, err := get("IDR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Name != "follower" || follower.count(query) != 1 {
+		t.Errorf("expecting cache miss to read from follower, got %+v", c)
+	}
+
+	// cache hit doesn't query the database
+	c, err = get("IDR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Code != "IDR" || c.Name != "follower" {
+		t.Errorf("expecting cached row, got %+v", c)
+	}
+	if n := follower.count(query) + leader.count(query); n != 1 {
+		t.Errorf("expecting cache hit to skip the database, got %d queries", n)
+	}
+
+	// follower failure fall back to leader
+	mu.Lock()
+	followerDown = true
+	mu.Unlock()
+	c, err = get("USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Name != "leader" || leader.count(query) != 1 {
+		t.Errorf("expecting follower failure to read from leader, got %+v", c)
+	}
+
+	// not found is not a follower failure and is not cached
+	mu.Lock()
+	followerDown = false
+	mu.Unlock()
+	for i := 0; i < 2; i++ {
+		if _, err := get("XXX"); err != sql.ErrNoRows {
+			t.Errorf("expecting sql.ErrNoRows, got %v", err)
+		}
+	}
+	if leader.count(query) != 1 {
+		t.Error("expecting not found not to read from leader")
+	}
+
+	// expired row is read again
+	clock.Add(time.Minute)
+	before := follower.count(query)
+	if _, err := get("IDR"); err != nil {
+		t.Fatal(err)
+	}
+	if follower.count(query)-before != 1 {
+		t.Error("expecting expired row to be read again")
+	}
+}
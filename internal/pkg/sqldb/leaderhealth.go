@@ -0,0 +1,89 @@
+package sqldb
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/albertwidi/go-project-example/internal/pkg/log/logger"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+// defaultLeaderWriteFailureThreshold is the default number of consecutive failed write before the leader is unhealthy
+const defaultLeaderWriteFailureThreshold = 3
+
+// leaderHealth track the consecutive write to leader that is failed because the leader cannot accept writes
+type leaderHealth struct {
+	mu        sync.Mutex
+	failures  int
+	unhealthy bool
+}
+
+// LeaderHealthy return false when the last consecutive writes to leader is failed because the leader is unwritable,
+// for example the disk is full or the leader is in read-only mode. This can be used by readiness probe to signal failover
+// the leader is healthy again on the next successful write
+func (db *DB) LeaderHealthy() bool {
+	db.leaderHealth.mu.Lock()
+	defer db.leaderHealth.mu.Unlock()
+	return !db.leaderHealth.unhealthy
+}
+
+// recordLeaderWrite update the leader health with the result of a write to leader
+// error caused by the query itself, for example constraint violation, doesn't change the health
+func (db *DB) recordLeaderWrite(err error) {
+	if err != nil && !leaderUnwritable(err) {
+		return
+	}
+	threshold := db.opts.LeaderWriteFailureThreshold
+	if threshold <= 0 {
+		threshold = defaultLeaderWriteFailureThreshold
+	}
+
+	h := &db.leaderHealth
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err == nil {
+		if h.unhealthy && db.opts.Logger != nil {
+			db.opts.Logger.Infow("sqldb: leader is healthy", logger.KV{})
+		}
+		h.failures, h.unhealthy = 0, false
+		return
+	}
+	h.failures++
+	if h.unhealthy || h.failures < threshold {
+		return
+	}
+	h.unhealthy = true
+	_sqldbLeaderUnhealthyCount.Inc()
+	if db.opts.Logger != nil {
+		db.opts.Logger.Errorw("sqldb: leader is unhealthy", logger.KV{"failures": h.failures, "error": err.Error()})
+	}
+}
+
+// leaderUnwritable return true if err is caused by the leader that cannot accept writes
+func leaderUnwritable(err error) bool {
+	var (
+		pqErr    *pq.Error
+		mysqlErr *mysql.MySQLError
+	)
+	switch {
+	case errors.Is(err, driver.ErrBadConn) || errors.Is(err, mysql.ErrInvalidConn):
+		return true
+	case errors.As(err, &pqErr):
+		// 25006 is read_only_sql_transaction, class 08 is connection exception, 53 is insufficient resources like disk_full,
+		// 57P01, 57P02 and 57P03 is admin_shutdown, crash_shutdown and cannot_connect_now, and 58 is system error like io_error.
+		// Other operator intervention like 57014 query_canceled is caused by the query, for example statement_timeout
+		code := string(pqErr.Code)
+		return code == "25006" || strings.HasPrefix(code, "08") || strings.HasPrefix(code, "53") ||
+			code == "57P01" || code == "57P02" || code == "57P03" || strings.HasPrefix(code, "58")
+	case errors.As(err, &mysqlErr):
+		// 1290 and 1836 is read-only mode, 1792 is read-only transaction, 1021 is disk full, 1053 is server shutdown
+		switch mysqlErr.Number {
+		case 1290, 1836, 1792, 1021, 1053:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"sync"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestLeaderHealthy(t *testing.T) {
+	t.Parallel()
+
+	const query = "UPDATE users SET name = 'name' WHERE id = 1"
+	l := &fakeLogger{}
+	db, leader, _ := newFakeDB(t, WithLogger(l))
+
+	var (
+		mu  sync.Mutex
+		err error
+	)
+	leader.setHandler(func(ctx context.Context, q string, args []driver.NamedValue) fakeResult {
+		mu.Lock()
+		defer mu.Unlock()
+		return fakeResult{rowsAffected: 1, err: err}
+	})
+	setErr := func(e error) {
+		mu.Lock()
+		err = e
+		mu.Unlock()
+	}
+	exec := func() {
+		db.ExecContext(context.Background(), query)
+	}
+
+	if !db.LeaderHealthy() {
+		t.Fatal("expecting leader to be healthy before any write")
+	}
+	before := testutil.ToFloat64(_sqldbLeaderUnhealthyCount)
+
+	// error caused by the query doesn't change the health
+	setErr(&pq.Error{Code: "23505"})
+	for i := 0; i < 5; i++ {
+		exec()
+	}
+	if !db.LeaderHealthy() {
+		t.Error("expecting constraint violation not to mark leader unhealthy")
+	}
+	// canceled query by statement_timeout or CancelBackend
+	setErr(&pq.Error{Code: "57014", Message: "canceling statement due to statement timeout"})
+	for i := 0; i < 5; i++ {
+		exec()
+	}
+	if !db.LeaderHealthy() {
+		t.Error("expecting canceled query not to mark leader unhealthy")
+	}
+
+	setErr(&pq.Error{Code: "25006", Message: "cannot execute UPDATE in a read-only transaction"})
+	exec()
+	exec()
+	if !db.LeaderHealthy() {
+		t.Error("expecting leader to be healthy before the threshold")
+	}
+	exec()
+	if db.LeaderHealthy() {
+		t.Error("expecting leader to be unhealthy after 3 consecutive write failures")
+	}
+	if !l.contains("sqldb: leader is unhealthy") {
+		t.Error("expecting unhealthy leader to be logged")
+	}
+	exec()
+	if n := testutil.ToFloat64(_sqldbLeaderUnhealthyCount) - before; n != 1 {
+		t.Errorf("expecting unhealthy leader to be counted once, got %v", n)
+	}
+
+	setErr(nil)
+	exec()
+	if !db.LeaderHealthy() {
+		t.Error("expecting leader to be healthy after a successful write")
+	}
+	if !l.contains("sqldb: leader is healthy") {
+		t.Error("expecting recovered leader to be logged")
+	}
+
+	// a success reset the consecutive failures
+	setErr(driver.ErrBadConn)
+	exec()
+	exec()
+	setErr(nil)
+	exec()
+	setErr(&pq.Error{Code: "53100", Message: "could not extend file: No space left on device"})
+	exec()
+	setErr(driver.ErrBadConn)
+	exec()
+	if !db.LeaderHealthy() {
+		t.Error("expecting failures before a successful write not to be counted")
+	}
+}
+
+func TestLeaderUnwritable(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]bool{
+		"57P01": true,
+		"57P02": true,
+		"57P03": true,
+		"57014": false,
+		"57000": false,
+		"58030": true,
+		"23505": false,
+	}
+	for code, expect := range cases {
+		if got := leaderUnwritable(&pq.Error{Code: pq.ErrorCode(code)}); got != expect {
+			t.Errorf("%s: expecting %v, got %v", code, expect, got)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package sqldb
+
+import "strings"
+
+// likeEscaper escape the LIKE wildcard and the escape character itself
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// EscapeLike escape % and _ in s so it is matched literally in LIKE pattern with \ as the escape character
+// for example "%" + EscapeLike(term) + "%" match any value containing term
+func EscapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// LikeClause return clause to match column with the LIKE pattern using \ as the escape character
+// the pattern should be built with EscapeLike for user input, for example:
+//
+//	clause, arg := db.LikeClause("name", EscapeLike(term)+"%")
+//	db.Select(&users, db.Rebind("SELECT * FROM users WHERE "+clause), arg)
+//
+// the query return error if the column is not a valid identifier
+func (db *DB) LikeClause(column, pattern string) (clause string, arg interface{}) {
+	if err := validateIdentifier(column); err != nil {
+		return "?", errorValue{err: err}
+	}
+	// backslash is an escape character in mysql string literal
+	escape := `'\'`
+	if db.driver == "mysql" {
+		escape = `'\\'`
+	}
+	return column + " LIKE ? ESCAPE " + escape, pattern
+}
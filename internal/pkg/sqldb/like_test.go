@@ -0,0 +1,79 @@
+package sqldb
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// likeMatch evaluate the LIKE pattern with \ as the escape character
+func likeMatch(pattern, value string) bool {
+	var re strings.Builder
+	re.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			re.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		case c == '%':
+			re.WriteString(".*")
+		case c == '_':
+			re.WriteString(".")
+		default:
+			re.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	re.WriteString("$")
+	return regexp.MustCompile(re.String()).MatchString(value)
+}
+
+func TestEscapeLike(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		term   string
+		value  string
+		expect bool
+	}{
+		{term: "50%", value: "get 50% off", expect: true},
+		{term: "50%", value: "get 500 off", expect: false},
+		{term: "a_b", value: "xa_bx", expect: true},
+		{term: "a_b", value: "xacbx", expect: false},
+		{term: `c:\temp`, value: `dir c:\temp\file`, expect: true},
+		{term: `c:\temp`, value: `dir c:temp`, expect: false},
+	}
+	for _, c := range cases {
+		pattern := "%" + EscapeLike(c.term) + "%"
+		if got := likeMatch(pattern, c.value); got != c.expect {
+			t.Errorf("%s in %s with pattern %s: expecting %v, got %v", c.term, c.value, pattern, c.expect, got)
+		}
+	}
+}
+
+func TestLikeClause(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		driver string
+		expect string
+	}{
+		{driver: "postgres", expect: `name LIKE ? ESCAPE '\'`},
+		{driver: "mysql", expect: `name LIKE ? ESCAPE '\\'`},
+	}
+	for _, c := range cases {
+		db := &DB{driver: c.driver}
+		clause, arg := db.LikeClause("name", EscapeLike("100%")+"%")
+		if clause != c.expect {
+			t.Errorf("%s: expecting %s, got %s", c.driver, c.expect, clause)
+		}
+		if arg != `100\%%` {
+			t.Errorf("%s: expecting escaped pattern, got %v", c.driver, arg)
+		}
+	}
+
+	db := &DB{driver: "postgres"}
+	clause, _ := db.LikeClause("name; DROP TABLE users", "%")
+	if strings.Contains(clause, "DROP") {
+		t.Errorf("expecting invalid column to not be written to the clause, got %s", clause)
+	}
+}
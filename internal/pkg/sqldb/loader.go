@@ -0,0 +1,172 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// list of loader default
+const (
+	defaultLoaderWait     = time.Millisecond
+	defaultLoaderMaxBatch = 100
+	defaultLoaderTimeout  = time.Second * 5
+)
+
+var errLoaderInvalid = errors.New("sqldb: loader must have Query, NewDest and Key")
+
+// Loader batch concurrent LoadByKey with the same loader name into one IN query
+type Loader struct {
+	// Query select the rows by the keys with IN (?), for example SELECT * FROM users WHERE id IN (?)
+	Query string
+	// NewDest return a pointer to an empty slice to scan the rows into, for example &[]User{}
+	NewDest func() interface{}
+	// Key return the key of a scanned element, the key must have the same type as the key passed to LoadByKey
+	Key func(elem interface{}) interface{}
+	// Wait is the duration to collect keys before the query is executed, default to 1ms
+	Wait time.Duration
+	// MaxBatch is the maximum number of keys in one query, default to 100
+	MaxBatch int
+	// Timeout is the timeout of the batch query, default to the follower default timeout or 5s when it is not set
+	Timeout time.Duration
+}
+
+// keyLoader collect the keys of a loader into batch
+type keyLoader struct {
+	db     *DB
+	loader Loader
+
+	mu    sync.Mutex
+	batch *loaderBatch
+}
+
+// loaderBatch is the keys waiting to be loaded in one query
+type loaderBatch struct {
+	keys    []interface{}
+	results map[interface{}]loaderResult
+	// done is closed when results is populated
+	done chan struct{}
+}
+
+type loaderResult struct {
+	value interface{}
+	err   error
+}
+
+// RegisterLoader register loader with the name to be used by LoadByKey
+func (db *DB) RegisterLoader(name string, loader Loader) error {
+	if loader.Query == "" || loader.NewDest == nil || loader.Key == nil {
+		return errLoaderInvalid
+	}
+	if loader.Wait <= 0 {
+		loader.Wait = defaultLoaderWait
+	}
+	if loader.MaxBatch <= 0 {
+		loader.MaxBatch = defaultLoaderMaxBatch
+	}
+	if loader.Timeout <= 0 {
+		loader.Timeout = db.opts.FollowerDefaultTimeout
+	}
+	if loader.Timeout <= 0 {
+		loader.Timeout = defaultLoaderTimeout
+	}
+	if _, loaded := db.loaders.LoadOrStore(name, &keyLoader{db: db, loader: loader}); loaded {
+		return fmt.Errorf("sqldb: loader %s is already registered", name)
+	}
+	return nil
+}
+
+// LoadByKey load the element with the key using the registered loader, sql.ErrNoRows is returned when not found
+// concurrent loads within the loader Wait is batched into one query, and the same key is only queried once.
+// The batch query doesn't use ctx, as the batch is shared by many callers, ctx only stop the caller from waiting.
+// The batch query is bounded by the loader Timeout instead. key must be comparable, as it is used as map key
+func (db *DB) LoadByKey(ctx context.Context, loaderName string, key interface{}) (interface{}, error) {
+	l, ok := db.loaders.Load(loaderName)
+	if !ok {
+		return nil, fmt.Errorf("sqldb: loader %s is not registered", loaderName)
+	}
+	if t := reflect.TypeOf(key); t != nil && !t.Comparable() {
+		return nil, fmt.Errorf("sqldb: loader key must be comparable, got %T", key)
+	}
+	batch := l.(*keyLoader).add(key)
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-batch.done:
+	}
+	result, ok := batch.results[key]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return result.value, result.err
+}
+
+// add the key to the current batch, a new batch is started when there is none
+func (kl *keyLoader) add(key interface{}) *loaderBatch {
+	kl.mu.Lock()
+	defer kl.mu.Unlock()
+
+	if kl.batch == nil {
+		batch := &loaderBatch{done: make(chan struct{})}
+		kl.batch = batch
+		time.AfterFunc(kl.loader.Wait, func() { kl.dispatch(batch) })
+	}
+	batch := kl.batch
+	for _, k := range batch.keys {
+		if k == key {
+			return batch
+		}
+	}
+	batch.keys = append(batch.keys, key)
+	// the full batch is detached under the lock, so the next key start a new batch instead of exceeding MaxBatch
+	if len(batch.keys) >= kl.loader.MaxBatch {
+		kl.batch = nil
+		go kl.run(batch)
+	}
+	return batch
+}
+
+// dispatch load the batch when the Wait is passed, the batch that is already detached by MaxBatch is not loaded again
+func (kl *keyLoader) dispatch(batch *loaderBatch) {
+	kl.mu.Lock()
+	if kl.batch != batch {
+		kl.mu.Unlock()
+		return
+	}
+	kl.batch = nil
+	kl.mu.Unlock()
+
+	kl.run(batch)
+}
+
+// run load the detached batch and release the waiting callers
+func (kl *keyLoader) run(batch *loaderBatch) {
+	batch.results = kl.load(batch.keys)
+	close(batch.done)
+}
+
+func (kl *keyLoader) load(keys []interface{}) map[interface{}]loaderResult {
+	results := make(map[interface{}]loaderResult, len(keys))
+	ctx, cancel := context.WithTimeout(context.Background(), kl.loader.Timeout)
+	defer cancel()
+
+	dest := kl.loader.NewDest()
+	if err := kl.db.SelectIn(ctx, dest, kl.loader.Query, keys); err != nil {
+		for _, key := range keys {
+			results[key] = loaderResult{err: err}
+		}
+		return results
+	}
+
+	slice := reflect.ValueOf(dest).Elem()
+	for i := 0; i < slice.Len(); i++ {
+		elem := slice.Index(i).Interface()
+		results[kl.loader.Key(elem)] = loaderResult{value: elem}
+	}
+	return results
+}
@@ -0,0 +1,202 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLoadByKey(t *testing.T) {
+	t.Parallel()
+
+	db, _, follower := newFakeDB(t)
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		var rows [][]driver.Value
+		for _, arg := range args {
+			// user 404 doesn't exist
+			if id := arg.Value.(int64); id != 404 {
+				rows = append(rows, []driver.Value{id, "user"})
+			}
+		}
+		return fakeResult{columns: []string{"id", "name"}, rows: rows}
+	})
+
+	type user struct {
+		ID   int64  `db:"id"`
+		Name string `db:"name"`
+	}
+	err := db.RegisterLoader("user", Loader{
+		Query:   "SELECT id, name FROM users WHERE id IN (?)",
+		NewDest: func() interface{} { return &[]user{} },
+		Key:     func(elem interface{}) interface{} { return elem.(user).ID },
+		Wait:    time.Millisecond * 50,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		wg   sync.WaitGroup
+		errs = make(chan error, 50)
+	)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(id int64) {
+			defer wg.Done()
+			value, err := db.LoadByKey(context.Background(), "user", id)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if u := value.(user); u.ID != id {
+				t.Errorf("expecting user %d, got %d", id, u.ID)
+			}
+		}(int64(i%5 + 1))
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	var queries int
+	for _, q := range follower.Queries() {
+		if strings.HasPrefix(q, "SELECT id, name FROM users WHERE id IN (") {
+			queries++
+		}
+	}
+	if queries < 1 || queries > 2 {
+		t.Errorf("expecting concurrent loads to be coalesced into one or two queries, got %d", queries)
+	}
+
+	if _, err := db.LoadByKey(context.Background(), "user", int64(404)); err != sql.ErrNoRows {
+		t.Errorf("expecting sql.ErrNoRows, got %v", err)
+	}
+	if _, err := db.LoadByKey(context.Background(), "order", int64(1)); err == nil {
+		t.Error("expecting error for unregistered loader")
+	}
+	if err := db.RegisterLoader("user", Loader{Query: "SELECT 1", NewDest: func() interface{} { return &[]int{} }, Key: func(interface{}) interface{} { return 0 }}); err == nil {
+		t.Error("expecting error for duplicate loader")
+	}
+	if _, err := db.LoadByKey(context.Background(), "user", []int64{1}); err == nil {
+		t.Error("expecting error for non-comparable key")
+	}
+}
+
+func TestLoadByKeyTimeout(t *testing.T) {
+	t.Parallel()
+
+	db, _, follower := newFakeDB(t)
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		<-ctx.Done()
+		return fakeResult{err: ctx.Err()}
+	})
+	err := db.RegisterLoader("id", Loader{
+		Query:   "SELECT id FROM users WHERE id IN (?)",
+		NewDest: func() interface{} { return &[]int64{} },
+		Key:     func(elem interface{}) interface{} { return elem },
+		Timeout: time.Millisecond * 50,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if _, err := db.LoadByKey(ctx, "id", int64(1)); err != context.DeadlineExceeded {
+		t.Errorf("expecting the batch query to be stopped by the loader timeout, got %v", err)
+	}
+}
+
+func TestLoadByKeyMaxBatch(t *testing.T) {
+	t.Parallel()
+
+	db, _, follower := newFakeDB(t)
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		rows := make([][]driver.Value, len(args))
+		for i, arg := range args {
+			rows[i] = []driver.Value{arg.Value}
+		}
+		return fakeResult{columns: []string{"id"}, rows: rows}
+	})
+	err := db.RegisterLoader("id", Loader{
+		Query:    "SELECT id FROM users WHERE id IN (?)",
+		NewDest:  func() interface{} { return &[]int64{} },
+		Key:      func(elem interface{}) interface{} { return elem },
+		Wait:     time.Hour,
+		MaxBatch: 3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the batch is loaded when it is full without waiting for the hour
+	var wg sync.WaitGroup
+	for i := int64(1); i <= 3; i++ {
+		wg.Add(1)
+		go func(id int64) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+			defer cancel()
+			if value, err := db.LoadByKey(ctx, "id", id); err != nil || value != id {
+				t.Errorf("expecting %d, got %v %v", id, value, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestLoadByKeyMaxBatchConcurrent(t *testing.T) {
+	t.Parallel()
+
+	const maxBatch = 5
+	db, _, follower := newFakeDB(t)
+	var (
+		mu      sync.Mutex
+		largest int
+	)
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		mu.Lock()
+		if len(args) > largest {
+			largest = len(args)
+		}
+		mu.Unlock()
+		// slow the query, so keys keep arriving while the full batch is loaded
+		time.Sleep(time.Millisecond * 5)
+		rows := make([][]driver.Value, len(args))
+		for i, arg := range args {
+			rows[i] = []driver.Value{arg.Value}
+		}
+		return fakeResult{columns: []string{"id"}, rows: rows}
+	})
+	err := db.RegisterLoader("id", Loader{
+		Query:    "SELECT id FROM users WHERE id IN (?)",
+		NewDest:  func() interface{} { return &[]int64{} },
+		Key:      func(elem interface{}) interface{} { return elem },
+		Wait:     time.Millisecond * 50,
+		MaxBatch: maxBatch,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := int64(1); i <= 1000; i++ {
+		wg.Add(1)
+		go func(id int64) {
+			defer wg.Done()
+			if value, err := db.LoadByKey(context.Background(), "id", id); err != nil || value != id {
+				t.Errorf("expecting %d, got %v %v", id, value, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if largest > maxBatch {
+		t.Errorf("expecting at most %d keys in a query, got %d", maxBatch, largest)
+	}
+}
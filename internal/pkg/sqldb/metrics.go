@@ -0,0 +1,160 @@
+package sqldb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// list of reason a read is degraded to leader
+const (
+	degradedFollowerDown    = "follower_down"
+	degradedLagExceeded     = "lag_exceeded"
+	degradedStickyRead      = "sticky_read"
+	degradedForced          = "forced"
+	degradedSchemaMigration = "schema_migration"
+)
+
+// unnamedOperation is the operation label of query without operation name
+const unnamedOperation = "unnamed"
+
+var (
+	// prometheus metrics
+	_sqldbReadDegradedCount *prometheus.CounterVec
+	_sqldbQueryCount        *prometheus.CounterVec
+	_sqldbQueryDurationHist *prometheus.HistogramVec
+	_sqldbFollowerLagGauge  *prometheus.GaugeVec
+	// _sqldbReadTierFallbackCount count read that is sent to follower outside the primary tier
+	_sqldbReadTierFallbackCount *prometheus.CounterVec
+	// _sqldbStatementCacheGauge is the number of cached prepared statement by target
+	_sqldbStatementCacheGauge *prometheus.GaugeVec
+	// _sqldbStatementCacheCount count the statement cache hit, miss and eviction by target
+	_sqldbStatementCacheCount *prometheus.CounterVec
+	// _sqldbLeaderUnhealthyCount count the leader become unhealthy because of consecutive failed write
+	_sqldbLeaderUnhealthyCount prometheus.Counter
+)
+
+// throwing fatal if prometheus metrics cannot be registered
+// registration error should not happen if metrics name is different
+func init() {
+	_sqldbReadDegradedCount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "sqldb_read_degraded_to_leader_total",
+		Help: "total of read that is sent to leader instead of follower",
+	}, []string{"reason"})
+	if err := prometheus.Register(_sqldbReadDegradedCount); err != nil {
+		if !errors.As(err, &prometheus.AlreadyRegisteredError{}) {
+			err = fmt.Errorf("error when registering sqldbReadDegradedCount. err: %w", err)
+			log.Fatal(err)
+		}
+	}
+	_sqldbQueryCount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "sqldb_query_total",
+		Help: "total of query by operation name, query without operation name is counted as unnamed",
+	}, []string{"operation", "target", "status"})
+	if err := prometheus.Register(_sqldbQueryCount); err != nil {
+		if !errors.As(err, &prometheus.AlreadyRegisteredError{}) {
+			err = fmt.Errorf("error when registering sqldbQueryCount. err: %w", err)
+			log.Fatal(err)
+		}
+	}
+	_sqldbQueryDurationHist = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "sqldb_query_duration_seconds",
+		Help: "duration of query by operation name and target, query without operation name is observed as unnamed",
+	}, []string{"operation", "target"})
+	if err := prometheus.Register(_sqldbQueryDurationHist); err != nil {
+		if !errors.As(err, &prometheus.AlreadyRegisteredError{}) {
+			err = fmt.Errorf("error when registering sqldbQueryDurationHist. err: %w", err)
+			log.Fatal(err)
+		}
+	}
+	_sqldbFollowerLagGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "sqldb_follower_replication_lag_seconds",
+		Help: "replication lag of follower from the last health check, follower with unknown lag is not reported",
+	}, []string{"follower", "az"})
+	if err := prometheus.Register(_sqldbFollowerLagGauge); err != nil {
+		if !errors.As(err, &prometheus.AlreadyRegisteredError{}) {
+			err = fmt.Errorf("error when registering sqldbFollowerLagGauge. err: %w", err)
+			log.Fatal(err)
+		}
+	}
+	_sqldbReadTierFallbackCount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "sqldb_read_follower_tier_fallback_total",
+		Help: "total of read that is sent to follower in higher tier because no follower in the primary tier is available",
+	}, []string{"tier"})
+	if err := prometheus.Register(_sqldbReadTierFallbackCount); err != nil {
+		if !errors.As(err, &prometheus.AlreadyRegisteredError{}) {
+			err = fmt.Errorf("error when registering sqldbReadTierFallbackCount. err: %w", err)
+			log.Fatal(err)
+		}
+	}
+	_sqldbStatementCacheGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "sqldb_prepared_statement_cache_statements",
+		Help: "number of prepared statement in the statement cache",
+	}, []string{"target"})
+	if err := prometheus.Register(_sqldbStatementCacheGauge); err != nil {
+		if !errors.As(err, &prometheus.AlreadyRegisteredError{}) {
+			err = fmt.Errorf("error when registering sqldbStatementCacheGauge. err: %w", err)
+			log.Fatal(err)
+		}
+	}
+	_sqldbStatementCacheCount = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "sqldb_prepared_statement_cache_total",
+		Help: "total of prepared statement cache lookup by result hit or miss, and eviction",
+	}, []string{"target", "result"})
+	if err := prometheus.Register(_sqldbStatementCacheCount); err != nil {
+		if !errors.As(err, &prometheus.AlreadyRegisteredError{}) {
+			err = fmt.Errorf("error when registering sqldbStatementCacheCount. err: %w", err)
+			log.Fatal(err)
+		}
+	}
+	_sqldbLeaderUnhealthyCount = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "sqldb_leader_unhealthy_total",
+		Help: "total of leader become unhealthy because of consecutive write failed with unwritable leader error",
+	})
+	if err := prometheus.Register(_sqldbLeaderUnhealthyCount); err != nil {
+		if !errors.As(err, &prometheus.AlreadyRegisteredError{}) {
+			err = fmt.Errorf("error when registering sqldbLeaderUnhealthyCount. err: %w", err)
+			log.Fatal(err)
+		}
+	}
+}
+
+// observeReplicationLag set the replication lag gauge of follower, the gauge is removed when the lag is unknown
+func observeReplicationLag(f *followerDB) {
+	lag, ok := f.replicationLag()
+	if !ok {
+		_sqldbFollowerLagGauge.DeleteLabelValues(f.name, f.az)
+		return
+	}
+	_sqldbFollowerLagGauge.WithLabelValues(f.name, f.az).Set(lag.Seconds())
+}
+
+type operationNameKey struct{}
+
+// WithOperationName set the operation label of query metrics, for example list_orders
+// the name should be a constant, as each name create a new metric series
+func WithOperationName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, operationNameKey{}, name)
+}
+
+func operationName(ctx context.Context) string {
+	name, ok := ctx.Value(operationNameKey{}).(string)
+	if !ok || name == "" {
+		return unnamedOperation
+	}
+	return name
+}
+
+func observeQuery(ctx context.Context, q *queryInfo, duration time.Duration, err error) {
+	operation := operationName(ctx)
+	status := "ok"
+	if err != nil {
+		status = "error"
+	}
+	_sqldbQueryCount.WithLabelValues(operation, q.target, status).Inc()
+	_sqldbQueryDurationHist.WithLabelValues(operation, q.target).Observe(duration.Seconds())
+}
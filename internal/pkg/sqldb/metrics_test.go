@@ -0,0 +1,174 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// TestReadDegradedMetrics is not run in parallel so the global counter is not changed by other tests
+func TestReadDegradedMetrics(t *testing.T) {
+	db, leader, follower := newFakeDB(t, WithReplicationLagQuery("SELECT lag"))
+	leader.setHandler(lagHandler(0))
+	follower.setHandler(lagHandler(10))
+	db.checkFollowers(context.Background())
+
+	followerDown := func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{err: errors.New("connection refused")}
+	}
+
+	cases := []struct {
+		name   string
+		ctx    context.Context
+		setup  func()
+		reason string
+	}{
+		{
+			name:   "forced",
+			ctx:    WithConsistency(context.Background(), Strong),
+			reason: degradedForced,
+		},
+		{
+			name:   "lag exceeded",
+			ctx:    WithConsistency(context.Background(), BoundedStaleness(time.Second)),
+			reason: degradedLagExceeded,
+		},
+		{
+			name: "sticky read",
+			ctx: func() context.Context {
+				ctx := db.AfterWrite(context.Background())
+				markWritten(ctx)
+				return ctx
+			}(),
+			reason: degradedStickyRead,
+		},
+		{
+			name: "follower down",
+			ctx:  context.Background(),
+			setup: func() {
+				follower.setHandler(followerDown)
+				db.checkFollowers(context.Background())
+			},
+			reason: degradedFollowerDown,
+		},
+	}
+
+	reasons := []string{degradedFollowerDown, degradedLagExceeded, degradedStickyRead, degradedForced}
+	for _, c := range cases {
+		if c.setup != nil {
+			c.setup()
+		}
+		before := make(map[string]float64)
+		for _, r := range reasons {
+			before[r] = testutil.ToFloat64(_sqldbReadDegradedCount.WithLabelValues(r))
+		}
+
+		var id int
+		if err := db.GetContext(c.ctx, &id, "SELECT id FROM users"); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		for _, r := range reasons {
+			expect := before[r]
+			if r == c.reason {
+				expect++
+			}
+			if got := testutil.ToFloat64(_sqldbReadDegradedCount.WithLabelValues(r)); got != expect {
+				t.Errorf("%s: expecting %s counter to be %v, got %v", c.name, r, expect, got)
+			}
+		}
+	}
+
+	// follower is used again after it is back
+	follower.setHandler(lagHandler(0))
+	db.checkFollowers(context.Background())
+	if _, target := db.reader(context.Background()); target != targetFollower {
+		t.Errorf("expecting follower to be used after recovered, got %s", target)
+	}
+}
+
+// TestQueryMetrics is not run in parallel so the unnamed operation counter is not changed by other tests
+func TestQueryMetrics(t *testing.T) {
+	db, leader, follower := newFakeDB(t)
+	handler := func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		if query == "SELECT broken" {
+			return fakeResult{err: errors.New("syntax error")}
+		}
+		return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}, rowsAffected: 1}
+	}
+	leader.setHandler(handler)
+	follower.setHandler(handler)
+
+	count := func(operation, target, status string) float64 {
+		return testutil.ToFloat64(_sqldbQueryCount.WithLabelValues(operation, target, status))
+	}
+	unnamedBefore := count(unnamedOperation, targetFollower, "ok")
+
+	ctx := WithOperationName(context.Background(), "test_list_orders")
+	var ids []int
+	for i := 0; i < 3; i++ {
+		if err := db.SelectContext(ctx, &ids, "SELECT id FROM orders WHERE user_id = ?", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := db.ExecContext(WithOperationName(context.Background(), "test_cancel_order"), "UPDATE orders SET status = 'cancel' WHERE id = 1"); err != nil {
+		t.Fatal(err)
+	}
+	db.SelectContext(ctx, &ids, "SELECT broken")
+	if err := db.SelectContext(context.Background(), &ids, "SELECT id FROM users"); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		operation string
+		target    string
+		status    string
+		expect    float64
+	}{
+		{operation: "test_list_orders", target: targetFollower, status: "ok", expect: 3},
+		{operation: "test_list_orders", target: targetFollower, status: "error", expect: 1},
+		{operation: "test_cancel_order", target: targetLeader, status: "ok", expect: 1},
+		{operation: unnamedOperation, target: targetFollower, status: "ok", expect: unnamedBefore + 1},
+	}
+	for _, c := range cases {
+		if got := count(c.operation, c.target, c.status); got != c.expect {
+			t.Errorf("%s %s %s: expecting %v, got %v", c.operation, c.target, c.status, c.expect, got)
+		}
+	}
+}
+
+func TestFollowerLagMetrics(t *testing.T) {
+	t.Parallel()
+
+	// the follower name is unique, so the gauge is not changed by other tests
+	const name, az = "lag-metrics-replica", "az-a"
+	db, _, _ := newFakeDB(t, WithReplicationLagQuery("SELECT lag"))
+	server, follower := newFakeServer(t, name)
+	if err := db.SetNamedFollowers([]NamedFollower{{Name: name, AZ: az, DB: follower}}); err != nil {
+		t.Fatal(err)
+	}
+
+	server.setHandler(lagHandler(10))
+	db.checkFollowers(context.Background())
+	if lag := testutil.ToFloat64(_sqldbFollowerLagGauge.WithLabelValues(name, az)); lag != 10 {
+		t.Errorf("expecting lag gauge of 10, got %v", lag)
+	}
+
+	server.setHandler(lagHandler(0.5))
+	db.checkFollowers(context.Background())
+	if lag := testutil.ToFloat64(_sqldbFollowerLagGauge.WithLabelValues(name, az)); lag != 0.5 {
+		t.Errorf("expecting recovered lag gauge of 0.5, got %v", lag)
+	}
+
+	// the fake driver doesn't support replication lag without ReplicationLagQuery
+	db.opts.ReplicationLagQuery = ""
+	db.checkFollowers(context.Background())
+	// DeleteLabelValues return false when the gauge doesn't exist
+	if _sqldbFollowerLagGauge.DeleteLabelValues(name, az) {
+		t.Error("expecting lag gauge to be omitted when lag is not supported")
+	}
+}
@@ -0,0 +1,75 @@
+package sqldb
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// queryReader return the database connection for read of query
+// read that reference a column of Options.MigrationGuard is sent to leader until all followers has the schema version of the column
+func (db *DB) queryReader(ctx context.Context, query string) (*sqlx.DB, string) {
+	if db.migrationPending(query) {
+		return db.degradeToLeader(degradedSchemaMigration)
+	}
+	return db.reader(ctx)
+}
+
+// migrationPending return true when query reference a guarded column that is not migrated in all followers
+func (db *DB) migrationPending(query string) bool {
+	if len(db.opts.MigrationGuard) == 0 {
+		return false
+	}
+
+	lower := strings.ToLower(query)
+	for column, version := range db.opts.MigrationGuard {
+		if !containsIdentifier(lower, strings.ToLower(column)) {
+			continue
+		}
+		for _, f := range db.followerStates() {
+			followerVersion, ok := f.schemaVersion()
+			if !ok || compareSchemaVersion(followerVersion, version) < 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// containsIdentifier return true when query contains ident that is not part of other identifier
+func containsIdentifier(query, ident string) bool {
+	for i := 0; ident != ""; {
+		n := strings.Index(query[i:], ident)
+		if n < 0 {
+			return false
+		}
+		start, end := i+n, i+n+len(ident)
+		if (start == 0 || !isIdentifierChar(query[start-1])) && (end == len(query) || !isIdentifierChar(query[end])) {
+			return true
+		}
+		i = start + 1
+	}
+	return false
+}
+
+func isIdentifierChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// compareSchemaVersion compare numeric version like migration timestamp by the number, other version is compared as string
+func compareSchemaVersion(a, b string) int {
+	an, aErr := strconv.ParseInt(a, 10, 64)
+	bn, bErr := strconv.ParseInt(b, 10, 64)
+	if aErr != nil || bErr != nil {
+		return strings.Compare(a, b)
+	}
+	switch {
+	case an < bn:
+		return -1
+	case an > bn:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,89 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMigrationGuard(t *testing.T) {
+	t.Parallel()
+
+	db, leader, follower := newFakeDB(t,
+		WithSchemaVersionQuery("SELECT MAX(version) FROM schema_migrations"),
+		WithMigrationGuard(map[string]string{"discount_code": "20191101000000"}),
+	)
+	var followerVersion int64 = 20191017070146
+	handler := func(version func() int64) fakeHandler {
+		return func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+			if query == "SELECT MAX(version) FROM schema_migrations" {
+				return fakeResult{columns: []string{"version"}, rows: [][]driver.Value{{version()}}}
+			}
+			return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}}
+		}
+	}
+	leader.setHandler(handler(func() int64 { return 20191101000000 }))
+	follower.setHandler(handler(func() int64 { return atomic.LoadInt64(&followerVersion) }))
+
+	var id int
+	// the follower schema version is unknown before the first check
+	if err := db.GetContext(context.Background(), &id, "SELECT id FROM orders WHERE discount_code = 'a'"); err != nil {
+		t.Fatal(err)
+	}
+	if n := leader.count("SELECT id FROM orders WHERE discount_code = 'a'"); n != 1 {
+		t.Errorf("expecting read with unknown follower version to be sent to leader, got %d", n)
+	}
+
+	if err := db.CheckSchemaVersion(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	queries := []string{
+		"SELECT id FROM orders WHERE discount_code = 'b'",
+		"SELECT o.discount_code FROM orders o",
+	}
+	for _, q := range queries {
+		if err := db.GetContext(context.Background(), &id, q); err != nil {
+			t.Fatal(err)
+		}
+		if leader.count(q) != 1 {
+			t.Errorf("%s: expecting read to be sent to leader while follower is migrating", q)
+		}
+	}
+	// column with the guarded column as prefix is not affected
+	if err := db.GetContext(context.Background(), &id, "SELECT discount_code_v0 FROM orders"); err != nil {
+		t.Fatal(err)
+	}
+	if follower.count("SELECT discount_code_v0 FROM orders") != 1 {
+		t.Error("expecting read without guarded column to be sent to follower")
+	}
+
+	atomic.StoreInt64(&followerVersion, 20191101000000)
+	if err := db.CheckSchemaVersion(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.GetContext(context.Background(), &id, "SELECT id FROM orders WHERE discount_code = 'c'"); err != nil {
+		t.Fatal(err)
+	}
+	if follower.count("SELECT id FROM orders WHERE discount_code = 'c'") != 1 {
+		t.Error("expecting read to be sent to follower after the follower is migrated")
+	}
+}
+
+func TestCompareSchemaVersion(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		a, b   string
+		expect int
+	}{
+		{a: "900", b: "1000", expect: -1},
+		{a: "20191101000000", b: "20191101000000", expect: 0},
+		{a: "v2", b: "v1", expect: 1},
+	}
+	for _, c := range cases {
+		if got := compareSchemaVersion(c.a, c.b); got != c.expect {
+			t.Errorf("%s %s: expecting %d, got %d", c.a, c.b, c.expect, got)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package sqldb
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var (
+	errUnfilteredMutation = errors.New("sqldb: refusing UPDATE/DELETE without WHERE")
+
+	// sqlCommentsAndLiteralsRegex match comments, string literals and quoted identifiers
+	// so keyword inside them is not detected as part of the query
+	sqlCommentsAndLiteralsRegex = regexp.MustCompile(`(?s)--[^\n]*|/\*.*?\*/|'(?:[^']|'')*'|"(?:[^"]|"")*"` + "|`[^`]*`")
+	whereRegex                  = regexp.MustCompile(`(?i)\bWHERE\b`)
+)
+
+type allowFullTableMutationKey struct{}
+
+// WithAllowFullTableMutation allow UPDATE/DELETE without WHERE for queries using the context
+// when RejectUnfilteredMutations is enabled
+func WithAllowFullTableMutation(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allowFullTableMutationKey{}, true)
+}
+
+func allowFullTableMutation(ctx context.Context) bool {
+	allow, _ := ctx.Value(allowFullTableMutationKey{}).(bool)
+	return allow
+}
+
+// stripQuery remove comments and literals from query
+func stripQuery(query string) string {
+	return sqlCommentsAndLiteralsRegex.ReplaceAllString(query, " ")
+}
+
+// isUnfilteredMutation return true if the query is UPDATE or DELETE without WHERE
+func isUnfilteredMutation(query string) bool {
+	stripped := strings.TrimSpace(stripQuery(query))
+	fields := strings.Fields(stripped)
+	if len(fields) == 0 {
+		return false
+	}
+	switch strings.ToUpper(fields[0]) {
+	case "UPDATE", "DELETE":
+		return !whereRegex.MatchString(stripped)
+	}
+	return false
+}
+
+// checkMutation return error if the query is UPDATE or DELETE without WHERE
+// and the guard is enabled
+func (db *DB) checkMutation(ctx context.Context, query string) error {
+	if !db.opts.RejectUnfilteredMutations || allowFullTableMutation(ctx) {
+		return nil
+	}
+	if isUnfilteredMutation(query) {
+		return errUnfilteredMutation
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package sqldb
+
+import (
+	"context"
+	"testing"
+)
+
+func TestIsUnfilteredMutation(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		query  string
+		expect bool
+	}{
+		{query: "DELETE FROM users", expect: true},
+		{query: "  update users set name = 'where'", expect: true},
+		{query: "DELETE FROM users -- WHERE id = 1", expect: true},
+		{query: "DELETE FROM users /* WHERE */", expect: true},
+		{query: "DELETE FROM users WHERE id = ?", expect: false},
+		{query: "UPDATE users SET name = ? WHERE id = ?", expect: false},
+		{query: "SELECT * FROM users", expect: false},
+		{query: "INSERT INTO users(name) VALUES(?)", expect: false},
+	}
+
+	for _, c := range cases {
+		if got := isUnfilteredMutation(c.query); got != c.expect {
+			t.Errorf("%s: expecting %v, got %v", c.query, c.expect, got)
+		}
+	}
+}
+
+func TestRejectUnfilteredMutations(t *testing.T) {
+	t.Parallel()
+
+	db, leader, _ := newFakeDB(t, WithRejectUnfilteredMutations())
+	if _, err := db.Exec("DELETE FROM users"); err != errUnfilteredMutation {
+		t.Errorf("expecting error %v, got %v", errUnfilteredMutation, err)
+	}
+	if _, err := db.Exec("DELETE FROM users WHERE id = ?", 1); err != nil {
+		t.Errorf("expecting no error, got %v", err)
+	}
+	ctx := WithAllowFullTableMutation(context.Background())
+	if _, err := db.ExecContext(ctx, "DELETE FROM users"); err != nil {
+		t.Errorf("expecting no error with override, got %v", err)
+	}
+	if n := len(leader.Queries()); n != 2 {
+		t.Errorf("expecting 2 queries to reach leader, got %d", n)
+	}
+}
@@ -0,0 +1,112 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrDatabaseDisabled is returned by write to noop DB
+var ErrDatabaseDisabled = errors.New("sqldb: database disabled")
+
+// noopDriverName is the driver name of noop DB, it is not registered to database/sql
+const noopDriverName = "sqldbnoop"
+
+// NewNoop return DB that never connect to any database, for service that run with the database feature disabled
+// read return no rows, so Get return sql.ErrNoRows and Select return empty result
+// write and transaction return ErrDatabaseDisabled
+func NewNoop() *DB {
+	newNoopDB := func() *sqlx.DB {
+		return sqlx.NewDb(sql.OpenDB(noopConnector{}), noopDriverName)
+	}
+	return &DB{
+		driver:    noopDriverName,
+		leader:    newNoopDB(),
+		followers: []*followerDB{newFollower(defaultFollowerName(0), newNoopDB())},
+		rand:      newLockedRand(nil),
+		done:      make(chan struct{}),
+	}
+}
+
+type noopConnector struct{}
+
+func (noopConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return noopConn{}, nil
+}
+
+func (noopConnector) Driver() driver.Driver {
+	return noopDriver{}
+}
+
+type noopDriver struct{}
+
+func (noopDriver) Open(name string) (driver.Conn, error) {
+	return noopConn{}, nil
+}
+
+type noopConn struct{}
+
+func (noopConn) Prepare(query string) (driver.Stmt, error) {
+	return noopStmt{}, nil
+}
+
+func (noopConn) Close() error {
+	return nil
+}
+
+func (noopConn) Begin() (driver.Tx, error) {
+	return nil, ErrDatabaseDisabled
+}
+
+func (noopConn) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (noopConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return noopRows{}, nil
+}
+
+func (noopConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return nil, ErrDatabaseDisabled
+}
+
+// CheckNamedValue accept any argument, as the argument is never sent to a database
+func (noopConn) CheckNamedValue(*driver.NamedValue) error {
+	return nil
+}
+
+type noopStmt struct{}
+
+func (noopStmt) Close() error {
+	return nil
+}
+
+func (noopStmt) NumInput() int {
+	return -1
+}
+
+func (noopStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, ErrDatabaseDisabled
+}
+
+func (noopStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return noopRows{}, nil
+}
+
+type noopRows struct{}
+
+func (noopRows) Columns() []string {
+	return nil
+}
+
+func (noopRows) Close() error {
+	return nil
+}
+
+func (noopRows) Next(dest []driver.Value) error {
+	return io.EOF
+}
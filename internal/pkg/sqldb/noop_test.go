@@ -0,0 +1,56 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+)
+
+func TestNoop(t *testing.T) {
+	t.Parallel()
+
+	db := NewNoop()
+	defer db.Close()
+	ctx := context.Background()
+
+	var user struct {
+		ID   int64  `db:"id"`
+		Name string `db:"name"`
+	}
+	if err := db.GetContext(ctx, &user, "SELECT id, name FROM users WHERE id = ?", 1); err != sql.ErrNoRows {
+		t.Errorf("Get: expecting sql.ErrNoRows, got %v", err)
+	}
+	var ids []int64
+	if err := db.SelectContext(ctx, &ids, "SELECT id FROM users"); err != nil || len(ids) != 0 {
+		t.Errorf("Select: expecting empty result, got %v %v", ids, err)
+	}
+	rows, err := db.QueryContext(ctx, "SELECT id FROM users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows.Next() {
+		t.Error("Query: expecting no rows")
+	}
+	rows.Close()
+	var id int64
+	if err := db.QueryRowContext(ctx, "SELECT id FROM users").Scan(&id); err != sql.ErrNoRows {
+		t.Errorf("QueryRow: expecting sql.ErrNoRows, got %v", err)
+	}
+
+	if _, err := db.ExecContext(ctx, "UPDATE users SET name = ? WHERE id = ?", "name", 1); err != ErrDatabaseDisabled {
+		t.Errorf("Exec: expecting ErrDatabaseDisabled, got %v", err)
+	}
+	if _, err := db.NamedExecContext(ctx, "UPDATE users SET name = :name WHERE id = :id", user); err != ErrDatabaseDisabled {
+		t.Errorf("NamedExec: expecting ErrDatabaseDisabled, got %v", err)
+	}
+	err = db.WithTransaction(ctx, nil, func(ctx context.Context, tx *Tx) error {
+		t.Error("expecting transaction function not to be called")
+		return nil
+	})
+	if err != ErrDatabaseDisabled {
+		t.Errorf("WithTransaction: expecting ErrDatabaseDisabled, got %v", err)
+	}
+	if err := db.Leader().PingContext(ctx); err != nil {
+		t.Errorf("Ping: expecting no error, got %v", err)
+	}
+}
@@ -0,0 +1,389 @@
+package sqldb
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/albertwidi/go-project-example/internal/pkg/log/logger"
+)
+
+// Options of sqldb, set by Wrap using list of Option
+type Options struct {
+	// Logger used by sqldb to log information, nothing is logged when logger is nil
+	Logger logger.Logger
+	// SlowQueryThreshold is the minimum duration of a query to be logged as slow query, disabled when zero
+	// the query arguments is never logged to the Logger to keep PII out of the logs
+	SlowQueryThreshold time.Duration
+	// SecureSink receive full slow query detail including the arguments
+	SecureSink SecureSink
+	// HealthCheckInterval is the interval of followers health check, disabled when zero
+	// the health check update the replication lag of each follower
+	HealthCheckInterval time.Duration
+	// ReplicationLagQuery is the query to get replication lag in seconds
+	// by default the query is chosen by driver
+	ReplicationLagQuery string
+	// CounterFlushInterval is the interval to flush buffered counters, counters is only flushed manually or on Close when zero
+	CounterFlushInterval time.Duration
+	// RejectUnfilteredMutations reject UPDATE/DELETE without WHERE
+	// use WithAllowFullTableMutation context to bypass the guard
+	RejectUnfilteredMutations bool
+	// SchemaVersionQuery is the query to get the schema version, for example the max version of migrations table
+	// the schema version of leader and followers is compared on Wrap and on every health check
+	SchemaVersionQuery string
+	// MaxQueryParams is the maximum number of parameters in one query, default to 65535
+	// IN query with more parameters is split into chunks by SelectIn
+	MaxQueryParams int
+	// IdentifierPolicy decide how table and column name is written in SQL generated by sqldb
+	IdentifierPolicy IdentifierPolicy
+	// ConstraintMessages map constraint or index name to a user-facing message for ConstraintError
+	ConstraintMessages map[string]string
+	// ResourceUsageSampleRate is the fraction of SELECT on postgres to sample for resource usage, from 0 to 1, disabled when zero
+	// a sampled query is executed again with EXPLAIN (ANALYZE, BUFFERS) in the background on the same connection pool,
+	// so each sample double the cost of the query. Keep the rate low, for example 0.001, in production
+	ResourceUsageSampleRate float64
+	// CircuitBreaker fail fast query with fingerprint that keep failing or being slow, disabled when zero
+	CircuitBreaker CircuitBreaker
+	// AsyncQueueSize is the size of ExecAsync queue, ExecAsync is disabled when zero
+	AsyncQueueSize int
+	// AsyncWorkers is the number of goroutine executing the ExecAsync queue, default to 1
+	AsyncWorkers int
+	// QueryAllowlist is the list of allowed query fingerprint, query with other fingerprint is rejected
+	// all query is allowed when empty. Query executed directly on a transaction is not checked
+	QueryAllowlist []string
+	// LeaderDefaultTimeout is the timeout of query to leader when the context has no deadline, disabled when zero
+	LeaderDefaultTimeout time.Duration
+	// FollowerDefaultTimeout is the timeout of query to follower when the context has no deadline, disabled when zero
+	FollowerDefaultTimeout time.Duration
+	// FaultInjector inject latency and error to queries, only set this for chaos testing
+	// the injector can be replaced or disabled at runtime with SetFaultInjector
+	FaultInjector FaultInjector
+	// StatsSampleInterval is the interval to record connection pool stats for StatsHistory, disabled when zero
+	StatsSampleInterval time.Duration
+	// StatsHistorySize is the number of stats sample kept, default to 60
+	StatsHistorySize int
+	// FollowerDrainTimeout is the maximum duration DrainFollower wait for in-flight queries, default to 30 seconds
+	FollowerDrainTimeout time.Duration
+	// ReadRetries is the number of retry of read that is failed with retryable error, disabled when zero
+	// write is never retried
+	ReadRetries int
+	// LogRetryDecisions log the retry classification of every failed read when ReadRetries is set
+	LogRetryDecisions bool
+	// TimeoutComment prepend /* timeout=Nms */ with the remaining context deadline to the query
+	// this let a query proxy that honor the comment cancel the query in sync with the client deadline
+	TimeoutComment bool
+	// FollowerDiscovery is called every FollowerDiscoveryInterval to refresh the followers, disabled when nil
+	FollowerDiscovery         FollowerDiscoveryFunc
+	FollowerDiscoveryInterval time.Duration
+	// DiagnoseSchemaErrors log the actual columns of the referenced table when a query failed with undefined column or table
+	// the columns is read from information_schema in the background, so the failed query is not delayed
+	DiagnoseSchemaErrors bool
+	// PreferredAZ is the availability zone of the service, available follower in the same AZ is preferred for read
+	// follower in other AZ is used when no follower in the preferred AZ is available
+	PreferredAZ string
+	// FollowerConnectOptions is used by ReconnectFollower to connect to the new follower
+	FollowerConnectOptions *ConnectOptions
+	// Policies map operation name set by WithOperationName to the timeout, target, retry and slow query threshold of the operation
+	Policies map[string]OpPolicy
+	// MigrationGuard map column name to the schema version that add the column, SchemaVersionQuery must be set
+	// read that reference the column is sent to leader until all followers report the schema version on the health check
+	MigrationGuard map[string]string
+	// RandSource is the source of randomness for resource usage sampling and transaction retry jitter
+	// by default a source seeded by the current time is used, set a fixed seed source for reproducible tests
+	RandSource rand.Source
+	// WriteFunctions is the set of function name that modify data, CallFunction call the function on leader
+	WriteFunctions map[string]bool
+	// RepeatedQueryThreshold is the number of identical query using WithQueryCounter context before it is logged, default to 10
+	RepeatedQueryThreshold int
+	// IdempotencyTable is the table of idempotency key used by ExecIdempotent, default to sqldb_idempotency_keys
+	IdempotencyTable string
+	// AllowDriverMismatch allow follower with different driver name than the leader, for example a postgres-wire-compatible proxy
+	// query is still rebound using the leader driver
+	AllowDriverMismatch bool
+	// StatementCacheSize is the maximum number of cached prepared statement for Get, Select and Exec, disabled when zero
+	// the least-recently-used statement is closed and deallocated from the server when the cache is full.
+	// Keep it below the server limit of prepared statement per session, for example max_prepared_stmt_count in mysql
+	StatementCacheSize int
+	// LeaderWriteFailureThreshold is the number of consecutive write failed because the leader is unwritable
+	// before LeaderHealthy return false, default to 3
+	LeaderWriteFailureThreshold int
+	// ScanTimeout is the maximum duration of reading the rows of Select, Reduce and Iterate after the query is executed, disabled when zero
+	// the scan is stopped with ErrScanTimeout, so a slow consumer doesn't hold the connection
+	ScanTimeout time.Duration
+	// WarnTransactionWithoutWrite log a warning when WithTransaction is committed without any Exec or NamedExec in the transaction
+	// such transaction only read from leader, and could use a read-only transaction or read from follower
+	WarnTransactionWithoutWrite bool
+	// LayeredCacheSize is the maximum number of row cached in-process by LayeredGet, default to 1000
+	LayeredCacheSize int
+}
+
+// Option to configure DB
+type Option func(opts *Options)
+
+// WithLogger set the logger of sqldb
+func WithLogger(l logger.Logger) Option {
+	return func(opts *Options) {
+		opts.Logger = l
+	}
+}
+
+// WithSlowQuery log query slower than threshold, the full query detail is sent to sink if sink is not nil
+func WithSlowQuery(threshold time.Duration, sink SecureSink) Option {
+	return func(opts *Options) {
+		opts.SlowQueryThreshold = threshold
+		opts.SecureSink = sink
+	}
+}
+
+// WithHealthCheck check the followers health every interval
+func WithHealthCheck(interval time.Duration) Option {
+	return func(opts *Options) {
+		opts.HealthCheckInterval = interval
+	}
+}
+
+// WithReplicationLagQuery set the query to get replication lag in seconds
+func WithReplicationLagQuery(query string) Option {
+	return func(opts *Options) {
+		opts.ReplicationLagQuery = query
+	}
+}
+
+// WithCounterFlushInterval flush the buffered counters every interval
+func WithCounterFlushInterval(interval time.Duration) Option {
+	return func(opts *Options) {
+		opts.CounterFlushInterval = interval
+	}
+}
+
+// WithRejectUnfilteredMutations reject UPDATE/DELETE query without WHERE
+func WithRejectUnfilteredMutations() Option {
+	return func(opts *Options) {
+		opts.RejectUnfilteredMutations = true
+	}
+}
+
+// WithSchemaVersionQuery set the query to get the schema version
+func WithSchemaVersionQuery(query string) Option {
+	return func(opts *Options) {
+		opts.SchemaVersionQuery = query
+	}
+}
+
+// WithMaxQueryParams set the maximum number of parameters in one query
+func WithMaxQueryParams(n int) Option {
+	return func(opts *Options) {
+		opts.MaxQueryParams = n
+	}
+}
+
+// WithIdentifierPolicy set how identifier is written in SQL generated by sqldb
+func WithIdentifierPolicy(policy IdentifierPolicy) Option {
+	return func(opts *Options) {
+		opts.IdentifierPolicy = policy
+	}
+}
+
+// WithConstraintMessages map constraint or index name to a user-facing message
+func WithConstraintMessages(messages map[string]string) Option {
+	return func(opts *Options) {
+		opts.ConstraintMessages = messages
+	}
+}
+
+// WithResourceUsageSampling log the shared buffer hits/reads and rows of sampled query on postgres
+func WithResourceUsageSampling(rate float64) Option {
+	return func(opts *Options) {
+		opts.ResourceUsageSampleRate = rate
+	}
+}
+
+// WithCircuitBreaker enable per-fingerprint circuit breaker
+func WithCircuitBreaker(cb CircuitBreaker) Option {
+	return func(opts *Options) {
+		opts.CircuitBreaker = cb
+	}
+}
+
+// WithAsyncExec enable ExecAsync with bounded queue of size and number of workers
+func WithAsyncExec(size, workers int) Option {
+	return func(opts *Options) {
+		opts.AsyncQueueSize = size
+		opts.AsyncWorkers = workers
+	}
+}
+
+// WithQueryAllowlist only allow query with fingerprint in the list
+func WithQueryAllowlist(fingerprints []string) Option {
+	return func(opts *Options) {
+		opts.QueryAllowlist = fingerprints
+	}
+}
+
+// WithDefaultTimeout set the timeout of query to leader and follower when the context has no deadline
+func WithDefaultTimeout(leader, follower time.Duration) Option {
+	return func(opts *Options) {
+		opts.LeaderDefaultTimeout = leader
+		opts.FollowerDefaultTimeout = follower
+	}
+}
+
+// WithFaultInjector inject fault to queries using fi
+func WithFaultInjector(fi FaultInjector) Option {
+	return func(opts *Options) {
+		opts.FaultInjector = fi
+	}
+}
+
+// WithStatsHistory record the connection pool stats every interval, and keep the last size samples
+func WithStatsHistory(interval time.Duration, size int) Option {
+	return func(opts *Options) {
+		opts.StatsSampleInterval = interval
+		opts.StatsHistorySize = size
+	}
+}
+
+// WithFollowerDrainTimeout set the maximum duration DrainFollower wait for in-flight queries
+func WithFollowerDrainTimeout(timeout time.Duration) Option {
+	return func(opts *Options) {
+		opts.FollowerDrainTimeout = timeout
+	}
+}
+
+// WithReadRetries retry read that is failed with retryable error up to n times
+func WithReadRetries(n int) Option {
+	return func(opts *Options) {
+		opts.ReadRetries = n
+	}
+}
+
+// WithRetryLogging log the retry classification of failed read
+func WithRetryLogging() Option {
+	return func(opts *Options) {
+		opts.LogRetryDecisions = true
+	}
+}
+
+// WithTimeoutComment prepend the remaining context deadline as comment to the query
+func WithTimeoutComment() Option {
+	return func(opts *Options) {
+		opts.TimeoutComment = true
+	}
+}
+
+// WithFollowerDiscovery refresh the followers from discover every interval
+func WithFollowerDiscovery(interval time.Duration, discover FollowerDiscoveryFunc) Option {
+	return func(opts *Options) {
+		opts.FollowerDiscoveryInterval = interval
+		opts.FollowerDiscovery = discover
+	}
+}
+
+// WithSchemaErrorDiagnostics log the actual columns of the table when a query failed with undefined column or table
+func WithSchemaErrorDiagnostics() Option {
+	return func(opts *Options) {
+		opts.DiagnoseSchemaErrors = true
+	}
+}
+
+// WithPreferredAZ prefer follower in az for read
+func WithPreferredAZ(az string) Option {
+	return func(opts *Options) {
+		opts.PreferredAZ = az
+	}
+}
+
+// WithFollowerConnectOptions set the connect options used by ReconnectFollower
+func WithFollowerConnectOptions(connOpts *ConnectOptions) Option {
+	return func(opts *Options) {
+		opts.FollowerConnectOptions = connOpts
+	}
+}
+
+// WithPolicy set the query policy of each operation name
+func WithPolicy(policies map[string]OpPolicy) Option {
+	return func(opts *Options) {
+		opts.Policies = policies
+	}
+}
+
+// WithMigrationGuard route read that reference the columns to leader until all followers has the schema version of the column
+func WithMigrationGuard(columns map[string]string) Option {
+	return func(opts *Options) {
+		opts.MigrationGuard = columns
+	}
+}
+
+// WithRandSource set the source of randomness for sampling and jitter
+func WithRandSource(src rand.Source) Option {
+	return func(opts *Options) {
+		opts.RandSource = src
+	}
+}
+
+// WithWriteFunctions set the function that modify data, so CallFunction call the function on leader
+func WithWriteFunctions(names ...string) Option {
+	return func(opts *Options) {
+		if opts.WriteFunctions == nil {
+			opts.WriteFunctions = make(map[string]bool, len(names))
+		}
+		for _, name := range names {
+			opts.WriteFunctions[name] = true
+		}
+	}
+}
+
+// WithRepeatedQueryThreshold set the number of identical query in WithQueryCounter context before it is logged
+func WithRepeatedQueryThreshold(n int) Option {
+	return func(opts *Options) {
+		opts.RepeatedQueryThreshold = n
+	}
+}
+
+// WithIdempotencyTable set the table of idempotency key used by ExecIdempotent
+func WithIdempotencyTable(table string) Option {
+	return func(opts *Options) {
+		opts.IdempotencyTable = table
+	}
+}
+
+// WithAllowDriverMismatch allow follower with different driver name than the leader
+func WithAllowDriverMismatch() Option {
+	return func(opts *Options) {
+		opts.AllowDriverMismatch = true
+	}
+}
+
+// WithStatementCache cache up to size prepared statement for Get, Select and Exec
+func WithStatementCache(size int) Option {
+	return func(opts *Options) {
+		opts.StatementCacheSize = size
+	}
+}
+
+// WithLeaderWriteFailureThreshold set the number of consecutive failed write before the leader is unhealthy
+func WithLeaderWriteFailureThreshold(n int) Option {
+	return func(opts *Options) {
+		opts.LeaderWriteFailureThreshold = n
+	}
+}
+
+// WithScanTimeout set the maximum duration of reading the rows of Select, Reduce and Iterate
+func WithScanTimeout(timeout time.Duration) Option {
+	return func(opts *Options) {
+		opts.ScanTimeout = timeout
+	}
+}
+
+// WithTransactionWithoutWriteWarning log a warning when WithTransaction only read
+func WithTransactionWithoutWriteWarning() Option {
+	return func(opts *Options) {
+		opts.WarnTransactionWithoutWrite = true
+	}
+}
+
+// WithLayeredCacheSize set the maximum number of row cached in-process by LayeredGet
+func WithLayeredCacheSize(n int) Option {
+	return func(opts *Options) {
+		opts.LayeredCacheSize = n
+	}
+}
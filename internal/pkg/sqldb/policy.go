@@ -0,0 +1,48 @@
+package sqldb
+
+import (
+	"context"
+	"time"
+)
+
+// OpPolicy is the query behavior of an operation, the operation is set to the context by WithOperationName
+type OpPolicy struct {
+	// Timeout of each query of the operation when the context has no deadline, the default timeout of the target is used when zero
+	Timeout time.Duration
+	// Target of the read of the operation, leader always read from leader and follower read by Eventual consistency
+	// the read is sent by the context consistency when empty. Consistency set by WithConsistency take precedence
+	Target string
+	// Retryable allow read of the operation to be retried up to Options.ReadRetries
+	// read of operation with policy is never retried when false
+	Retryable bool
+	// SlowQueryThreshold of the operation, Options.SlowQueryThreshold is used when zero
+	SlowQueryThreshold time.Duration
+}
+
+// policy return the policy of the context operation, if any
+func (db *DB) policy(ctx context.Context) (OpPolicy, bool) {
+	if len(db.opts.Policies) == 0 {
+		return OpPolicy{}, false
+	}
+	name, ok := ctx.Value(operationNameKey{}).(string)
+	if !ok {
+		return OpPolicy{}, false
+	}
+	p, ok := db.opts.Policies[name]
+	return p, ok
+}
+
+// policyConsistency return the consistency level of the context operation target, ok is false when the policy has no target
+func (db *DB) policyConsistency(ctx context.Context) (ConsistencyLevel, bool) {
+	p, ok := db.policy(ctx)
+	if !ok {
+		return ConsistencyLevel{}, false
+	}
+	switch p.Target {
+	case targetLeader:
+		return Strong, true
+	case targetFollower:
+		return Eventual, true
+	}
+	return ConsistencyLevel{}, false
+}
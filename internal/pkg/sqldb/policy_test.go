@@ -0,0 +1,96 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func TestPolicy(t *testing.T) {
+	t.Parallel()
+
+	l := &fakeLogger{}
+	db, leader, follower := newFakeDB(t,
+		WithLogger(l),
+		WithReadRetries(2),
+		WithDefaultTimeout(time.Minute, time.Minute),
+		WithPolicy(map[string]OpPolicy{
+			"get_balance": {Timeout: time.Second, Target: "leader", SlowQueryThreshold: time.Nanosecond},
+			"list_orders": {Timeout: time.Second * 10, Target: "follower", Retryable: true},
+			"search":      {},
+		}),
+	)
+
+	var (
+		mu        sync.Mutex
+		deadlines = make(map[string]time.Duration)
+		failed    = make(map[string]bool)
+	)
+	handler := func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		mu.Lock()
+		defer mu.Unlock()
+		if deadline, ok := ctx.Deadline(); ok {
+			deadlines[query] = time.Until(deadline)
+		}
+		// every query fail once with deadlock
+		if !failed[query] {
+			failed[query] = true
+			return fakeResult{err: &pq.Error{Code: "40P01"}}
+		}
+		return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}}
+	}
+	leader.setHandler(handler)
+	follower.setHandler(handler)
+
+	var id int
+	if err := db.GetContext(WithOperationName(context.Background(), "get_balance"), &id, "SELECT balance"); err == nil {
+		t.Error("expecting get_balance to not be retried")
+	}
+	if err := db.GetContext(WithOperationName(context.Background(), "list_orders"), &id, "SELECT orders"); err != nil {
+		t.Errorf("expecting list_orders to be retried, got %v", err)
+	}
+	if err := db.GetContext(WithOperationName(context.Background(), "search"), &id, "SELECT search"); err == nil {
+		t.Error("expecting search to not be retried")
+	}
+	if err := db.GetContext(context.Background(), &id, "SELECT unnamed"); err != nil {
+		t.Errorf("expecting read without policy to be retried, got %v", err)
+	}
+	// the context consistency take precedence over the policy target
+	if err := db.GetContext(WithConsistency(WithOperationName(context.Background(), "get_balance"), Eventual), &id, "SELECT eventual"); err == nil {
+		t.Error("expecting get_balance to not be retried")
+	}
+
+	cases := []struct {
+		query  string
+		target *fakeServer
+		min    time.Duration
+		max    time.Duration
+	}{
+		{query: "SELECT balance", target: leader, min: 0, max: time.Second},
+		{query: "SELECT orders", target: follower, min: time.Second * 9, max: time.Second * 10},
+		{query: "SELECT search", target: follower, min: time.Second * 50, max: time.Minute},
+		{query: "SELECT unnamed", target: follower, min: time.Second * 50, max: time.Minute},
+		{query: "SELECT eventual", target: follower, min: 0, max: time.Second},
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for _, c := range cases {
+		if c.target.count(c.query) == 0 {
+			t.Errorf("%s: expecting query to be sent to the policy target", c.query)
+		}
+		if got := deadlines[c.query]; got <= c.min || got > c.max {
+			t.Errorf("%s: expecting timeout between %s and %s, got %s", c.query, c.min, c.max, got)
+		}
+	}
+
+	if !l.contains("query:SELECT balance") {
+		t.Error("expecting get_balance to be logged as slow query")
+	}
+	if l.contains("query:SELECT orders") {
+		t.Error("expecting list_orders to not be logged as slow query")
+	}
+}
@@ -0,0 +1,42 @@
+package sqldb
+
+import (
+	"strings"
+
+	"github.com/jmoiron/sqlx/reflectx"
+)
+
+// protoMapper map protobuf-generated struct field to column using the name= part of the protobuf tag
+// for example `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3"` is mapped to user_id
+var protoMapper = reflectx.NewMapperTagFunc("protobuf", protoFieldName, protoTagName)
+
+// protoFieldName map field without protobuf tag, generated XXX_ fields is skipped
+func protoFieldName(name string) string {
+	if strings.HasPrefix(name, "XXX_") {
+		return "-"
+	}
+	return strings.ToLower(name)
+}
+
+// protoTagName return the name= value of protobuf tag
+func protoTagName(tag string) string {
+	for _, part := range strings.Split(tag, ",") {
+		if strings.HasPrefix(part, "name=") {
+			return strings.TrimPrefix(part, "name=")
+		}
+	}
+	return tag
+}
+
+// SetProtoMapper scan into protobuf-generated struct using the protobuf field name as the column name
+// this replace the db tag mapping of leader and all followers, so it should be called before the DB is used
+func (db *DB) SetProtoMapper() {
+	db.followersMu.Lock()
+	defer db.followersMu.Unlock()
+
+	db.mapper = protoMapper
+	db.leader.Mapper = protoMapper
+	for _, f := range db.followers {
+		f.db.Mapper = protoMapper
+	}
+}
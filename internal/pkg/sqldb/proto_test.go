@@ -0,0 +1,57 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// testUserProto is shaped like a protoc-gen-go generated struct
+type testUserProto struct {
+	state         struct{}
+	sizeCache     int32
+	unknownFields []byte
+
+	UserId               int64    `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	DisplayName          string   `protobuf:"bytes,2,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func TestProtoMapper(t *testing.T) {
+	t.Parallel()
+
+	db, _, follower := newFakeDB(t)
+	handler := func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{
+			columns: []string{"user_id", "display_name"},
+			rows:    [][]driver.Value{{int64(42), "alice"}, {int64(43), "bob"}},
+		}
+	}
+	follower.setHandler(handler)
+	db.SetProtoMapper()
+
+	var users []testUserProto
+	if err := db.SelectContext(context.Background(), &users, "SELECT user_id, display_name FROM users"); err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 || users[0].UserId != 42 || users[0].DisplayName != "alice" || users[1].UserId != 43 || users[1].DisplayName != "bob" {
+		t.Errorf("unexpected users %+v", users)
+	}
+
+	// follower set after SetProtoMapper also use the mapper
+	newServer, newFollower := newFakeServer(t, "new-follower")
+	newServer.setHandler(handler)
+	if err := db.SetFollowers([]*sqlx.DB{newFollower}); err != nil {
+		t.Fatal(err)
+	}
+	var user testUserProto
+	if err := db.GetContext(context.Background(), &user, "SELECT user_id, display_name FROM users LIMIT 1"); err != nil {
+		t.Fatal(err)
+	}
+	if user.UserId != 42 || user.DisplayName != "alice" {
+		t.Errorf("unexpected user %+v", user)
+	}
+}
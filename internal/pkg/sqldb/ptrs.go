@@ -0,0 +1,28 @@
+package sqldb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// SelectPtrs select rows into dest that must be a pointer to slice of pointer, for example *[]*User
+// every element is a non-nil pointer populated from a row, NULL column is scanned into the pointer field of the element
+func (db *DB) SelectPtrs(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	value := reflect.ValueOf(dest)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Slice || value.Elem().Type().Elem().Kind() != reflect.Ptr {
+		return fmt.Errorf("sqldb: SelectPtrs destination must be a pointer to a slice of pointer, got %T", dest)
+	}
+
+	slice := value.Elem()
+	start := slice.Len()
+	if err := db.SelectContext(ctx, dest, query, args...); err != nil {
+		return err
+	}
+	for i := start; i < slice.Len(); i++ {
+		if slice.Index(i).IsNil() {
+			return fmt.Errorf("sqldb: SelectPtrs scanned nil element at index %d", i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+)
+
+func TestSelectPtrs(t *testing.T) {
+	t.Parallel()
+
+	db, _, follower := newFakeDB(t)
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{
+			columns: []string{"id", "nickname"},
+			rows:    [][]driver.Value{{int64(1), "al"}, {int64(2), nil}},
+		}
+	})
+
+	type user struct {
+		ID       int64   `db:"id"`
+		Nickname *string `db:"nickname"`
+	}
+
+	var users []*user
+	if err := db.SelectPtrs(context.Background(), &users, "SELECT id, nickname FROM users"); err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 {
+		t.Fatalf("expecting 2 users, got %d", len(users))
+	}
+	for i, u := range users {
+		if u == nil {
+			t.Fatalf("expecting element %d to be non-nil", i)
+		}
+	}
+	if users[0].ID != 1 || users[0].Nickname == nil || *users[0].Nickname != "al" {
+		t.Errorf("unexpected first user %+v", users[0])
+	}
+	if users[1].ID != 2 || users[1].Nickname != nil {
+		t.Errorf("expecting NULL nickname to be nil, got %+v", users[1])
+	}
+
+	var values []user
+	if err := db.SelectPtrs(context.Background(), &values, "SELECT id, nickname FROM users"); err == nil {
+		t.Error("expecting error for slice of non-pointer")
+	}
+}
@@ -0,0 +1,102 @@
+package sqldb
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// list of query target
+const (
+	targetLeader   = "leader"
+	targetFollower = "follower"
+)
+
+// queryInfo is the information of query that pass through sqldb
+type queryInfo struct {
+	query  string
+	args   []interface{}
+	target string
+	// rows is true when the query return rows to the caller
+	// the context of rows query cannot be canceled when the query function return
+	rows bool
+	// write is true when the query modify data
+	write bool
+	// handle is the database connection used by read query
+	handle *sqlx.DB
+
+	fingerprintOnce sync.Once
+	fp              string
+}
+
+// fingerprint return the fingerprint of the query, the fingerprint is only computed once
+func (q *queryInfo) fingerprint() string {
+	q.fingerprintOnce.Do(func() {
+		q.fp = Fingerprint(q.query)
+	})
+	return q.fp
+}
+
+// run the query function, all query in sqldb should go through this function
+func (db *DB) run(ctx context.Context, q *queryInfo, fn func(ctx context.Context) error) error {
+	if err := db.checkMutation(ctx, q.query); err != nil {
+		return err
+	}
+	if err := db.checkAllowlist(q); err != nil {
+		return err
+	}
+	db.countRepeatedQuery(ctx, q)
+
+	release, err := acquireConcurrency(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if db.opts.CircuitBreaker.enabled() {
+		if err := db.breakers.allow(q.fingerprint()); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancelTimeout := db.withDefaultTimeout(ctx, q.target)
+	// rows is read by the caller after run return, so the timeout is only released when expired
+	if !q.rows {
+		defer cancelTimeout()
+	}
+
+	ctx, done := db.inflight.track(ctx, !q.rows)
+	defer done()
+
+	db.taps.emit("start", q, 0, nil)
+	start := time.Now()
+	err = db.call(ctx, q, fn)
+	duration := time.Since(start)
+	db.taps.emit("end", q, duration, err)
+	if q.write && err == nil {
+		markWritten(ctx)
+	}
+	err = db.constraintError(err)
+	if q.write && q.target == targetLeader {
+		db.recordLeaderWrite(err)
+	}
+	if db.opts.CircuitBreaker.enabled() {
+		db.breakers.record(db.opts.CircuitBreaker, q.fingerprint(), duration, err)
+	}
+	observeQuery(ctx, q, duration, err)
+	db.logSlowQuery(ctx, q, duration, err)
+	if err == nil && db.shouldExplain(ctx, q) {
+		db.logQueryPlan(ctx, q)
+	}
+	if err == nil && db.shouldSampleResourceUsage(q) {
+		go db.sampleResourceUsage(q)
+	}
+	if err != nil && db.opts.DiagnoseSchemaErrors && db.opts.Logger != nil {
+		if table, ok := undefinedSchemaTable(err, q.query); ok {
+			go db.diagnoseSchemaError(q, err, table)
+		}
+	}
+	return err
+}
@@ -0,0 +1,59 @@
+package sqldb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/albertwidi/go-project-example/internal/pkg/log/logger"
+)
+
+// defaultRepeatedQueryThreshold is the number of identical query in a request before it is logged
+const defaultRepeatedQueryThreshold = 10
+
+type queryCounterKey struct{}
+
+// queryCounter count identical query by fingerprint and arguments
+type queryCounter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// WithQueryCounter return a context that count identical query, the same fingerprint with the same arguments
+// a warning is logged when an identical query run Options.RepeatedQueryThreshold times using the context,
+// this usually mean a missing cache or a query in a loop. Wrap the request context once at the beginning of the request
+func WithQueryCounter(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(queryCounterKey{}).(*queryCounter); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, queryCounterKey{}, &queryCounter{counts: make(map[string]int)})
+}
+
+// increment the count of the query and return the new count
+func (qc *queryCounter) increment(q *queryInfo) int {
+	key := q.fingerprint() + fmt.Sprintf("%#v", q.args)
+	qc.mu.Lock()
+	defer qc.mu.Unlock()
+	qc.counts[key]++
+	return qc.counts[key]
+}
+
+// countRepeatedQuery count the query in the context query counter, and log when the count reach the threshold
+// the warning is only logged once for each identical query
+func (db *DB) countRepeatedQuery(ctx context.Context, q *queryInfo) {
+	qc, ok := ctx.Value(queryCounterKey{}).(*queryCounter)
+	if !ok || db.opts.Logger == nil {
+		return
+	}
+	threshold := db.opts.RepeatedQueryThreshold
+	if threshold <= 0 {
+		threshold = defaultRepeatedQueryThreshold
+	}
+	if count := qc.increment(q); count == threshold {
+		db.opts.Logger.Warnw("sqldb: identical query repeated in one request", logger.KV{
+			"fingerprint": q.fingerprint(),
+			"operation":   operationName(ctx),
+			"count":       count,
+		})
+	}
+}
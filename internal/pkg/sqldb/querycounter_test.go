@@ -0,0 +1,57 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+)
+
+func TestWithQueryCounter(t *testing.T) {
+	t.Parallel()
+
+	l := &fakeLogger{}
+	db, _, follower := newFakeDB(t, WithLogger(l), WithRepeatedQueryThreshold(5))
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}}
+	})
+
+	const query = "SELECT id FROM users WHERE id = ?"
+	ctx := WithQueryCounter(context.Background())
+	var id int
+	// the same query with different arguments is not repeated
+	for i := 0; i < 10; i++ {
+		if err := db.GetContext(ctx, &id, query, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if l.contains("identical query repeated") {
+		t.Fatal("expecting no warning for query with different arguments")
+	}
+
+	for i := 0; i < 4; i++ {
+		if err := db.GetContext(ctx, &id, query, 100); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if l.contains("identical query repeated") {
+		t.Fatal("expecting no warning before the threshold")
+	}
+	if err := db.GetContext(ctx, &id, query, 100); err != nil {
+		t.Fatal(err)
+	}
+	if !l.contains("identical query repeated") || !l.contains("count:5") {
+		t.Error("expecting warning when the identical query reach the threshold")
+	}
+
+	// query without the counter context is not counted
+	l = &fakeLogger{}
+	db.opts.Logger = l
+	for i := 0; i < 10; i++ {
+		if err := db.GetContext(context.Background(), &id, query, 200); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if l.contains("identical query repeated") {
+		t.Error("expecting query without counter context to not be counted")
+	}
+}
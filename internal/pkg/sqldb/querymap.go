@@ -0,0 +1,51 @@
+package sqldb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// QueryMap2 scan two-column result into dest that must be a pointer to map, for example *map[string]int64
+// the first column is the key and the second column is the value, a nil map is allocated
+// error is returned when the result doesn't have exactly two columns, or the same key is returned twice
+func (db *DB) QueryMap2(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	value := reflect.ValueOf(dest)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Map {
+		return fmt.Errorf("sqldb: QueryMap2 destination must be a pointer to a map, got %T", dest)
+	}
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	if len(columns) != 2 {
+		return fmt.Errorf("sqldb: QueryMap2 expecting 2 columns, got %d", len(columns))
+	}
+
+	m := value.Elem()
+	if m.IsNil() {
+		m.Set(reflect.MakeMap(m.Type()))
+	}
+	for rows.Next() {
+		key := reflect.New(m.Type().Key())
+		elem := reflect.New(m.Type().Elem())
+		if err := rows.Scan(key.Interface(), elem.Interface()); err != nil {
+			return err
+		}
+		if m.MapIndex(key.Elem()).IsValid() {
+			return fmt.Errorf("sqldb: QueryMap2 duplicate key %v", key.Elem().Interface())
+		}
+		m.SetMapIndex(key.Elem(), elem.Elem())
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return rows.Close()
+}
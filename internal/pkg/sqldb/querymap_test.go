@@ -0,0 +1,44 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+)
+
+func TestQueryMap2(t *testing.T) {
+	t.Parallel()
+
+	db, _, follower := newFakeDB(t)
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		switch query {
+		case "SELECT status, count(*) FROM orders GROUP BY status":
+			return fakeResult{columns: []string{"status", "count"}, rows: [][]driver.Value{{"paid", int64(3)}, {"pending", int64(5)}}}
+		case "SELECT status, count(*) FROM orders GROUP BY status, currency":
+			return fakeResult{columns: []string{"status", "count"}, rows: [][]driver.Value{{"paid", int64(3)}, {"paid", int64(1)}}}
+		}
+		return fakeResult{columns: []string{"status", "currency", "count"}, rows: [][]driver.Value{{"paid", "IDR", int64(3)}}}
+	})
+
+	var counts map[string]int64
+	if err := db.QueryMap2(context.Background(), &counts, "SELECT status, count(*) FROM orders GROUP BY status"); err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string]int64{"paid": 3, "pending": 5}
+	if !reflect.DeepEqual(counts, expect) {
+		t.Errorf("expecting %v, got %v", expect, counts)
+	}
+
+	var duplicated map[string]int64
+	if err := db.QueryMap2(context.Background(), &duplicated, "SELECT status, count(*) FROM orders GROUP BY status, currency"); err == nil {
+		t.Error("expecting duplicate key error")
+	}
+	var wrongColumns map[string]int64
+	if err := db.QueryMap2(context.Background(), &wrongColumns, "SELECT status, currency, count(*) FROM orders GROUP BY status, currency"); err == nil {
+		t.Error("expecting column count error")
+	}
+	if err := db.QueryMap2(context.Background(), counts, "SELECT status, count(*) FROM orders GROUP BY status"); err == nil {
+		t.Error("expecting destination error for non-pointer map")
+	}
+}
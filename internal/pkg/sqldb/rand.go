@@ -0,0 +1,35 @@
+package sqldb
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// lockedRand is a rand that is safe for concurrent use, rand.Rand from a source is not
+type lockedRand struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+// newLockedRand return rand of src, a source seeded by the current time is used when src is nil
+func newLockedRand(src rand.Source) *lockedRand {
+	if src == nil {
+		src = rand.NewSource(time.Now().UnixNano())
+	}
+	return &lockedRand{r: rand.New(src)}
+}
+
+// Int63n return a non-negative random number in [0,n)
+func (lr *lockedRand) Int63n(n int64) int64 {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+	return lr.r.Int63n(n)
+}
+
+// Float64 return a random number in [0.0,1.0)
+func (lr *lockedRand) Float64() float64 {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+	return lr.r.Float64()
+}
@@ -0,0 +1,70 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func TestRandSource(t *testing.T) {
+	t.Parallel()
+
+	// record the transaction retry backoff and resource usage sampling of db with the source
+	record := func(src rand.Source) ([]time.Duration, []bool) {
+		db, leader, _ := newFakeDB(t, WithRandSource(src), WithResourceUsageSampling(0.5), WithLogger(&fakeLogger{}))
+		db.driver = "postgres"
+		leader.setHandler(func(ctx context.Context, q string, args []driver.NamedValue) fakeResult {
+			if q == "UPDATE accounts SET balance = 0" {
+				return fakeResult{err: &pq.Error{Code: "40P01"}}
+			}
+			return fakeResult{}
+		})
+
+		var backoffs []time.Duration
+		clock := &fakeClock{now: time.Now()}
+		retry := TxRetryOptions{
+			BaseBackoff: time.Millisecond * 100,
+			MaxBackoff:  time.Second,
+			MaxTotal:    time.Second * 10,
+			now:         clock.Now,
+			sleep: func(ctx context.Context, d time.Duration) error {
+				backoffs = append(backoffs, d)
+				clock.Add(d + time.Millisecond*100)
+				return nil
+			},
+		}
+		db.WithTransactionRetry(context.Background(), nil, retry, func(ctx context.Context, tx *Tx) error {
+			_, err := tx.ExecContext(ctx, "UPDATE accounts SET balance = 0")
+			return err
+		})
+
+		reader, _ := db.reader(context.Background())
+		samples := make([]bool, 20)
+		for i := range samples {
+			samples[i] = db.shouldSampleResourceUsage(&queryInfo{query: "SELECT 1", handle: reader})
+		}
+		return backoffs, samples
+	}
+
+	backoffs1, samples1 := record(rand.NewSource(42))
+	backoffs2, samples2 := record(rand.NewSource(42))
+	if len(backoffs1) < 2 {
+		t.Fatalf("expecting more than one retry, got %d", len(backoffs1))
+	}
+	if !reflect.DeepEqual(backoffs1, backoffs2) {
+		t.Errorf("expecting the same backoff with the same seed, got %v and %v", backoffs1, backoffs2)
+	}
+	if !reflect.DeepEqual(samples1, samples2) {
+		t.Errorf("expecting the same sampling with the same seed, got %v and %v", samples1, samples2)
+	}
+
+	backoffs3, _ := record(rand.NewSource(7))
+	if reflect.DeepEqual(backoffs1, backoffs3) {
+		t.Error("expecting different backoff with different seed")
+	}
+}
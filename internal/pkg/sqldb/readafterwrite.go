@@ -0,0 +1,43 @@
+package sqldb
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type afterWriteKey struct{}
+
+// AfterWrite return a context that route all reads to leader once any write happened using the context
+// this guarantee read-your-writes within a request, wrap the request context once at the beginning of the request
+func (db *DB) AfterWrite(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(afterWriteKey{}).(*int32); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, afterWriteKey{}, new(int32))
+}
+
+// markWritten mark the context that a write has happened
+func markWritten(ctx context.Context) {
+	if flag, ok := ctx.Value(afterWriteKey{}).(*int32); ok {
+		atomic.StoreInt32(flag, 1)
+	}
+}
+
+// written return true if a write has happened using the context
+func written(ctx context.Context) bool {
+	flag, ok := ctx.Value(afterWriteKey{}).(*int32)
+	return ok && atomic.LoadInt32(flag) == 1
+}
+
+// GetFromLeader get a row from leader regardless of the read routing, for example to read the row right after a write
+// the row is read from the unit of work transaction when it is began
+func (db *DB) GetFromLeader(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	// handle is only set when the read use the leader pool, the transaction cannot be explained or sampled
+	get, handle := db.leader.GetContext, db.leader
+	if tx, ok, _ := db.unitOfWorkTx(ctx, false); ok {
+		get, handle = tx.GetContext, nil
+	}
+	return db.run(ctx, &queryInfo{query: query, args: args, target: targetLeader, handle: handle}, func(ctx context.Context) error {
+		return get(ctx, dest, db.withTimeoutComment(ctx, query), args...)
+	})
+}
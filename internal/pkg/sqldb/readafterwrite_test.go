@@ -0,0 +1,114 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAfterWrite(t *testing.T) {
+	t.Parallel()
+
+	db, leader, follower := newFakeDB(t)
+	handler := func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}, rowsAffected: 1}
+	}
+	leader.setHandler(handler)
+	follower.setHandler(handler)
+
+	const query = "SELECT id FROM users WHERE id = ?"
+	var id int
+
+	// read-only request read from follower
+	readOnly := db.AfterWrite(context.Background())
+	if err := db.GetContext(readOnly, &id, query, 1); err != nil {
+		t.Fatal(err)
+	}
+	if follower.count(query) != 1 || leader.count(query) != 0 {
+		t.Error("expecting read-only request to read from follower")
+	}
+
+	// request with write read from leader after the write
+	ctx := db.AfterWrite(context.Background())
+	if err := db.GetContext(ctx, &id, query, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, "UPDATE users SET name = ? WHERE id = ?", "name", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.GetContext(ctx, &id, query, 1); err != nil {
+		t.Fatal(err)
+	}
+	if follower.count(query) != 2 || leader.count(query) != 1 {
+		t.Error("expecting read after write to read from leader")
+	}
+}
+
+func TestGetFromLeader(t *testing.T) {
+	t.Parallel()
+
+	db, leader, follower := newFakeDB(t)
+	handler := func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}}
+	}
+	leader.setHandler(handler)
+	follower.setHandler(handler)
+
+	const query = "SELECT id FROM users WHERE id = ?"
+	var id int
+	// the read go to leader even with eventual consistency
+	if err := db.GetFromLeader(WithConsistency(context.Background(), Eventual), &id, query, 1); err != nil {
+		t.Fatal(err)
+	}
+	if leader.count(query) != 1 || follower.count(query) != 0 {
+		t.Error("expecting GetFromLeader to read from leader")
+	}
+	if id != 1 {
+		t.Errorf("expecting 1, got %d", id)
+	}
+}
+
+func TestGetFromLeaderInTransactionNotSampled(t *testing.T) {
+	t.Parallel()
+
+	l := &fakeLogger{}
+	db, leader, _ := newFakeDB(t, WithLogger(l), WithResourceUsageSampling(1))
+	db.driver = "postgres"
+	const plan = `[{"Plan": {"Actual Rows": 1}, "Execution Time": 1}]`
+	leader.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		if strings.HasPrefix(query, "EXPLAIN") {
+			return fakeResult{columns: []string{"QUERY PLAN"}, rows: [][]driver.Value{{[]byte(plan)}}}
+		}
+		return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}}
+	})
+
+	var id int
+	err := db.WithTransaction(WithExplain(context.Background()), nil, func(ctx context.Context, tx *Tx) error {
+		return db.GetFromLeader(ctx, &id, "SELECT id FROM users WHERE id = $1 FOR UPDATE", 1)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the read outside the transaction is sampled, so the sampler has run
+	if err := db.GetFromLeader(context.Background(), &id, "SELECT id FROM users WHERE id = $1", 1); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second * 5)
+	for !l.contains("sqldb: query resource usage") {
+		if time.Now().After(deadline) {
+			t.Fatal("expecting the read outside transaction to be sampled")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	time.Sleep(time.Millisecond * 50)
+	for _, q := range leader.Queries() {
+		if strings.HasPrefix(q, "EXPLAIN") && strings.Contains(q, "FOR UPDATE") {
+			t.Errorf("expecting the read in transaction to not be explained or sampled on the leader pool, got %s", q)
+		}
+	}
+	if l.contains("sqldb: query plan") {
+		t.Error("expecting the read in transaction to not be explained")
+	}
+}
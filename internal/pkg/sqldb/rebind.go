@@ -0,0 +1,54 @@
+package sqldb
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrQueryAlreadyBound is returned by RebindStrict when the query already use the driver placeholder
+var ErrQueryAlreadyBound = errors.New("sqldb: query already use the driver placeholder")
+
+// RebindStrict rebind the query like Rebind, but return ErrQueryAlreadyBound instead of the unchanged query
+// when the query already use the driver placeholder. Use this in query builder to catch query that is rebound twice
+func (db *DB) RebindStrict(query string) (string, error) {
+	bindType := sqlx.BindType(db.driver)
+	if alreadyBound(bindType, query) {
+		return "", ErrQueryAlreadyBound
+	}
+	return sqlx.Rebind(bindType, query), nil
+}
+
+// alreadyBound return true when query contain placeholder of bindType outside of quoted string
+func alreadyBound(bindType int, query string) bool {
+	var prefix string
+	switch bindType {
+	case sqlx.DOLLAR:
+		prefix = "$"
+	case sqlx.NAMED:
+		prefix = ":arg"
+	case sqlx.AT:
+		prefix = "@p"
+	default:
+		return false
+	}
+
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case strings.HasPrefix(query[i:], prefix) && len(query) > i+len(prefix):
+			if next := query[i+len(prefix)]; next >= '0' && next <= '9' {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package sqldb
+
+import (
+	"testing"
+)
+
+func TestRebind(t *testing.T) {
+	t.Parallel()
+
+	db, _, _ := newFakeDB(t)
+	db.driver = "postgres"
+
+	cases := []struct {
+		query  string
+		expect string
+	}{
+		{query: "SELECT * FROM users WHERE id = ? AND name = ?", expect: "SELECT * FROM users WHERE id = $1 AND name = $2"},
+		{query: "SELECT * FROM users WHERE id = $1 AND name = $2", expect: "SELECT * FROM users WHERE id = $1 AND name = $2"},
+		// already bound query with jsonb ? operator is not rebound again
+		{query: "SELECT * FROM products WHERE attributes ? 'color' AND id = $1", expect: "SELECT * FROM products WHERE attributes ? 'color' AND id = $1"},
+		// dollar in string literal is not a placeholder
+		{query: "SELECT * FROM prices WHERE label = '$1' AND id = ?", expect: "SELECT * FROM prices WHERE label = '$1' AND id = $1"},
+	}
+	for _, c := range cases {
+		if got := db.Rebind(db.Rebind(c.query)); got != c.expect {
+			t.Errorf("%s: expecting %s, got %s", c.query, c.expect, got)
+		}
+	}
+
+	query, err := db.RebindStrict("SELECT * FROM users WHERE id = ?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query != "SELECT * FROM users WHERE id = $1" {
+		t.Errorf("unexpected query %s", query)
+	}
+	if _, err := db.RebindStrict(query); err != ErrQueryAlreadyBound {
+		t.Errorf("expecting ErrQueryAlreadyBound, got %v", err)
+	}
+
+	db.driver = "mysql"
+	if query, err := db.RebindStrict("SELECT * FROM users WHERE id = ?"); err != nil || query != "SELECT * FROM users WHERE id = ?" {
+		t.Errorf("expecting mysql query to be unchanged, got %s %v", query, err)
+	}
+}
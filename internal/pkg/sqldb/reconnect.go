@@ -0,0 +1,33 @@
+package sqldb
+
+import (
+	"context"
+)
+
+// ReconnectFollower connect to newDSN and replace the follower with the name, the leader is not touched
+// the new follower keep the name, AZ and tier, and the old follower is closed after its in-flight queries are finished
+// the connection is opened with Options.FollowerConnectOptions
+func (db *DB) ReconnectFollower(ctx context.Context, name, newDSN string) error {
+	if _, err := db.followerByName(name); err != nil {
+		return err
+	}
+
+	newDB, err := Connect(ctx, db.driver, newDSN, db.opts.FollowerConnectOptions)
+	if err != nil {
+		return err
+	}
+
+	followers := db.followerStates()
+	named := make([]NamedFollower, len(followers))
+	for i, f := range followers {
+		named[i] = NamedFollower{Name: f.name, AZ: f.az, Tier: f.tier, DB: f.db}
+		if f.name == name {
+			named[i].DB = newDB
+		}
+	}
+	if err := db.SetNamedFollowers(named); err != nil {
+		Disconnect(newDB)
+		return err
+	}
+	return nil
+}
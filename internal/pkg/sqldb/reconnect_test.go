@@ -0,0 +1,101 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func TestReconnectFollower(t *testing.T) {
+	t.Parallel()
+
+	newServer, newFollower := newFakeServer(t, "replacement")
+	var connectedDSN string
+	connector := func(ctx context.Context, driver, dsn string) (*sqlx.DB, error) {
+		connectedDSN = dsn
+		return newFollower, nil
+	}
+	db, leader, oldServer := newFakeDB(t, WithFollowerConnectOptions(&ConnectOptions{Connector: connector}))
+	oldFollower := db.Follower()
+
+	handler := func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}}
+	}
+	oldServer.setHandler(handler)
+	newServer.setHandler(handler)
+
+	var (
+		wg   sync.WaitGroup
+		stop = make(chan struct{})
+		errs = make(chan error, 100)
+	)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				var id int
+				if err := db.Get(&id, "SELECT id FROM users"); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+
+	if err := db.ReconnectFollower(context.Background(), defaultFollowerName(0), "replica-2"); err != nil {
+		t.Fatal(err)
+	}
+	close(stop)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("unexpected error when reading: %v", err)
+	}
+	if connectedDSN != "replica-2" {
+		t.Errorf("expecting new dsn to be connected, got %s", connectedDSN)
+	}
+
+	before := len(oldServer.Queries())
+	for i := 0; i < 5; i++ {
+		var id int
+		if err := db.Get(&id, "SELECT id FROM users"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := newServer.count("SELECT id FROM users"); n < 5 {
+		t.Errorf("expecting new reads to use the new follower, got %d", n)
+	}
+	if n := len(oldServer.Queries()); n != before {
+		t.Errorf("expecting no read to the old follower, got %d more", n-before)
+	}
+	if followers := db.Followers(); len(followers) != 1 || followers[0] != newFollower {
+		t.Errorf("expecting follower to be replaced, got %v", followers)
+	}
+	if err := db.Leader().Ping(); err != nil {
+		t.Errorf("expecting leader to be untouched, got %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for oldFollower.Ping() == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("expecting old follower to be closed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if n := leader.count("SELECT id FROM users"); n != 0 {
+		t.Errorf("expecting no read to leader, got %d", n)
+	}
+
+	if err := db.ReconnectFollower(context.Background(), "unknown", "replica-3"); err == nil {
+		t.Error("expecting error for unknown follower")
+	}
+}
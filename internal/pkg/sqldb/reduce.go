@@ -0,0 +1,77 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	_scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	_timeType    = reflect.TypeOf(time.Time{})
+)
+
+// Reduce scan each row of the query and fold it into acc using reducer, so the rows is never materialized, for example:
+//
+//	total := int64(0)
+//	err := db.Reduce(ctx, &total, func(total int64, o Order) int64 {
+//		return total + o.Amount*o.Quantity
+//	}, "SELECT amount, quantity FROM orders WHERE created_at > ?", since)
+//
+// acc must be a pointer to A with the initial value, and reducer must be func(A, T) A. T is scanned like Get,
+// struct by the column name and other type by the single column. acc is only set when all rows is reduced
+func (db *DB) Reduce(ctx context.Context, acc interface{}, reducer interface{}, query string, args ...interface{}) error {
+	accValue := reflect.ValueOf(acc)
+	if accValue.Kind() != reflect.Ptr || accValue.IsNil() {
+		return fmt.Errorf("sqldb: Reduce accumulator must be a non-nil pointer, got %T", acc)
+	}
+	accType := accValue.Elem().Type()
+	fn := reflect.ValueOf(reducer)
+	if !fn.IsValid() || fn.Kind() != reflect.Func || fn.IsNil() {
+		return fmt.Errorf("sqldb: Reduce reducer must be func(%s, T) %s, got %T", accType, accType, reducer)
+	}
+	if ft := fn.Type(); ft.NumIn() != 2 || ft.NumOut() != 1 || ft.In(0) != accType || ft.Out(0) != accType {
+		return fmt.Errorf("sqldb: Reduce reducer must be func(%s, T) %s, got %T", accType, accType, reducer)
+	}
+	rowType := fn.Type().In(1)
+	scanStruct := rowType.Kind() == reflect.Struct && rowType != _timeType && !reflect.PtrTo(rowType).Implements(_scannerType)
+
+	reader, info, err := db.readQueryer(ctx, query, args)
+	if err != nil {
+		return err
+	}
+	return db.run(ctx, info, func(ctx context.Context) error {
+		queryRows := func(ctx context.Context) (*sqlx.Rows, error) {
+			return reader.QueryxContext(ctx, db.withTimeoutComment(ctx, query), args...)
+		}
+		return queryScan(ctx, db.opts.ScanTimeout, queryRows, func(rows *sqlx.Rows) error {
+			var (
+				err    error
+				result = accValue.Elem()
+				row    = reflect.New(rowType)
+				zero   = reflect.Zero(rowType)
+			)
+			for rows.Next() {
+				row.Elem().Set(zero)
+				if scanStruct {
+					err = rows.StructScan(row.Interface())
+				} else {
+					err = rows.Scan(row.Interface())
+				}
+				if err != nil {
+					return err
+				}
+				result = fn.Call([]reflect.Value{result, row.Elem()})[0]
+			}
+			if err := rows.Err(); err != nil {
+				return err
+			}
+			accValue.Elem().Set(result)
+			return nil
+		})
+	})
+}
@@ -0,0 +1,84 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+func TestReduce(t *testing.T) {
+	t.Parallel()
+
+	const n = 10000
+	db, _, follower := newFakeDB(t)
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		if query == "SELECT amount FROM orders WHERE broken = 1" {
+			return fakeResult{columns: []string{"amount"}, rows: [][]driver.Value{{int64(1)}, {"not a number"}}}
+		}
+		res := fakeResult{columns: []string{"amount", "quantity"}}
+		if query == "SELECT amount FROM orders" {
+			res.columns = res.columns[:1]
+		}
+		for i := int64(1); i <= n; i++ {
+			row := []driver.Value{i, int64(2)}
+			res.rows = append(res.rows, row[:len(res.columns)])
+		}
+		return res
+	})
+
+	total := int64(0)
+	err := db.Reduce(context.Background(), &total, func(total, amount int64) int64 {
+		return total + amount
+	}, "SELECT amount FROM orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != n*(n+1)/2 {
+		t.Errorf("expecting %d, got %d", n*(n+1)/2, total)
+	}
+
+	type order struct {
+		Amount   int64 `db:"amount"`
+		Quantity int64 `db:"quantity"`
+	}
+	type summary struct {
+		count, value int64
+	}
+	var s summary
+	err = db.Reduce(context.Background(), &s, func(s summary, o order) summary {
+		return summary{count: s.count + 1, value: s.value + o.Amount*o.Quantity}
+	}, "SELECT amount, quantity FROM orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.count != n || s.value != n*(n+1) {
+		t.Errorf("unexpected summary %+v", s)
+	}
+
+	// the accumulator is not changed when the scan is failed
+	total = 42
+	err = db.Reduce(context.Background(), &total, func(total, amount int64) int64 {
+		return total + amount
+	}, "SELECT amount FROM orders WHERE broken = 1")
+	if err == nil || total != 42 {
+		t.Errorf("expecting scan error without changing the accumulator, got %d %v", total, err)
+	}
+
+	if err := db.Reduce(context.Background(), &total, func(total int, amount int64) int { return 0 }, "SELECT amount FROM orders"); err == nil {
+		t.Error("expecting error for reducer with different accumulator type")
+	}
+	if err := db.Reduce(context.Background(), total, func(total, amount int64) int64 { return 0 }, "SELECT amount FROM orders"); err == nil {
+		t.Error("expecting error for non-pointer accumulator")
+	}
+	if err := db.Reduce(context.Background(), &total, errors.New("not a function"), "SELECT amount FROM orders"); err == nil {
+		t.Error("expecting error for non-function reducer")
+	}
+	if err := db.Reduce(context.Background(), &total, nil, "SELECT amount FROM orders"); err == nil {
+		t.Error("expecting error for nil reducer")
+	}
+	var reducer func(total, amount int64) int64
+	if err := db.Reduce(context.Background(), &total, reducer, "SELECT amount FROM orders"); err == nil {
+		t.Error("expecting error for nil function reducer")
+	}
+}
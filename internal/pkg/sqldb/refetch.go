@@ -0,0 +1,12 @@
+package sqldb
+
+import "context"
+
+// ExecAndRefetch execute the query in leader, then refetch the row into dest from leader
+// the row is read from leader to include the changes by triggers without replication lag
+func (db *DB) ExecAndRefetch(ctx context.Context, dest interface{}, execQuery string, execArgs []interface{}, refetchQuery string, refetchArgs []interface{}) error {
+	if _, err := db.ExecContext(ctx, execQuery, execArgs...); err != nil {
+		return err
+	}
+	return db.GetFromLeader(ctx, dest, refetchQuery, refetchArgs...)
+}
@@ -0,0 +1,55 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestExecAndRefetch(t *testing.T) {
+	t.Parallel()
+
+	db, leader, follower := newFakeDB(t)
+	updatedAt := time.Date(2019, 10, 17, 7, 0, 0, 0, time.UTC)
+
+	var (
+		mu   sync.Mutex
+		name = "old"
+		// updated_at is set by trigger on update
+		triggeredAt time.Time
+	)
+	leader.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		mu.Lock()
+		defer mu.Unlock()
+		switch query {
+		case "UPDATE users SET name = ? WHERE id = ?":
+			name = args[0].Value.(string)
+			triggeredAt = updatedAt
+			return fakeResult{rowsAffected: 1}
+		case "SELECT name, updated_at FROM users WHERE id = ?":
+			return fakeResult{columns: []string{"name", "updated_at"}, rows: [][]driver.Value{{name, triggeredAt}}}
+		}
+		return fakeResult{}
+	})
+
+	type user struct {
+		Name      string    `db:"name"`
+		UpdatedAt time.Time `db:"updated_at"`
+	}
+	var u user
+	err := db.ExecAndRefetch(context.Background(), &u,
+		"UPDATE users SET name = ? WHERE id = ?", []interface{}{"new", 1},
+		"SELECT name, updated_at FROM users WHERE id = ?", []interface{}{1},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Name != "new" || !u.UpdatedAt.Equal(updatedAt) {
+		t.Errorf("expecting refetched row to include trigger output, got %+v", u)
+	}
+	if len(follower.Queries()) != 0 {
+		t.Errorf("expecting refetch to not read from follower, got %v", follower.Queries())
+	}
+}
@@ -0,0 +1,101 @@
+package sqldb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/albertwidi/go-project-example/internal/pkg/log/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+// resourceUsageTimeout is the maximum duration of the out-of-band EXPLAIN query
+const resourceUsageTimeout = time.Second * 30
+
+var errResourceUsageNoPlan = errors.New("sqldb: explain return no plan")
+
+// ResourceUsage of a sampled query, taken from the root node of EXPLAIN (ANALYZE, BUFFERS) plan
+type ResourceUsage struct {
+	Fingerprint      string
+	Target           string
+	SharedHitBlocks  int64
+	SharedReadBlocks int64
+	ActualRows       int64
+	ExecutionTime    time.Duration
+}
+
+type explainPlan struct {
+	Plan struct {
+		SharedHitBlocks  int64   `json:"Shared Hit Blocks"`
+		SharedReadBlocks int64   `json:"Shared Read Blocks"`
+		ActualRows       float64 `json:"Actual Rows"`
+	} `json:"Plan"`
+	ExecutionTime float64 `json:"Execution Time"`
+}
+
+// shouldSampleResourceUsage return true when the query should be sampled
+// only successful SELECT on postgres is sampled, because EXPLAIN ANALYZE execute the query
+func (db *DB) shouldSampleResourceUsage(q *queryInfo) bool {
+	if db.opts.ResourceUsageSampleRate <= 0 || db.opts.Logger == nil {
+		return false
+	}
+	if db.driver != "postgres" || q.write || q.handle == nil {
+		return false
+	}
+	fields := strings.Fields(stripQuery(q.query))
+	if len(fields) == 0 || !strings.EqualFold(fields[0], "SELECT") {
+		return false
+	}
+	return db.opts.ResourceUsageSampleRate >= 1 || db.rand.Float64() < db.opts.ResourceUsageSampleRate
+}
+
+// sampleResourceUsage run the query again with EXPLAIN (ANALYZE, BUFFERS) and log the resource usage
+func (db *DB) sampleResourceUsage(q *queryInfo) {
+	ctx, cancel := context.WithTimeout(context.Background(), resourceUsageTimeout)
+	defer cancel()
+
+	usage, err := explainResourceUsage(ctx, q.handle, q.query, q.args...)
+	if err != nil {
+		db.opts.Logger.Warnw("sqldb: failed to sample resource usage", logger.KV{
+			"fingerprint": q.fingerprint(),
+			"error":       err.Error(),
+		})
+		return
+	}
+	usage.Fingerprint = q.fingerprint()
+	usage.Target = q.target
+
+	db.opts.Logger.Infow("sqldb: query resource usage", logger.KV{
+		"fingerprint":        usage.Fingerprint,
+		"target":             usage.Target,
+		"shared_hit_blocks":  usage.SharedHitBlocks,
+		"shared_read_blocks": usage.SharedReadBlocks,
+		"actual_rows":        usage.ActualRows,
+		"execution_time":     usage.ExecutionTime.String(),
+	})
+}
+
+func explainResourceUsage(ctx context.Context, handle *sqlx.DB, query string, args ...interface{}) (ResourceUsage, error) {
+	var out []byte
+	if err := handle.QueryRowxContext(ctx, "EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) "+query, args...).Scan(&out); err != nil {
+		return ResourceUsage{}, err
+	}
+
+	var plans []explainPlan
+	if err := json.Unmarshal(out, &plans); err != nil {
+		return ResourceUsage{}, err
+	}
+	if len(plans) == 0 {
+		return ResourceUsage{}, errResourceUsageNoPlan
+	}
+
+	plan := plans[0]
+	return ResourceUsage{
+		SharedHitBlocks:  plan.Plan.SharedHitBlocks,
+		SharedReadBlocks: plan.Plan.SharedReadBlocks,
+		ActualRows:       int64(plan.Plan.ActualRows),
+		ExecutionTime:    time.Duration(plan.ExecutionTime * float64(time.Millisecond)),
+	}, nil
+}
@@ -0,0 +1,108 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResourceUsageSampling(t *testing.T) {
+	t.Parallel()
+
+	l := &fakeLogger{}
+	db, _, follower := newFakeDB(t, WithLogger(l), WithResourceUsageSampling(1))
+	db.driver = "postgres"
+
+	const plan = `[{"Plan": {"Node Type": "Seq Scan", "Actual Rows": 3, "Shared Hit Blocks": 12, "Shared Read Blocks": 4}, "Execution Time": 1.5}]`
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		if strings.HasPrefix(query, "EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) ") {
+			return fakeResult{columns: []string{"QUERY PLAN"}, rows: [][]driver.Value{{[]byte(plan)}}}
+		}
+		return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}, {int64(2)}, {int64(3)}}}
+	})
+
+	var ids []int64
+	if err := db.SelectContext(context.Background(), &ids, "SELECT id FROM users WHERE status = $1", 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(context.Background(), "UPDATE users SET status = 2"); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []string{
+		"fingerprint:select id from users where status = ?",
+		"shared_hit_blocks:12",
+		"shared_read_blocks:4",
+		"actual_rows:3",
+		"execution_time:1.5ms",
+	}
+	deadline := time.Now().Add(time.Second * 5)
+	for !l.contains("sqldb: query resource usage") {
+		if time.Now().After(deadline) {
+			t.Fatal("expecting resource usage to be logged")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	for _, e := range expect {
+		if !l.contains(e) {
+			t.Errorf("expecting resource usage log to contain %s, got %v", e, l.logs)
+		}
+	}
+	if n := follower.count("EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) SELECT id FROM users WHERE status = $1"); n != 1 {
+		t.Errorf("expecting 1 explain query, got %d", n)
+	}
+}
+
+func TestShouldSampleResourceUsage(t *testing.T) {
+	t.Parallel()
+
+	db, _, _ := newFakeDB(t, WithLogger(&fakeLogger{}), WithResourceUsageSampling(1))
+	reader, _ := db.reader(context.Background())
+
+	cases := []struct {
+		name   string
+		driver string
+		q      *queryInfo
+		expect bool
+	}{
+		{
+			name:   "select on postgres",
+			driver: "postgres",
+			q:      &queryInfo{query: "SELECT 1", handle: reader},
+			expect: true,
+		},
+		{
+			name:   "select with leading comment",
+			driver: "postgres",
+			q:      &queryInfo{query: "/* list users */ select 1", handle: reader},
+			expect: true,
+		},
+		{
+			name:   "mysql",
+			driver: "mysql",
+			q:      &queryInfo{query: "SELECT 1", handle: reader},
+			expect: false,
+		},
+		{
+			name:   "write",
+			driver: "postgres",
+			q:      &queryInfo{query: "SELECT 1", handle: reader, write: true},
+			expect: false,
+		},
+		{
+			name:   "not a select",
+			driver: "postgres",
+			q:      &queryInfo{query: "WITH d AS (DELETE FROM users RETURNING id) SELECT id FROM d", handle: reader},
+			expect: false,
+		},
+	}
+
+	for _, c := range cases {
+		db.driver = c.driver
+		if got := db.shouldSampleResourceUsage(c.q); got != c.expect {
+			t.Errorf("%s: expecting %v, got %v", c.name, c.expect, got)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package sqldb
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ResultProcessor transform the row scanned by QueryMaps before it is returned, for example to redact PII column
+// the row is keyed by column name, error stop the query and is returned to the caller
+type ResultProcessor func(ctx context.Context, row map[string]interface{}) error
+
+// resultProcessors is the registered ResultProcessor by scope
+type resultProcessors struct {
+	mu     sync.RWMutex
+	scopes map[string][]ResultProcessor
+}
+
+type resultScopeKey struct{}
+
+// AddResultProcessor register the processor to the scope, the processor only run on query using context with the scope
+// processors of the same scope run in the order they are added
+func (db *DB) AddResultProcessor(scope string, processor ResultProcessor) {
+	db.processors.mu.Lock()
+	defer db.processors.mu.Unlock()
+	if db.processors.scopes == nil {
+		db.processors.scopes = make(map[string][]ResultProcessor)
+	}
+	db.processors.scopes[scope] = append(db.processors.scopes[scope], processor)
+}
+
+// WithResultScope run the result processors of the scopes on dynamic query using the context, for example "support"
+func WithResultScope(ctx context.Context, scopes ...string) context.Context {
+	scopes = append(resultScopesFromContext(ctx), scopes...)
+	return context.WithValue(ctx, resultScopeKey{}, scopes)
+}
+
+func resultScopesFromContext(ctx context.Context) []string {
+	scopes, _ := ctx.Value(resultScopeKey{}).([]string)
+	// copy so appending in WithResultScope doesn't share the array with the parent context
+	return append([]string(nil), scopes...)
+}
+
+// resultProcessors return the processors of the context scopes
+func (db *DB) resultProcessors(ctx context.Context) []ResultProcessor {
+	scopes := resultScopesFromContext(ctx)
+	if len(scopes) == 0 {
+		return nil
+	}
+
+	db.processors.mu.RLock()
+	defer db.processors.mu.RUnlock()
+	var processors []ResultProcessor
+	for _, scope := range scopes {
+		processors = append(processors, db.processors.scopes[scope]...)
+	}
+	return processors
+}
+
+// QueryMaps scan each row of the result into a map of column name to the value returned by the driver
+// the result processors of the context scopes run on every row
+func (db *DB) QueryMaps(ctx context.Context, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	sqlRows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	rows := &sqlx.Rows{Rows: sqlRows}
+	defer rows.Close()
+
+	processors := db.resultProcessors(ctx)
+	var result []map[string]interface{}
+	for rows.Next() {
+		row := make(map[string]interface{})
+		if err := rows.MapScan(row); err != nil {
+			return nil, err
+		}
+		for _, process := range processors {
+			if err := process(ctx, row); err != nil {
+				return nil, err
+			}
+		}
+		result = append(result, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, rows.Close()
+}
@@ -0,0 +1,54 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResultProcessor(t *testing.T) {
+	t.Parallel()
+
+	const query = "SELECT id, email FROM users"
+	db, _, follower := newFakeDB(t)
+	follower.setHandler(func(ctx context.Context, q string, args []driver.NamedValue) fakeResult {
+		return fakeResult{columns: []string{"id", "email"}, rows: [][]driver.Value{{int64(1), "john@example.com"}, {int64(2), nil}}}
+	})
+	db.AddResultProcessor("support", func(ctx context.Context, row map[string]interface{}) error {
+		if email, ok := row["email"].(string); ok {
+			i := strings.Index(email, "@")
+			row["email"] = email[:1] + strings.Repeat("*", i-1) + email[i:]
+		}
+		return nil
+	})
+
+	rows, err := db.QueryMaps(WithResultScope(context.Background(), "support"), query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []map[string]interface{}{{"id": int64(1), "email": "j***@example.com"}, {"id": int64(2), "email": nil}}
+	if !reflect.DeepEqual(rows, expect) {
+		t.Errorf("expecting masked rows %v, got %v", expect, rows)
+	}
+
+	// processor is not run outside the scope
+	rows, err = db.QueryMaps(WithResultScope(context.Background(), "billing"), query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if email := rows[0]["email"]; email != "john@example.com" {
+		t.Errorf("expecting email to be unmasked outside the scope, got %v", email)
+	}
+
+	// error of processor is returned
+	errRedact := errors.New("redact failed")
+	db.AddResultProcessor("strict", func(ctx context.Context, row map[string]interface{}) error {
+		return errRedact
+	})
+	if _, err := db.QueryMaps(WithResultScope(context.Background(), "support", "strict"), query); err != errRedact {
+		t.Errorf("expecting processor error, got %v", err)
+	}
+}
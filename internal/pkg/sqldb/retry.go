@@ -0,0 +1,107 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strconv"
+
+	"github.com/albertwidi/go-project-example/internal/pkg/log/logger"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+// RetryDecision is the classification of a query error
+type RetryDecision struct {
+	Retryable bool
+	// Code is the driver error code, empty if the error is not a driver error
+	Code string
+	// Reason is the predicate that decided the classification
+	Reason string
+}
+
+// ClassifyRetry classify whether the query can be retried after err
+// deadlock, serialization failure, lock wait timeout and bad connection is retryable
+func ClassifyRetry(err error) RetryDecision {
+	var (
+		pqErr    *pq.Error
+		mysqlErr *mysql.MySQLError
+	)
+	switch {
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		return RetryDecision{Reason: "context_done"}
+	case errors.Is(err, driver.ErrBadConn):
+		return RetryDecision{Retryable: true, Reason: "bad_connection"}
+	case errors.As(err, &pqErr):
+		d := RetryDecision{Code: string(pqErr.Code), Reason: "postgres_not_retryable"}
+		switch pqErr.Code {
+		case "40001":
+			d.Retryable, d.Reason = true, "postgres_serialization_failure"
+		case "40P01":
+			d.Retryable, d.Reason = true, "postgres_deadlock"
+		}
+		return d
+	case errors.As(err, &mysqlErr):
+		d := RetryDecision{Code: strconv.Itoa(int(mysqlErr.Number)), Reason: "mysql_not_retryable"}
+		switch mysqlErr.Number {
+		case 1213:
+			d.Retryable, d.Reason = true, "mysql_deadlock"
+		case 1205:
+			d.Retryable, d.Reason = true, "mysql_lock_wait_timeout"
+		}
+		return d
+	}
+	return RetryDecision{Reason: "not_retryable"}
+}
+
+type noRetryKey struct{}
+
+// WithNoRetry disable the read retry, connect retry and transaction retry using the context regardless of the options
+// use this for operation that must not be executed more than once
+func WithNoRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRetryKey{}, true)
+}
+
+func noRetryFromContext(ctx context.Context) bool {
+	noRetry, _ := ctx.Value(noRetryKey{}).(bool)
+	return noRetry
+}
+
+// call the query function, read is retried up to Options.ReadRetries when the error is retryable
+// write is never retried as it might not be idempotent
+func (db *DB) call(ctx context.Context, q *queryInfo, fn func(ctx context.Context) error) error {
+	for attempt := 1; ; attempt++ {
+		err := db.injectFault(ctx, q)
+		if err == nil {
+			err = fn(ctx)
+		}
+		if err == nil || q.write || db.opts.ReadRetries <= 0 || noRetryFromContext(ctx) {
+			return err
+		}
+		if p, ok := db.policy(ctx); ok && !p.Retryable {
+			return err
+		}
+
+		decision := ClassifyRetry(err)
+		retry := decision.Retryable && attempt <= db.opts.ReadRetries
+		db.logRetryDecision(q, attempt, err, decision, retry)
+		if !retry {
+			return err
+		}
+	}
+}
+
+func (db *DB) logRetryDecision(q *queryInfo, attempt int, err error, decision RetryDecision, retry bool) {
+	if !db.opts.LogRetryDecisions || db.opts.Logger == nil {
+		return
+	}
+	db.opts.Logger.Infow("sqldb: retry classification", logger.KV{
+		"fingerprint": q.fingerprint(),
+		"attempt":     attempt,
+		"code":        decision.Code,
+		"retryable":   decision.Retryable,
+		"reason":      decision.Reason,
+		"retry":       retry,
+		"error":       err.Error(),
+	})
+}
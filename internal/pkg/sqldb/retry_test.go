@@ -0,0 +1,144 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+func TestClassifyRetry(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name   string
+		err    error
+		expect RetryDecision
+	}{
+		{name: "postgres deadlock", err: &pq.Error{Code: "40P01"}, expect: RetryDecision{Retryable: true, Code: "40P01", Reason: "postgres_deadlock"}},
+		{name: "postgres serialization", err: &pq.Error{Code: "40001"}, expect: RetryDecision{Retryable: true, Code: "40001", Reason: "postgres_serialization_failure"}},
+		{name: "postgres undefined table", err: &pq.Error{Code: "42P01"}, expect: RetryDecision{Code: "42P01", Reason: "postgres_not_retryable"}},
+		{name: "mysql deadlock", err: &mysql.MySQLError{Number: 1213}, expect: RetryDecision{Retryable: true, Code: "1213", Reason: "mysql_deadlock"}},
+		{name: "mysql syntax", err: &mysql.MySQLError{Number: 1064}, expect: RetryDecision{Code: "1064", Reason: "mysql_not_retryable"}},
+		{name: "bad connection", err: driver.ErrBadConn, expect: RetryDecision{Retryable: true, Reason: "bad_connection"}},
+		{name: "canceled", err: context.Canceled, expect: RetryDecision{Reason: "context_done"}},
+		{name: "other", err: errors.New("other"), expect: RetryDecision{Reason: "not_retryable"}},
+	}
+	for _, c := range cases {
+		if got := ClassifyRetry(c.err); got != c.expect {
+			t.Errorf("%s: expecting %+v, got %+v", c.name, c.expect, got)
+		}
+	}
+}
+
+func TestReadRetries(t *testing.T) {
+	t.Parallel()
+
+	l := &fakeLogger{}
+	db, _, follower := newFakeDB(t, WithLogger(l), WithReadRetries(2), WithRetryLogging())
+
+	var (
+		mu       sync.Mutex
+		failures = map[string]int{"SELECT deadlock": 1}
+	)
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		mu.Lock()
+		defer mu.Unlock()
+		switch query {
+		case "SELECT deadlock":
+			if failures[query] > 0 {
+				failures[query]--
+				return fakeResult{err: &pq.Error{Code: "40P01"}}
+			}
+		case "SELECT missing":
+			return fakeResult{err: &pq.Error{Code: "42P01"}}
+		}
+		return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}}
+	})
+
+	var id int
+	if err := db.GetContext(context.Background(), &id, "SELECT deadlock"); err != nil {
+		t.Fatalf("expecting deadlock to be retried, got %v", err)
+	}
+	if n := follower.count("SELECT deadlock"); n != 2 {
+		t.Errorf("expecting 2 attempts, got %d", n)
+	}
+	for _, s := range []string{"sqldb: retry classification", "code:40P01", "reason:postgres_deadlock", "retryable:true", "retry:true"} {
+		if !l.contains(s) {
+			t.Errorf("expecting log to contain %s", s)
+		}
+	}
+
+	if err := db.GetContext(context.Background(), &id, "SELECT missing"); err == nil {
+		t.Fatal("expecting error")
+	}
+	if n := follower.count("SELECT missing"); n != 1 {
+		t.Errorf("expecting non-retryable error to not be retried, got %d attempts", n)
+	}
+	for _, s := range []string{"code:42P01", "reason:postgres_not_retryable", "retryable:false"} {
+		if !l.contains(s) {
+			t.Errorf("expecting log to contain %s", s)
+		}
+	}
+}
+
+func TestWithNoRetry(t *testing.T) {
+	t.Parallel()
+
+	db, leader, follower := newFakeDB(t, WithReadRetries(3))
+	deadlock := func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{err: &pq.Error{Code: "40P01"}}
+	}
+	leader.setHandler(deadlock)
+	follower.setHandler(deadlock)
+	ctx := WithNoRetry(context.Background())
+
+	var id int
+	if err := db.GetContext(ctx, &id, "SELECT id FROM users"); err == nil {
+		t.Fatal("expecting error")
+	}
+	if n := follower.count("SELECT id FROM users"); n != 1 {
+		t.Errorf("expecting read not to be retried, got %d attempts", n)
+	}
+	// the read is still retried without the context
+	db.GetContext(context.Background(), &id, "SELECT id FROM users")
+	if n := follower.count("SELECT id FROM users") - 1; n != 4 {
+		t.Errorf("expecting read to be retried without no retry context, got %d attempts", n)
+	}
+
+	err := db.WithTransactionRetry(ctx, nil, TxRetryOptions{MaxTotal: time.Minute}, func(ctx context.Context, tx *Tx) error {
+		_, err := tx.ExecContext(ctx, "UPDATE users SET name = 'a' WHERE id = 1")
+		return err
+	})
+	if err == nil {
+		t.Fatal("expecting transaction error")
+	}
+	if n := leader.count("BEGIN"); n != 1 {
+		t.Errorf("expecting transaction not to be retried, got %d attempts", n)
+	}
+
+	attempts := 0
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+	_, err = Connect(ctx, fakeDriverName, "unused", &ConnectOptions{
+		Retry: 3,
+		Connector: func(ctx context.Context, driver, dsn string) (*sqlx.DB, error) {
+			attempts++
+			return nil, refused
+		},
+	})
+	if err == nil {
+		t.Fatal("expecting connect error")
+	}
+	if attempts != 1 {
+		t.Errorf("expecting connect not to be retried, got %d attempts", attempts)
+	}
+}
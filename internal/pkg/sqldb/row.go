@@ -0,0 +1,18 @@
+package sqldb
+
+import "database/sql"
+
+// Row is the result of QueryRowContext, like sql.Row the error of the query is deferred until Scan
+// query rejected by sqldb before it is executed, for example by the allowlist or circuit breaker, is returned by Scan
+type Row struct {
+	row *sql.Row
+	err error
+}
+
+// Scan copy the columns of the row into dest, sql.ErrNoRows is returned when the query return no row
+func (r *Row) Scan(dest ...interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.row.Scan(dest...)
+}
@@ -0,0 +1,112 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrScanTimeout is returned when reading the rows take longer than Options.ScanTimeout after the query is executed
+var ErrScanTimeout = errors.New("sqldb: scan timeout")
+
+// ScanTimeoutError wrap the error of the canceled scan when the scan timeout is reached
+type ScanTimeoutError struct {
+	Timeout time.Duration
+	Err     error
+}
+
+// Error return the scan timeout message with the scan error
+func (e *ScanTimeoutError) Error() string {
+	return fmt.Sprintf("%s after %s: %s", ErrScanTimeout, e.Timeout, e.Err)
+}
+
+// Unwrap return the scan error
+func (e *ScanTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// Is return true for ErrScanTimeout
+func (e *ScanTimeoutError) Is(target error) bool {
+	return target == ErrScanTimeout
+}
+
+// queryScan run the query and scan the rows, the query context is canceled when the scan is not finished within timeout
+// the scan deadline start after the query return the rows, so it is independent of the query timeout. Disabled when zero
+func queryScan(ctx context.Context, timeout time.Duration, query func(ctx context.Context) (*sqlx.Rows, error), scan func(rows *sqlx.Rows) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	rows, err := query(ctx)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var expired int32
+	if timeout > 0 {
+		timer := time.AfterFunc(timeout, func() {
+			atomic.StoreInt32(&expired, 1)
+			cancel()
+		})
+		defer timer.Stop()
+	}
+
+	err = scan(rows)
+	if closeErr := rows.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil && atomic.LoadInt32(&expired) == 1 {
+		return &ScanTimeoutError{Timeout: timeout, Err: err}
+	}
+	return err
+}
+
+// scanSelect scan all rows into the dest slice like sqlx.SelectContext
+func scanSelect(rows *sqlx.Rows, dest interface{}) error {
+	// sqlx.StructScan only scan into struct, so scalar destination is scanned by sqlx.SelectContext with the opened rows
+	return sqlx.SelectContext(context.Background(), openedRows{rows: rows}, dest, "")
+}
+
+// queryScanRows run the read query and scan the rows within Options.ScanTimeout
+// the phases is measured on a dedicated connection when timing is not nil, the statement cache is used otherwise
+func (db *DB) queryScanRows(ctx context.Context, reader *sqlx.DB, target string, timing *QueryTiming, scan func(rows *sqlx.Rows) error, query string, args ...interface{}) error {
+	if timing != nil {
+		return queryTimed(ctx, reader, timing, db.opts.ScanTimeout, scan, db.withTimeoutComment(ctx, query), args...)
+	}
+	if db.useStmtCache() {
+		stmt, release, err := db.cachedStmt(ctx, reader, target, query)
+		if err != nil {
+			return err
+		}
+		defer release()
+		return queryScan(ctx, db.opts.ScanTimeout, func(ctx context.Context) (*sqlx.Rows, error) {
+			return stmt.QueryxContext(ctx, args...)
+		}, scan)
+	}
+	return queryScan(ctx, db.opts.ScanTimeout, func(ctx context.Context) (*sqlx.Rows, error) {
+		return reader.QueryxContext(ctx, db.withTimeoutComment(ctx, query), args...)
+	}, scan)
+}
+
+// openedRows is sqlx.QueryerContext that return the rows that is already queried
+type openedRows struct {
+	rows *sqlx.Rows
+}
+
+func (o openedRows) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return o.rows.Rows, nil
+}
+
+func (o openedRows) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
+	return o.rows, nil
+}
+
+// QueryRowxContext is never called by sqlx.SelectContext
+func (o openedRows) QueryRowxContext(ctx context.Context, query string, args ...interface{}) *sqlx.Row {
+	return nil
+}
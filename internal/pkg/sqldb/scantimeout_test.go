@@ -0,0 +1,159 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func TestScanTimeout(t *testing.T) {
+	t.Parallel()
+
+	db, _, follower := newFakeDB(t, WithScanTimeout(time.Millisecond*50), WithDefaultTimeout(time.Second, time.Second))
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		res := fakeResult{columns: []string{"id"}}
+		for i := int64(1); i <= 10; i++ {
+			res.rows = append(res.rows, []driver.Value{i})
+		}
+		if query == "SELECT id FROM slow_network" {
+			res.rowDelay = time.Millisecond * 20
+		}
+		return res
+	})
+
+	var ids []int64
+	if err := db.SelectContext(context.Background(), &ids, "SELECT id FROM orders"); err != nil {
+		t.Fatalf("expecting fast scan to succeed, got %v", err)
+	}
+	if len(ids) != 10 {
+		t.Errorf("expecting 10 rows, got %d", len(ids))
+	}
+
+	// the scan timeout fire even when the query timeout is not reached
+	start := time.Now()
+	err := db.SelectContext(context.Background(), &ids, "SELECT id FROM slow_network")
+	if !errors.Is(err, ErrScanTimeout) {
+		t.Fatalf("Select: expecting ErrScanTimeout, got %v", err)
+	}
+	var scanErr *ScanTimeoutError
+	if !errors.As(err, &scanErr) || !errors.Is(err, context.Canceled) {
+		t.Errorf("expecting the scan error to be unwrapped, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Millisecond*500 {
+		t.Errorf("expecting the scan to stop at the scan timeout, elapsed %s", elapsed)
+	}
+
+	// slow consumer of Reduce
+	var total int64
+	err = db.Reduce(context.Background(), &total, func(total, id int64) int64 {
+		time.Sleep(time.Millisecond * 20)
+		return total + id
+	}, "SELECT id FROM orders")
+	if !errors.Is(err, ErrScanTimeout) {
+		t.Fatalf("Reduce: expecting ErrScanTimeout, got %v", err)
+	}
+	if total != 0 {
+		t.Errorf("expecting accumulator not to be changed, got %d", total)
+	}
+
+	// query timeout is still reported as context deadline
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	time.Sleep(time.Millisecond * 5)
+	if err := db.SelectContext(ctx, &ids, "SELECT id FROM orders"); err == nil || errors.Is(err, ErrScanTimeout) {
+		t.Errorf("expecting query timeout not to be ErrScanTimeout, got %v", err)
+	}
+}
+
+func TestScanTimeoutCombined(t *testing.T) {
+	t.Parallel()
+
+	slowRows := func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		res := fakeResult{columns: []string{"id"}}
+		for i := int64(1); i <= 10; i++ {
+			res.rows = append(res.rows, []driver.Value{i})
+		}
+		res.rowDelay = time.Millisecond * 20
+		return res
+	}
+	cases := map[string]struct {
+		opts []Option
+		ctx  func() context.Context
+	}{
+		"byte budget": {
+			ctx: func() context.Context { return WithByteBudget(context.Background(), 1<<20) },
+		},
+		"query timing": {
+			ctx: func() context.Context {
+				ctx, _ := WithQueryTiming(context.Background())
+				return ctx
+			},
+		},
+		"byte budget and query timing": {
+			ctx: func() context.Context {
+				ctx, _ := WithQueryTiming(WithByteBudget(context.Background(), 1<<20))
+				return ctx
+			},
+		},
+		"statement cache": {
+			opts: []Option{WithStatementCache(8)},
+			ctx:  context.Background,
+		},
+	}
+	for name, c := range cases {
+		db, _, follower := newFakeDB(t, append(c.opts, WithScanTimeout(time.Millisecond*50))...)
+		follower.setHandler(slowRows)
+
+		var ids []int64
+		if err := db.SelectContext(c.ctx(), &ids, "SELECT id FROM slow_network"); !errors.Is(err, ErrScanTimeout) {
+			t.Errorf("%s: expecting ErrScanTimeout, got %v", name, err)
+		}
+		if name == "statement cache" && follower.count("PREPARE SELECT id FROM slow_network") != 1 {
+			t.Errorf("%s: expecting the statement cache to be used, got %v", name, follower.Queries())
+		}
+	}
+
+	// the budget and timing still apply with the scan timeout
+	db, _, follower := newFakeDB(t, WithScanTimeout(time.Second))
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{columns: []string{"name"}, rows: [][]driver.Value{{"aaaaaaaaaa"}, {"bbbbbbbbbb"}}}
+	})
+	ctx, timing := WithQueryTiming(WithByteBudget(context.Background(), 15))
+	var names []string
+	if err := db.SelectContext(ctx, &names, "SELECT name FROM users"); err != errByteBudgetExceeded {
+		t.Errorf("expecting errByteBudgetExceeded, got %v", err)
+	}
+	if timing.Exec == 0 {
+		t.Error("expecting the query to be timed")
+	}
+}
+
+func TestIterateScanTimeout(t *testing.T) {
+	t.Parallel()
+
+	db, _, follower := newFakeDB(t, WithScanTimeout(time.Millisecond*50))
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		res := fakeResult{columns: []string{"id"}}
+		for i := int64(1); i <= 10; i++ {
+			res.rows = append(res.rows, []driver.Value{i})
+		}
+		res.rowDelay = time.Millisecond * 20
+		return res
+	})
+
+	n := 0
+	err := db.Iterate(context.Background(), func(rows *sqlx.Rows) error {
+		n++
+		return nil
+	}, "SELECT id FROM orders")
+	if !errors.Is(err, ErrScanTimeout) {
+		t.Errorf("expecting ErrScanTimeout, got %v", err)
+	}
+	if n >= 10 {
+		t.Errorf("expecting the iteration to be stopped, got %d rows", n)
+	}
+}
@@ -0,0 +1,56 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/albertwidi/go-project-example/internal/pkg/log/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+func (db *DB) schemaVersion(ctx context.Context, handle *sqlx.DB) (string, error) {
+	var version sql.NullString
+	if err := handle.GetContext(ctx, &version, db.opts.SchemaVersionQuery); err != nil {
+		return "", err
+	}
+	return version.String, nil
+}
+
+// CheckSchemaVersion compare the schema version of leader and all followers
+// and log a warning for every follower with different version, this usually happen in the middle of rolling migration
+// follower that fail to return the version doesn't stop the check of the others, the failures is returned together
+// the check is skipped when SchemaVersionQuery is empty
+func (db *DB) CheckSchemaVersion(ctx context.Context) error {
+	if db.opts.SchemaVersionQuery == "" {
+		return nil
+	}
+
+	leaderVersion, err := db.schemaVersion(ctx, db.leader)
+	if err != nil {
+		return err
+	}
+
+	var failures []string
+	for _, f := range db.followerStates() {
+		followerVersion, err := db.schemaVersion(ctx, f.db)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %s", f.name, err))
+			continue
+		}
+		f.version.Store(followerVersion)
+		if followerVersion == leaderVersion || db.opts.Logger == nil {
+			continue
+		}
+		db.opts.Logger.Warnw("sqldb: leader and follower schema version is different", logger.KV{
+			"follower":         f.name,
+			"leader_version":   leaderVersion,
+			"follower_version": followerVersion,
+		})
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("sqldb: failed to check follower schema version: %s", strings.Join(failures, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func versionHandler(version int64) fakeHandler {
+	return func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{columns: []string{"version"}, rows: [][]driver.Value{{version}}}
+	}
+}
+
+func TestCheckSchemaVersion(t *testing.T) {
+	t.Parallel()
+
+	l := &fakeLogger{}
+	db, leader, follower := newFakeDB(t, WithLogger(l), WithSchemaVersionQuery("SELECT MAX(version) FROM schema_migrations"))
+	leader.setHandler(versionHandler(20191017070146))
+	follower.setHandler(versionHandler(20191017070146))
+
+	if err := db.CheckSchemaVersion(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if l.contains("schema version is different") {
+		t.Fatal("expecting no warning when schema version is the same")
+	}
+
+	follower.setHandler(versionHandler(20190613042222))
+	if err := db.CheckSchemaVersion(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !l.contains("schema version is different") {
+		t.Error("expecting warning when schema version is different")
+	}
+}
+
+func TestCheckSchemaVersionAllFollowers(t *testing.T) {
+	t.Parallel()
+
+	l := &fakeLogger{}
+	db, leader, _ := newFakeDB(t, WithLogger(l), WithSchemaVersionQuery("SELECT MAX(version) FROM schema_migrations"))
+	leader.setHandler(versionHandler(20191017070146))
+	serverA, followerA := newFakeServer(t, "a")
+	serverB, followerB := newFakeServer(t, "b")
+	serverC, followerC := newFakeServer(t, "c")
+	serverA.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{err: errors.New("connection refused")}
+	})
+	serverB.setHandler(versionHandler(20190613042222))
+	serverC.setHandler(versionHandler(20190101000000))
+	err := db.SetNamedFollowers([]NamedFollower{{Name: "a", DB: followerA}, {Name: "b", DB: followerB}, {Name: "c", DB: followerC}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = db.CheckSchemaVersion(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "a: connection refused") {
+		t.Errorf("expecting the failed follower to be returned, got %v", err)
+	}
+	// the failure of follower a doesn't stop the check of b and c
+	for _, name := range []string{"follower:b", "follower:c"} {
+		if !l.contains(name) {
+			t.Errorf("expecting mismatch warning with %s, got %v", name, l.logs)
+		}
+	}
+}
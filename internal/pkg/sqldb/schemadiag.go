@@ -0,0 +1,82 @@
+package sqldb
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/albertwidi/go-project-example/internal/pkg/log/logger"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+// schemaDiagnosticTimeout is the maximum duration of the information_schema query
+const schemaDiagnosticTimeout = time.Second * 10
+
+var (
+	// queryTableRegex match the first table referenced by a query
+	queryTableRegex = regexp.MustCompile(`(?i)\b(?:FROM|UPDATE|INTO|JOIN)\s+([A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z_][A-Za-z0-9_]*)?)`)
+	// the missing table is parsed from the message, as neither driver return it as a field
+	postgresUndefinedTableRegex = regexp.MustCompile(`relation "([^"]+)" does not exist`)
+	mysqlUndefinedTableRegex    = regexp.MustCompile(`Table '([^']+)' doesn't exist`)
+)
+
+// list of query to get the columns of a table for each driver
+var tableColumnsQueries = map[string]string{
+	"postgres": "SELECT column_name FROM information_schema.columns WHERE table_name = ? ORDER BY ordinal_position",
+	"mysql":    "SELECT column_name FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ? ORDER BY ordinal_position",
+}
+
+// undefinedSchemaTable return the table of the query when err is an undefined column or table error
+func undefinedSchemaTable(err error, query string) (string, bool) {
+	var (
+		pqErr    *pq.Error
+		mysqlErr *mysql.MySQLError
+		matches  []string
+	)
+	switch {
+	case errors.As(err, &pqErr) && pqErr.Code == "42P01":
+		matches = postgresUndefinedTableRegex.FindStringSubmatch(pqErr.Message)
+	case errors.As(err, &mysqlErr) && mysqlErr.Number == 1146:
+		matches = mysqlUndefinedTableRegex.FindStringSubmatch(mysqlErr.Message)
+	case errors.As(err, &pqErr) && pqErr.Code == "42703", errors.As(err, &mysqlErr) && mysqlErr.Number == 1054:
+		matches = queryTableRegex.FindStringSubmatch(stripQuery(query))
+	default:
+		return "", false
+	}
+	if len(matches) != 2 {
+		return "", true
+	}
+	return matches[1], true
+}
+
+// diagnoseSchemaError log the query and the actual columns of the referenced table from information_schema
+// this help to find schema skew between the application and the database, for example during rolling migration
+func (db *DB) diagnoseSchemaError(q *queryInfo, queryErr error, table string) {
+	kv := logger.KV{
+		"query":  q.query,
+		"target": q.target,
+		"table":  table,
+		"error":  queryErr.Error(),
+	}
+
+	handle := q.handle
+	if handle == nil {
+		handle = db.leader
+	}
+	if table != "" {
+		// mysql report the table as schema.table, the schema is always the current database
+		parts := strings.Split(table, ".")
+		ctx, cancel := context.WithTimeout(context.Background(), schemaDiagnosticTimeout)
+		defer cancel()
+		var columns []string
+		if err := handle.SelectContext(ctx, &columns, db.Rebind(tableColumnsQueries[db.driver]), parts[len(parts)-1]); err != nil {
+			kv["columns_error"] = err.Error()
+		} else {
+			kv["columns"] = strings.Join(columns, ", ")
+		}
+	}
+	db.opts.Logger.Warnw("sqldb: query failed with undefined column or table", kv)
+}
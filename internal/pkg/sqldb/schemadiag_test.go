@@ -0,0 +1,71 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+func TestDiagnoseSchemaError(t *testing.T) {
+	t.Parallel()
+
+	l := &fakeLogger{}
+	db, _, follower := newFakeDB(t, WithLogger(l), WithSchemaErrorDiagnostics())
+	db.driver = "postgres"
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		if query == "SELECT column_name FROM information_schema.columns WHERE table_name = $1 ORDER BY ordinal_position" {
+			if args[0].Value != "users" {
+				return fakeResult{err: errors.New("unexpected table")}
+			}
+			return fakeResult{columns: []string{"column_name"}, rows: [][]driver.Value{{"id"}, {"name"}, {"full_name"}}}
+		}
+		return fakeResult{err: &pq.Error{Code: "42703", Message: `column "nickname" does not exist`}}
+	})
+
+	var names []string
+	if err := db.SelectContext(context.Background(), &names, "SELECT nickname FROM users WHERE id = $1", 1); err == nil {
+		t.Fatal("expecting undefined column error")
+	}
+
+	deadline := time.Now().Add(time.Second * 5)
+	for !l.contains("sqldb: query failed with undefined column or table") {
+		if time.Now().After(deadline) {
+			t.Fatal("expecting schema diagnostic to be logged")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	for _, e := range []string{"columns:id, name, full_name", "table:users", "query:SELECT nickname FROM users WHERE id = $1"} {
+		if !l.contains(e) {
+			t.Errorf("expecting diagnostic log to contain %s, got %v", e, l.logs)
+		}
+	}
+}
+
+func TestUndefinedSchemaTable(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		err   error
+		query string
+		table string
+		ok    bool
+	}{
+		{err: &pq.Error{Code: "42703"}, query: "UPDATE public.users SET nickname = $1", table: "public.users", ok: true},
+		{err: &pq.Error{Code: "42P01", Message: `relation "orders" does not exist`}, query: "SELECT * FROM orders", table: "orders", ok: true},
+		{err: &mysql.MySQLError{Number: 1054, Message: "Unknown column 'nickname' in 'field list'"}, query: "INSERT INTO users (nickname) VALUES (?)", table: "users", ok: true},
+		{err: &mysql.MySQLError{Number: 1146, Message: "Table 'shop.orders' doesn't exist"}, query: "SELECT * FROM orders", table: "shop.orders", ok: true},
+		{err: &pq.Error{Code: "23505"}, query: "INSERT INTO users (id) VALUES ($1)"},
+		{err: errors.New("connection refused"), query: "SELECT * FROM users"},
+	}
+	for _, c := range cases {
+		table, ok := undefinedSchemaTable(c.err, c.query)
+		if table != c.table || ok != c.ok {
+			t.Errorf("%v: expecting table %q %v, got %q %v", c.err, c.table, c.ok, table, ok)
+		}
+	}
+}
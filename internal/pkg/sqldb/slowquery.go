@@ -0,0 +1,51 @@
+package sqldb
+
+import (
+	"context"
+	"time"
+
+	"github.com/albertwidi/go-project-example/internal/pkg/log/logger"
+)
+
+// SlowQuery contains full detail of a slow query
+type SlowQuery struct {
+	Query    string
+	Args     []interface{}
+	Target   string
+	Duration time.Duration
+	Err      error
+}
+
+// SecureSink is an access-controlled destination for slow query detail
+// the arguments might contain PII, so the sink should not be the normal application logs
+type SecureSink interface {
+	WriteSlowQuery(ctx context.Context, sq SlowQuery)
+}
+
+func (db *DB) logSlowQuery(ctx context.Context, q *queryInfo, duration time.Duration, err error) {
+	threshold := db.opts.SlowQueryThreshold
+	if p, ok := db.policy(ctx); ok && p.SlowQueryThreshold > 0 {
+		threshold = p.SlowQueryThreshold
+	}
+	if threshold <= 0 || duration < threshold {
+		return
+	}
+
+	if db.opts.Logger != nil {
+		db.opts.Logger.Warnw("sqldb: slow query", logger.KV{
+			"query":    q.query,
+			"target":   q.target,
+			"duration": duration.String(),
+		})
+	}
+
+	if db.opts.SecureSink != nil {
+		db.opts.SecureSink.WriteSlowQuery(ctx, SlowQuery{
+			Query:    q.query,
+			Args:     q.args,
+			Target:   q.target,
+			Duration: duration,
+			Err:      err,
+		})
+	}
+}
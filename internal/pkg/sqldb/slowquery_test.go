@@ -0,0 +1,66 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeSecureSink struct {
+	mu      sync.Mutex
+	queries []SlowQuery
+}
+
+func (fss *fakeSecureSink) WriteSlowQuery(ctx context.Context, sq SlowQuery) {
+	fss.mu.Lock()
+	fss.queries = append(fss.queries, sq)
+	fss.mu.Unlock()
+}
+
+func TestSlowQuerySecureSink(t *testing.T) {
+	t.Parallel()
+
+	var (
+		l    = &fakeLogger{}
+		sink = &fakeSecureSink{}
+	)
+
+	db, _, follower := newFakeDB(t, WithLogger(l), WithSlowQuery(time.Millisecond*10, sink))
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		if query == "SELECT slow FROM users WHERE email = ?" {
+			time.Sleep(time.Millisecond * 20)
+		}
+		return fakeResult{columns: []string{"slow"}, rows: [][]driver.Value{{int64(1)}}}
+	})
+
+	var result int
+	if err := db.Get(&result, "SELECT fast FROM users WHERE email = ?", "fast@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Get(&result, "SELECT slow FROM users WHERE email = ?", "secret@example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sink.queries) != 1 {
+		t.Fatalf("expecting 1 slow query in secure sink, got %d", len(sink.queries))
+	}
+	sq := sink.queries[0]
+	if len(sq.Args) != 1 || sq.Args[0] != "secret@example.com" {
+		t.Errorf("expecting full args in secure sink, got %v", sq.Args)
+	}
+	if sq.Target != targetFollower {
+		t.Errorf("expecting target %s, got %s", targetFollower, sq.Target)
+	}
+
+	if !l.contains("sqldb: slow query") {
+		t.Error("expecting slow query to be logged")
+	}
+	if l.contains("secret@example.com") {
+		t.Error("query arguments should not be written to the logs")
+	}
+	if l.contains("fast@example.com") || l.contains("SELECT fast") {
+		t.Error("fast query should not be logged")
+	}
+}
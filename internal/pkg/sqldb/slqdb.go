@@ -4,11 +4,14 @@ import (
 	"context"
 	"database/sql"
 	"errors"
-	"fmt"
+	"math/rand"
+	"sync"
 	"time"
 
+	"github.com/albertwidi/go-project-example/internal/pkg/log/logger"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/reflectx"
 	_ "github.com/lib/pq"
 )
 
@@ -19,33 +22,136 @@ var (
 
 // DB struct to hold all database connections
 type DB struct {
-	driver   string
-	leader   *sqlx.DB
-	follower *sqlx.DB
+	driver string
+	leader *sqlx.DB
+	opts   Options
+
+	// followersMu protect the followers list, as followers can be changed at runtime
+	followersMu sync.RWMutex
+	followers   []*followerDB
+	// tiers is the sorted unique tiers of followers
+	tiers []int
+	// next is the round-robin counter for follower selection
+	next uint64
+
+	// inflight track all in-flight queries, so it can be canceled
+	inflight inflightQueries
+
+	// taps is the list of attached debug tap
+	taps taps
+
+	// counters buffer the counter increment before flushed to the database
+	counters counterBuffer
+
+	// breakers is the circuit breaker of each query fingerprint
+	breakers circuitBreakers
+
+	// async is the queue of ExecAsync
+	async asyncQueue
+
+	// mapper is applied to followers set by SetFollowers, the followers mapping is not changed when nil
+	mapper *reflectx.Mapper
+
+	// statsHistory record the connection pool stats every StatsSampleInterval
+	statsHistory statsHistory
+
+	// faults is the fault injector for chaos testing
+	faults faultInjector
+
+	// allowlist is the set of allowed query fingerprint, all query is allowed when nil
+	allowlist map[string]struct{}
+
+	// loaders is the registered Loader of LoadByKey by name
+	loaders sync.Map
+
+	// stmts is the prepared statement cache, used when StatementCacheSize is set
+	stmts stmtCache
+
+	// processors is the result processors of QueryMaps by scope
+	processors resultProcessors
+
+	// layered is the in-process cache of LayeredGet
+	layered layeredCache
+
+	// leaderHealth track the consecutive failed write to leader for LeaderHealthy
+	leaderHealth leaderHealth
+
+	// rand is used for sampling and backoff jitter, so it doesn't share the global source
+	rand *lockedRand
+
+	// done is closed when DB is closed, to stop all background process
+	done      chan struct{}
+	closeOnce sync.Once
 }
 
 // Wrap leader and follower sqlx object to one DB object
 // this is for easier usage, so user doesn't have to specify leader or follower
 // all exec is going to leader, all query is going to follower
-func Wrap(ctx context.Context, leader, follower *sqlx.DB) (*DB, error) {
-	if leader.DriverName() != follower.DriverName() {
-		return nil, fmt.Errorf("sqldb: leader and follower driver is not matched. leader = %s follower = %s", leader.DriverName(), follower.DriverName())
-	}
-
+func Wrap(ctx context.Context, leader, follower *sqlx.DB, opts ...Option) (*DB, error) {
 	db := DB{
-		driver:   leader.DriverName(),
-		leader:   leader,
-		follower: follower,
+		driver:    leader.DriverName(),
+		leader:    leader,
+		followers: []*followerDB{newFollower(defaultFollowerName(0), follower)},
+		tiers:     []int{0},
+		done:      make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(&db.opts)
+	}
+	if err := db.checkFollowerDriver(follower); err != nil {
+		return nil, err
+	}
+	db.allowlist = newAllowlist(db.opts.QueryAllowlist)
+	db.rand = newLockedRand(db.opts.RandSource)
+	db.faults.injector = db.opts.FaultInjector
+	if err := db.CheckSchemaVersion(ctx); err != nil && db.opts.Logger != nil {
+		db.opts.Logger.Warnw("sqldb: failed to check schema version", logger.KV{"error": err.Error()})
+	}
+	if db.opts.HealthCheckInterval > 0 {
+		go db.healthCheckLoop(db.opts.HealthCheckInterval)
+	}
+	if db.opts.CounterFlushInterval > 0 {
+		go db.counterFlushLoop(db.opts.CounterFlushInterval)
+	}
+	if db.opts.StatsSampleInterval > 0 {
+		go db.statsSampleLoop(db.opts.StatsSampleInterval)
+	}
+	if db.opts.FollowerDiscovery != nil && db.opts.FollowerDiscoveryInterval > 0 {
+		ticker := time.NewTicker(db.opts.FollowerDiscoveryInterval)
+		go db.followerDiscoveryLoop(ticker.C, ticker.Stop)
+	}
+	if db.opts.AsyncQueueSize > 0 {
+		db.startAsyncWorkers(db.opts.AsyncQueueSize, db.opts.AsyncWorkers)
 	}
 	return &db, nil
 }
 
+// ConnectFunc is a function to connect to the database
+type ConnectFunc func(ctx context.Context, driver, dsn string) (*sqlx.DB, error)
+
 // ConnectOptions to list options when connect to the db
 type ConnectOptions struct {
-	Retry                 int
+	// Retry is the maximum number of connect attempt, connect is not retried when the error is not retryable or the context is WithNoRetry
+	Retry int
+	// RetryInterval is the base backoff between connect attempt, default to 3 seconds
+	// the wait before the next attempt is a random duration between zero and min(RetryMaxBackoff, RetryInterval * 2^(attempt-1))
+	RetryInterval time.Duration
+	// RetryMaxBackoff is the maximum backoff between connect attempt, default to 30 seconds
+	RetryMaxBackoff time.Duration
+	// RandSource is the source of the backoff jitter, by default a source seeded by the current time is used
+	RandSource            rand.Source
 	MaxOpenConnections    int
 	MaxIdleConnections    int
 	ConnectionMaxLifetime time.Duration
+	// Connector is used to connect to the database instead of sqlx.ConnectContext
+	// this is useful to inject instrumented or fault-injecting connection in tests
+	Connector ConnectFunc
+	// SSHTunnel connect to the database through ssh tunnel, the tunnel is closed by Disconnect or by Close of the DB wrapping
+	// the database. Close of the returned *sqlx.DB doesn't close the tunnel
+	SSHTunnel *SSHTunnelConfig
+
+	// sleep is replaced in tests
+	sleep func(ctx context.Context, d time.Duration) error
 }
 
 // Connect to a new database
@@ -55,10 +161,30 @@ func Connect(ctx context.Context, driver, dsn string, connOpts *ConnectOptions)
 		opts = &ConnectOptions{}
 	}
 
-	db, err := connectWithRetry(ctx, driver, dsn, opts.Retry)
+	connect := opts.Connector
+	if connect == nil {
+		connect = sqlx.ConnectContext
+	}
+
+	var tunnel *sshTunnel
+	if opts.SSHTunnel != nil {
+		var err error
+		tunnel, dsn, err = openSSHTunnel(*opts.SSHTunnel, driver, dsn)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	db, err := connectWithRetry(ctx, connect, driver, dsn, opts)
 	if err != nil {
+		if tunnel != nil {
+			tunnel.close()
+		}
 		return nil, err
 	}
+	if tunnel != nil {
+		_sshTunnels.Store(db, tunnel)
+	}
 
 	db.SetMaxOpenConns(opts.MaxOpenConnections)
 	db.SetMaxIdleConns(opts.MaxIdleConnections)
@@ -66,42 +192,57 @@ func Connect(ctx context.Context, driver, dsn string, connOpts *ConnectOptions)
 	return db, nil
 }
 
-func connectWithRetry(ctx context.Context, driver, dsn string, retry int) (*sqlx.DB, error) {
-	var (
-		sqlxdb *sqlx.DB
-		err    error
-	)
-
-	if retry == 0 {
-		sqlxdb, err = sqlx.ConnectContext(ctx, driver, dsn)
-		if err != nil {
-			return nil, err
-		}
-		return sqlxdb, err
+// connectWithRetry connect to the database up to opts.Retry times, the connection is only retried when the error is retryable
+// the wait between attempts is full jitter backoff, so instances restarted together doesn't reconnect at the same time
+func connectWithRetry(ctx context.Context, connect ConnectFunc, driver, dsn string, opts *ConnectOptions) (*sqlx.DB, error) {
+	base, max := opts.RetryInterval, opts.RetryMaxBackoff
+	if base == 0 {
+		base = time.Second * 3
+	}
+	if max == 0 {
+		max = time.Second * 30
+	}
+	rnd := newLockedRand(opts.RandSource)
+	sleep := opts.sleep
+	if sleep == nil {
+		sleep = sleepContext
 	}
 
-	for x := 0; x < retry; x++ {
-		sqlxdb, err = sqlx.ConnectContext(ctx, driver, dsn)
+	for attempt := 1; ; attempt++ {
+		sqlxdb, err := connect(ctx, driver, dsn)
 		if err == nil {
-			break
+			return sqlxdb, nil
 		}
-		if x+1 == retry && err != nil {
-			return nil, fmt.Errorf("sqldb: failed connect to database: %s", err.Error())
+
+		ce := &ConnectError{Kind: classifyConnectError(err), Attempts: attempt, Err: err}
+		if attempt >= opts.Retry || !ce.Kind.Retryable() || noRetryFromContext(ctx) {
+			return nil, ce
+		}
+		if err := sleep(ctx, fullJitterBackoff(base, max, attempt, rnd)); err != nil {
+			return nil, ce
 		}
-		time.Sleep(time.Second * 3)
 	}
-	return sqlxdb, err
 }
 
 // Close all database connection to leader and replica
 func (db *DB) Close() error {
-	if err := db.leader.Close(); err != nil {
+	db.closeOnce.Do(func() {
+		close(db.done)
+	})
+	// flush the async exec and buffered counters before the connection is closed
+	// the connection is still closed when flush is failed
+	db.closeAsync()
+	flushErr := db.FlushCounters(context.Background())
+	db.stmts.close()
+	if err := Disconnect(db.leader); err != nil {
 		return err
 	}
-	if err := db.follower.Close(); err != nil {
-		return err
+	for _, follower := range db.Followers() {
+		if err := Disconnect(follower); err != nil {
+			return err
+		}
 	}
-	return nil
+	return flushErr
 }
 
 // Leader return leader database connection
@@ -110,61 +251,88 @@ func (db *DB) Leader() *sqlx.DB {
 }
 
 // Follower return follower database connection
+// the follower is selected using round-robin when there are more than one follower
 func (db *DB) Follower() *sqlx.DB {
-	return db.follower
+	return db.nextFollower()
+}
+
+// LeaderSQLDB return the underlying *sql.DB of leader, the connection pool is shared with the sqlx wrapper
+func (db *DB) LeaderSQLDB() *sql.DB {
+	return db.leader.DB
+}
+
+// FollowerSQLDB return the underlying *sql.DB of follower, selected the same way as Follower
+func (db *DB) FollowerSQLDB() *sql.DB {
+	return db.nextFollower().DB
 }
 
 // SetMaxIdleConns to sql database
 func (db *DB) SetMaxIdleConns(n int) {
 	db.Leader().SetMaxIdleConns(n)
-	db.Follower().SetMaxIdleConns(n)
+	for _, follower := range db.Followers() {
+		follower.SetMaxIdleConns(n)
+	}
 }
 
 // SetMaxOpenConns to sql database
 func (db *DB) SetMaxOpenConns(n int) {
 	db.Leader().SetMaxOpenConns(n)
-	db.Follower().SetMaxOpenConns(n)
+	for _, follower := range db.Followers() {
+		follower.SetMaxOpenConns(n)
+	}
 }
 
 // SetConnMaxLifetime to sql database
 func (db *DB) SetConnMaxLifetime(t time.Duration) {
 	db.Leader().SetConnMaxLifetime(t)
-	db.Follower().SetConnMaxLifetime(t)
+	for _, follower := range db.Followers() {
+		follower.SetConnMaxLifetime(t)
+	}
 }
 
 // Get return one value in destination using relfection
 func (db *DB) Get(dest interface{}, query string, args ...interface{}) error {
-	return db.follower.Get(dest, query, args...)
+	return db.GetContext(context.Background(), dest, query, args...)
 }
 
 // Select return more than one value in destintion using reflection
 func (db *DB) Select(dest interface{}, query string, args ...interface{}) error {
-	return db.follower.Select(dest, query, args...)
+	return db.SelectContext(context.Background(), dest, query, args...)
 }
 
 // Query function
 func (db *DB) Query(query string, args ...interface{}) (*sql.Rows, error) {
-	return db.follower.Query(query, args...)
+	return db.QueryContext(context.Background(), query, args...)
 }
 
 // NamedQuery function
 func (db *DB) NamedQuery(query string, arg interface{}) (*sqlx.Rows, error) {
-	return db.follower.NamedQuery(query, arg)
+	var (
+		rows           *sqlx.Rows
+		ctx            = context.Background()
+		reader, target = db.queryReader(ctx, query)
+	)
+	err := db.run(ctx, &queryInfo{query: query, args: []interface{}{arg}, target: target, rows: true, handle: reader}, func(ctx context.Context) error {
+		var err error
+		rows, err = reader.NamedQueryContext(ctx, query, arg)
+		return err
+	})
+	return rows, err
 }
 
 // QueryRow function
-func (db *DB) QueryRow(query string, args ...interface{}) *sql.Row {
-	return db.follower.QueryRow(query, args...)
+func (db *DB) QueryRow(query string, args ...interface{}) *Row {
+	return db.QueryRowContext(context.Background(), query, args...)
 }
 
 // Exec function
 func (db *DB) Exec(query string, args ...interface{}) (sql.Result, error) {
-	return db.leader.Exec(query, args...)
+	return db.ExecContext(context.Background(), query, args...)
 }
 
 // NamedExec execute query with named parameter
 func (db *DB) NamedExec(query string, arg interface{}) (sql.Result, error) {
-	return db.leader.NamedExec(query, arg)
+	return db.NamedExecContext(context.Background(), query, arg)
 }
 
 // Begin return sql transaction object, begin a transaction
@@ -177,9 +345,14 @@ func (db *DB) Beginx() (*sqlx.Tx, error) {
 	return db.leader.Beginx()
 }
 
-// Rebind query
+// Rebind query, query that already use the driver placeholder is returned unchanged
+// so rebinding twice doesn't mix the placeholders of the query
 func (db *DB) Rebind(query string) string {
-	return sqlx.Rebind(sqlx.BindType(db.driver), query)
+	bindType := sqlx.BindType(db.driver)
+	if alreadyBound(bindType, query) {
+		return query
+	}
+	return sqlx.Rebind(bindType, query)
 }
 
 // Named return named query and parameters
@@ -0,0 +1,62 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// SnapshotDB is a read-only view of the database, all reads see the same snapshot
+// it doesn't have write method to prevent accidental writes
+type SnapshotDB struct {
+	db     *DB
+	tx     *sqlx.Tx
+	target string
+}
+
+// ReadOnlySnapshot begin a repeatable read and read-only transaction in follower for consistent reads,
+// for example in long reporting job. close must be called to end the transaction
+func (db *DB) ReadOnlySnapshot(ctx context.Context) (snapshot *SnapshotDB, close func() error, err error) {
+	reader, target := db.reader(ctx)
+	tx, err := reader.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &SnapshotDB{db: db, tx: tx, target: target}, tx.Rollback, nil
+}
+
+// GetContext get a row from the snapshot
+func (s *SnapshotDB) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return s.db.run(ctx, &queryInfo{query: query, args: args, target: s.target}, func(ctx context.Context) error {
+		return s.tx.GetContext(ctx, dest, query, args...)
+	})
+}
+
+// SelectContext select rows from the snapshot
+func (s *SnapshotDB) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return s.db.run(ctx, &queryInfo{query: query, args: args, target: s.target}, func(ctx context.Context) error {
+		return s.tx.SelectContext(ctx, dest, query, args...)
+	})
+}
+
+// QueryContext query rows from the snapshot
+func (s *SnapshotDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	var rows *sql.Rows
+	err := s.db.run(ctx, &queryInfo{query: query, args: args, target: s.target, rows: true}, func(ctx context.Context) error {
+		var err error
+		rows, err = s.tx.QueryContext(ctx, query, args...)
+		return err
+	})
+	return rows, err
+}
+
+// QueryRowContext query a row from the snapshot
+func (s *SnapshotDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *Row {
+	var row *sql.Row
+	err := s.db.run(ctx, &queryInfo{query: query, args: args, target: s.target, rows: true}, func(ctx context.Context) error {
+		row = s.tx.QueryRowContext(ctx, query, args...)
+		return nil
+	})
+	return &Row{row: row, err: err}
+}
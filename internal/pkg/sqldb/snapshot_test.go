@@ -0,0 +1,74 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestReadOnlySnapshot(t *testing.T) {
+	t.Parallel()
+
+	db, leader, follower := newFakeDB(t)
+
+	// the fake server return the version at the time the transaction began inside a transaction
+	var (
+		mu       sync.Mutex
+		version  int64
+		snapshot int64
+	)
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		mu.Lock()
+		defer mu.Unlock()
+		switch query {
+		case "BEGIN":
+			snapshot = version
+		case "SELECT version FROM reports":
+			return fakeResult{columns: []string{"version"}, rows: [][]driver.Value{{snapshot}}}
+		}
+		return fakeResult{}
+	})
+
+	s, closeSnapshot, err := db.ReadOnlySnapshot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		mu.Lock()
+		version++
+		mu.Unlock()
+
+		var v int64
+		if err := s.GetContext(context.Background(), &v, "SELECT version FROM reports"); err != nil {
+			t.Fatal(err)
+		}
+		if v != 0 {
+			t.Errorf("expecting all reads to see the snapshot version 0, got %d", v)
+		}
+	}
+	if err := closeSnapshot(); err != nil {
+		t.Fatal(err)
+	}
+
+	follower.mu.Lock()
+	txOptions := follower.txOptions
+	follower.mu.Unlock()
+	if len(txOptions) != 1 || !txOptions[0].ReadOnly || sql.IsolationLevel(txOptions[0].Isolation) != sql.LevelRepeatableRead {
+		t.Errorf("expecting one repeatable read and read-only transaction, got %+v", txOptions)
+	}
+	if follower.count("ROLLBACK") != 1 {
+		t.Error("expecting close to end the transaction")
+	}
+	if len(leader.Queries()) != 0 {
+		t.Errorf("expecting snapshot to read from follower, got %v", leader.Queries())
+	}
+
+	for _, method := range []string{"Exec", "ExecContext", "NamedExec", "NamedExecContext", "Commit"} {
+		if _, ok := reflect.TypeOf(s).MethodByName(method); ok {
+			t.Errorf("expecting SnapshotDB to not have %s", method)
+		}
+	}
+}
@@ -3,34 +3,174 @@ package sqldb
 import (
 	"context"
 	"database/sql"
+
+	"github.com/jmoiron/sqlx"
 )
 
 // GetContext function
 func (db *DB) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
-	return db.follower.GetContext(ctx, dest, query, args...)
+	if err := validateGetDest(dest); err != nil {
+		return err
+	}
+	tx, ok, err := db.txReader(ctx)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return db.run(ctx, &queryInfo{query: query, args: args, target: targetLeader}, func(ctx context.Context) error {
+			return tx.GetContext(ctx, dest, db.withTimeoutComment(ctx, query), args...)
+		})
+	}
+	reader, target := db.queryReader(ctx, query)
+	return db.run(ctx, &queryInfo{query: query, args: args, target: target, handle: reader}, func(ctx context.Context) error {
+		if budget, ok := byteBudgetFromContext(ctx); ok {
+			return getWithBudget(ctx, reader, budget, dest, db.withTimeoutComment(ctx, query), args...)
+		}
+		if timing, ok := queryTimingFromContext(ctx); ok {
+			return getTimed(ctx, reader, timing, dest, db.withTimeoutComment(ctx, query), args...)
+		}
+		if db.useStmtCache() {
+			stmt, release, err := db.cachedStmt(ctx, reader, target, query)
+			if err != nil {
+				return err
+			}
+			defer release()
+			return stmt.GetContext(ctx, dest, args...)
+		}
+		return reader.GetContext(ctx, dest, db.withTimeoutComment(ctx, query), args...)
+	})
 }
 
 // SelectContext fuction
 func (db *DB) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
-	return db.follower.SelectContext(ctx, dest, query, args...)
+	if err := validateSelectDest(dest); err != nil {
+		return err
+	}
+	tx, ok, err := db.txReader(ctx)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return db.run(ctx, &queryInfo{query: query, args: args, target: targetLeader}, func(ctx context.Context) error {
+			return tx.SelectContext(ctx, dest, db.withTimeoutComment(ctx, query), args...)
+		})
+	}
+	reader, target := db.queryReader(ctx, query)
+	return db.run(ctx, &queryInfo{query: query, args: args, target: target, handle: reader}, func(ctx context.Context) error {
+		// byte budget, query timing and scan timeout is combined by scanning the opened rows
+		budget, budgeted := byteBudgetFromContext(ctx)
+		timing, _ := queryTimingFromContext(ctx)
+		if budgeted || timing != nil || db.opts.ScanTimeout > 0 {
+			scan := func(rows *sqlx.Rows) error {
+				return scanSelect(rows, dest)
+			}
+			if budgeted {
+				scan = func(rows *sqlx.Rows) error {
+					return scanSliceBudgeted(rows, budget, dest)
+				}
+			}
+			return db.queryScanRows(ctx, reader, target, timing, scan, query, args...)
+		}
+		if db.useStmtCache() {
+			stmt, release, err := db.cachedStmt(ctx, reader, target, query)
+			if err != nil {
+				return err
+			}
+			defer release()
+			return stmt.SelectContext(ctx, dest, args...)
+		}
+		return reader.SelectContext(ctx, dest, db.withTimeoutComment(ctx, query), args...)
+	})
 }
 
 // QueryContext function
 func (db *DB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
-	return db.follower.QueryContext(ctx, query, args...)
+	var rows *sql.Rows
+	tx, ok, err := db.txReader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		err = db.run(ctx, &queryInfo{query: query, args: args, target: targetLeader, rows: true}, func(ctx context.Context) error {
+			var err error
+			rows, err = tx.QueryContext(ctx, db.withTimeoutComment(ctx, query), args...)
+			return err
+		})
+		return rows, err
+	}
+	reader, target := db.queryReader(ctx, query)
+	err = db.run(ctx, &queryInfo{query: query, args: args, target: target, rows: true, handle: reader}, func(ctx context.Context) error {
+		var err error
+		rows, err = reader.QueryContext(ctx, db.withTimeoutComment(ctx, query), args...)
+		return err
+	})
+	return rows, err
 }
 
 // QueryRowContext function
-func (db *DB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
-	return db.follower.QueryRowContext(ctx, query, args...)
+// the row is read from the transaction inside WithTransaction even when WithFollowerRead is used
+func (db *DB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *Row {
+	var row *sql.Row
+	if tx, ok, _ := db.unitOfWorkTx(ctx, false); ok {
+		err := db.run(ctx, &queryInfo{query: query, args: args, target: targetLeader, rows: true}, func(ctx context.Context) error {
+			row = tx.QueryRowContext(ctx, db.withTimeoutComment(ctx, query), args...)
+			return nil
+		})
+		return &Row{row: row, err: err}
+	}
+	reader, target := db.queryReader(ctx, query)
+	err := db.run(ctx, &queryInfo{query: query, args: args, target: target, rows: true, handle: reader}, func(ctx context.Context) error {
+		row = reader.QueryRowContext(ctx, db.withTimeoutComment(ctx, query), args...)
+		return nil
+	})
+	return &Row{row: row, err: err}
 }
 
 // ExecContext function
 func (db *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
-	return db.leader.ExecContext(ctx, query, args...)
+	tx, ok, err := db.unitOfWorkTx(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	exec := db.leader.ExecContext
+	if ok {
+		exec = tx.ExecContext
+	} else if db.useStmtCache() {
+		exec = func(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+			stmt, release, err := db.cachedStmt(ctx, db.leader, targetLeader, query)
+			if err != nil {
+				return nil, err
+			}
+			defer release()
+			return stmt.ExecContext(ctx, args...)
+		}
+	}
+
+	var result sql.Result
+	err = db.run(ctx, &queryInfo{query: query, args: args, target: targetLeader, write: true}, func(ctx context.Context) error {
+		var err error
+		result, err = exec(ctx, db.withTimeoutComment(ctx, query), args...)
+		return err
+	})
+	return result, err
 }
 
 // NamedExecContext function
 func (db *DB) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
-	return db.leader.NamedExecContext(ctx, query, arg)
+	tx, ok, err := db.unitOfWorkTx(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	exec := db.leader.NamedExecContext
+	if ok {
+		exec = tx.NamedExecContext
+	}
+
+	var result sql.Result
+	err = db.run(ctx, &queryInfo{query: query, args: []interface{}{arg}, target: targetLeader, write: true}, func(ctx context.Context) error {
+		var err error
+		result, err = exec(ctx, db.withTimeoutComment(ctx, query), arg)
+		return err
+	})
+	return result, err
 }
@@ -0,0 +1,415 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/albertwidi/go-project-example/internal/pkg/log/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+// fake driver is used to test sqldb without a real database
+// every fake server have its own dsn, and the handler decide the result of each query
+const fakeDriverName = "sqldbfake"
+
+var (
+	_fakeServers   sync.Map
+	_fakeServerSeq int64
+	_fakeConnSeq   int64
+)
+
+func init() {
+	sql.Register(fakeDriverName, &fakeDriver{})
+}
+
+type fakeResult struct {
+	columns      []string
+	columnTypes  []fakeColumnType
+	rows         [][]driver.Value
+	rowsAffected int64
+	err          error
+	// rowDelay is the delay of reading each row, to simulate slow network
+	rowDelay time.Duration
+}
+
+// fakeColumnType is the column type metadata returned by fakeRows
+type fakeColumnType struct {
+	databaseType string
+	nullable     bool
+	length       int64
+}
+
+type fakeHandler func(ctx context.Context, query string, args []driver.NamedValue) fakeResult
+
+type fakeServer struct {
+	name    string
+	mu      sync.Mutex
+	handler fakeHandler
+	queries []string
+	// txOptions is the options of all transactions began in the fake server
+	txOptions []driver.TxOptions
+}
+
+func (fs *fakeServer) setHandler(h fakeHandler) {
+	fs.mu.Lock()
+	fs.handler = h
+	fs.mu.Unlock()
+}
+
+func (fs *fakeServer) do(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+	fs.mu.Lock()
+	fs.queries = append(fs.queries, query)
+	h := fs.handler
+	fs.mu.Unlock()
+
+	if h == nil {
+		return fakeResult{}
+	}
+	return h(ctx, query, args)
+}
+
+// record the query without calling the handler, for example statement preparation
+func (fs *fakeServer) record(query string) {
+	fs.mu.Lock()
+	fs.queries = append(fs.queries, query)
+	fs.mu.Unlock()
+}
+
+// Queries return all queries received by the fake server
+func (fs *fakeServer) Queries() []string {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	q := make([]string, len(fs.queries))
+	copy(q, fs.queries)
+	return q
+}
+
+func (fs *fakeServer) count(query string) int {
+	n := 0
+	for _, q := range fs.Queries() {
+		if q == query {
+			n++
+		}
+	}
+	return n
+}
+
+func newFakeServer(t *testing.T, name string) (*fakeServer, *sqlx.DB) {
+	t.Helper()
+
+	dsn := fmt.Sprintf("%s-%s-%d", t.Name(), name, atomic.AddInt64(&_fakeServerSeq, 1))
+	fs := &fakeServer{name: name}
+	_fakeServers.Store(dsn, fs)
+
+	db, err := sqlx.Open(fakeDriverName, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fs, db
+}
+
+func newFakeDB(t *testing.T, opts ...Option) (*DB, *fakeServer, *fakeServer) {
+	t.Helper()
+
+	leaderServer, leader := newFakeServer(t, "leader")
+	followerServer, follower := newFakeServer(t, "follower")
+	db, err := Wrap(context.Background(), leader, follower, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, leaderServer, followerServer
+}
+
+type fakeDriver struct{}
+
+func (fd *fakeDriver) Open(dsn string) (driver.Conn, error) {
+	fs, ok := _fakeServers.Load(dsn)
+	if !ok {
+		return nil, fmt.Errorf("fake server %s not found", dsn)
+	}
+	return &fakeConn{server: fs.(*fakeServer), id: atomic.AddInt64(&_fakeConnSeq, 1)}, nil
+}
+
+type fakeConn struct {
+	server *fakeServer
+	// id is unique for each connection, passed to the handler context as fakeConnIDKey
+	id int64
+}
+
+type fakeConnIDKey struct{}
+
+// fakeConnID return the id of the connection running the query
+func fakeConnID(ctx context.Context) int64 {
+	id, _ := ctx.Value(fakeConnIDKey{}).(int64)
+	return id
+}
+
+func (fc *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	fc.server.record("PREPARE " + query)
+	return &fakeStmt{conn: fc, query: query}, nil
+}
+
+func (fc *fakeConn) Close() error {
+	return nil
+}
+
+func (fc *fakeConn) Begin() (driver.Tx, error) {
+	return fc.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (fc *fakeConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	fc.server.mu.Lock()
+	fc.server.txOptions = append(fc.server.txOptions, opts)
+	fc.server.mu.Unlock()
+
+	res := fc.server.do(ctx, "BEGIN", nil)
+	if res.err != nil {
+		return nil, res.err
+	}
+	return &fakeTx{conn: fc}, nil
+}
+
+func (fc *fakeConn) Ping(ctx context.Context) error {
+	return fc.server.do(ctx, "PING", nil).err
+}
+
+func (fc *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	res := fc.server.do(context.WithValue(ctx, fakeConnIDKey{}, fc.id), query, args)
+	if res.err != nil {
+		return nil, res.err
+	}
+	return &fakeRows{columns: res.columns, columnTypes: res.columnTypes, rows: res.rows, rowDelay: res.rowDelay}, nil
+}
+
+func (fc *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	res := fc.server.do(context.WithValue(ctx, fakeConnIDKey{}, fc.id), query, args)
+	if res.err != nil {
+		return nil, res.err
+	}
+	return driver.RowsAffected(res.rowsAffected), nil
+}
+
+type fakeTx struct {
+	conn *fakeConn
+}
+
+func (ft *fakeTx) Commit() error {
+	return ft.conn.server.do(context.Background(), "COMMIT", nil).err
+}
+
+func (ft *fakeTx) Rollback() error {
+	return ft.conn.server.do(context.Background(), "ROLLBACK", nil).err
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (fst *fakeStmt) Close() error {
+	fst.conn.server.record("DEALLOCATE " + fst.query)
+	return nil
+}
+
+func (fst *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (fst *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return fst.conn.ExecContext(context.Background(), fst.query, toNamedValues(args))
+}
+
+func (fst *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return fst.conn.QueryContext(context.Background(), fst.query, toNamedValues(args))
+}
+
+func (fst *fakeStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return fst.conn.ExecContext(ctx, fst.query, args)
+}
+
+func (fst *fakeStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return fst.conn.QueryContext(ctx, fst.query, args)
+}
+
+func toNamedValues(args []driver.Value) []driver.NamedValue {
+	nv := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		nv[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return nv
+}
+
+type fakeRows struct {
+	columns     []string
+	columnTypes []fakeColumnType
+	rows        [][]driver.Value
+	rowDelay    time.Duration
+	pos         int
+}
+
+func (fr *fakeRows) Columns() []string {
+	return fr.columns
+}
+
+func (fr *fakeRows) ColumnTypeDatabaseTypeName(index int) string {
+	if index >= len(fr.columnTypes) {
+		return ""
+	}
+	return fr.columnTypes[index].databaseType
+}
+
+func (fr *fakeRows) ColumnTypeNullable(index int) (nullable, ok bool) {
+	if index >= len(fr.columnTypes) {
+		return false, false
+	}
+	return fr.columnTypes[index].nullable, true
+}
+
+func (fr *fakeRows) ColumnTypeLength(index int) (length int64, ok bool) {
+	if index >= len(fr.columnTypes) || fr.columnTypes[index].length == 0 {
+		return 0, false
+	}
+	return fr.columnTypes[index].length, true
+}
+
+func (fr *fakeRows) Close() error {
+	return nil
+}
+
+func (fr *fakeRows) Next(dest []driver.Value) error {
+	if fr.pos >= len(fr.rows) {
+		return io.EOF
+	}
+	time.Sleep(fr.rowDelay)
+	copy(dest, fr.rows[fr.pos])
+	fr.pos++
+	return nil
+}
+
+// fakeLogger record all log message for assertion
+type fakeLogger struct {
+	mu   sync.Mutex
+	logs []string
+}
+
+var _ logger.Logger = (*fakeLogger)(nil)
+
+func (fl *fakeLogger) write(level, msg string, kv logger.KV) {
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+	fl.logs = append(fl.logs, fmt.Sprintf("[%s] %s %v", level, msg, kv))
+}
+
+func (fl *fakeLogger) contains(s string) bool {
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+	for _, l := range fl.logs {
+		if strings.Contains(l, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func (fl *fakeLogger) SetConfig(config *logger.Config) error { return nil }
+func (fl *fakeLogger) SetLevel(level logger.Level) error     { return nil }
+func (fl *fakeLogger) Debug(args ...interface{})             { fl.write("DEBUG", fmt.Sprint(args...), nil) }
+func (fl *fakeLogger) Debugf(format string, args ...interface{}) {
+	fl.write("DEBUG", fmt.Sprintf(format, args...), nil)
+}
+func (fl *fakeLogger) Debugw(msg string, kv logger.KV) { fl.write("DEBUG", msg, kv) }
+func (fl *fakeLogger) Info(args ...interface{})        { fl.write("INFO", fmt.Sprint(args...), nil) }
+func (fl *fakeLogger) Infof(format string, args ...interface{}) {
+	fl.write("INFO", fmt.Sprintf(format, args...), nil)
+}
+func (fl *fakeLogger) Infow(msg string, kv logger.KV) { fl.write("INFO", msg, kv) }
+func (fl *fakeLogger) Warn(args ...interface{})       { fl.write("WARN", fmt.Sprint(args...), nil) }
+func (fl *fakeLogger) Warnf(format string, args ...interface{}) {
+	fl.write("WARN", fmt.Sprintf(format, args...), nil)
+}
+func (fl *fakeLogger) Warnw(msg string, kv logger.KV) { fl.write("WARN", msg, kv) }
+func (fl *fakeLogger) Error(args ...interface{})      { fl.write("ERROR", fmt.Sprint(args...), nil) }
+func (fl *fakeLogger) Errorf(format string, args ...interface{}) {
+	fl.write("ERROR", fmt.Sprintf(format, args...), nil)
+}
+func (fl *fakeLogger) Errorw(msg string, kv logger.KV) { fl.write("ERROR", msg, kv) }
+func (fl *fakeLogger) Fatal(args ...interface{})       { fl.write("FATAL", fmt.Sprint(args...), nil) }
+func (fl *fakeLogger) Fatalf(format string, args ...interface{}) {
+	fl.write("FATAL", fmt.Sprintf(format, args...), nil)
+}
+func (fl *fakeLogger) Fatalw(msg string, kv logger.KV) { fl.write("FATAL", msg, kv) }
+
+func TestSQLDB(t *testing.T) {
+	t.Parallel()
+
+	db, leader, follower := newFakeDB(t)
+	handler := func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}}
+	}
+	leader.setHandler(handler)
+	follower.setHandler(handler)
+
+	if db.LeaderSQLDB() != db.Leader().DB {
+		t.Error("expecting leader *sql.DB to share the pool with sqlx wrapper")
+	}
+	if db.FollowerSQLDB() != db.Follower().DB {
+		t.Error("expecting follower *sql.DB to share the pool with sqlx wrapper")
+	}
+
+	var id int
+	if err := db.LeaderSQLDB().QueryRow("SELECT id FROM users").Scan(&id); err != nil || id != 1 {
+		t.Errorf("expecting leader *sql.DB to be usable, got %d %v", id, err)
+	}
+	if err := db.FollowerSQLDB().QueryRow("SELECT id FROM users").Scan(&id); err != nil || id != 1 {
+		t.Errorf("expecting follower *sql.DB to be usable, got %d %v", id, err)
+	}
+	if leader.count("SELECT id FROM users") != 1 || follower.count("SELECT id FROM users") != 1 {
+		t.Error("expecting each query to go to its handle")
+	}
+}
+
+func TestWrapDriverMismatch(t *testing.T) {
+	t.Parallel()
+
+	leaderServer, fake := newFakeServer(t, "leader")
+	followerServer, follower := newFakeServer(t, "follower")
+	// the leader is registered as postgres, and the follower is a proxy with the fake driver name
+	leader := sqlx.NewDb(fake.DB, "postgres")
+
+	if _, err := Wrap(context.Background(), leader, follower); err == nil || !strings.Contains(err.Error(), "driver is not matched") {
+		t.Fatalf("expecting driver mismatch error, got %v", err)
+	}
+
+	db, err := Wrap(context.Background(), leader, follower, WithAllowDriverMismatch())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query := db.Rebind("SELECT id FROM users WHERE id = ?"); query != "SELECT id FROM users WHERE id = $1" {
+		t.Errorf("expecting query to be rebound with the leader driver, got %s", query)
+	}
+	handler := func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}}
+	}
+	leaderServer.setHandler(handler)
+	followerServer.setHandler(handler)
+	var id int
+	if err := db.GetContext(context.Background(), &id, "SELECT id FROM users"); err != nil {
+		t.Fatal(err)
+	}
+	if followerServer.count("SELECT id FROM users") != 1 {
+		t.Error("expecting read to be sent to the mismatched follower")
+	}
+
+	_, other := newFakeServer(t, "other")
+	if err := db.SetFollowers([]*sqlx.DB{other}); err != nil {
+		t.Errorf("expecting mismatched follower to be allowed by SetFollowers, got %v", err)
+	}
+}
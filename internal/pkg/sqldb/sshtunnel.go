@@ -0,0 +1,232 @@
+package sqldb
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+var errSSHHostKeyRequired = errors.New("sqldb: ssh tunnel require KnownHostsFile or HostKeyCallback")
+
+// SSHTunnelConfig is the configuration to connect to the database through ssh tunnel
+type SSHTunnelConfig struct {
+	// Host is the ssh server address, port 22 is used when port is not set
+	Host string
+	User string
+	// KeyFile is the path of private key to authenticate to the ssh server
+	KeyFile string
+	// KnownHostsFile is used to verify the ssh server host key
+	KnownHostsFile string
+	// HostKeyCallback is used to verify the ssh server host key instead of KnownHostsFile
+	HostKeyCallback ssh.HostKeyCallback
+	// Timeout of the ssh connection, default to 10 seconds
+	Timeout time.Duration
+}
+
+// _sshTunnels map the connected database to its ssh tunnel, so the tunnel can be closed by Disconnect
+var _sshTunnels sync.Map
+
+// sshTunnel forward connection from local listener to the database through ssh server
+type sshTunnel struct {
+	client   *ssh.Client
+	listener net.Listener
+	// remote is the database address dialed from the ssh server
+	remote string
+}
+
+func (c SSHTunnelConfig) clientConfig() (*ssh.ClientConfig, error) {
+	key, err := ioutil.ReadFile(c.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("sqldb: failed to read ssh key file: %w", err)
+	}
+	signer, err := ssh.ParsePrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("sqldb: failed to parse ssh key file: %w", err)
+	}
+
+	hostKeyCallback := c.HostKeyCallback
+	if hostKeyCallback == nil && c.KnownHostsFile != "" {
+		hostKeyCallback, err = knownhosts.New(c.KnownHostsFile)
+		if err != nil {
+			return nil, fmt.Errorf("sqldb: failed to read ssh known hosts file: %w", err)
+		}
+	}
+	if hostKeyCallback == nil {
+		return nil, errSSHHostKeyRequired
+	}
+
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = time.Second * 10
+	}
+	return &ssh.ClientConfig{
+		User:            c.User,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         timeout,
+	}, nil
+}
+
+// openSSHTunnel connect to the ssh server and forward a local port to the database address in dsn
+// the dsn with the database address replaced by the local port is returned
+func openSSHTunnel(config SSHTunnelConfig, driver, dsn string) (*sshTunnel, string, error) {
+	clientConfig, err := config.clientConfig()
+	if err != nil {
+		return nil, "", err
+	}
+
+	host := config.Host
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, "22")
+	}
+	client, err := ssh.Dial("tcp", host, clientConfig)
+	if err != nil {
+		return nil, "", fmt.Errorf("sqldb: failed to connect to ssh server: %w", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		client.Close()
+		return nil, "", err
+	}
+
+	tunnelDSN, remote, err := replaceDSNAddress(driver, dsn, listener.Addr().String())
+	if err != nil {
+		listener.Close()
+		client.Close()
+		return nil, "", err
+	}
+
+	t := &sshTunnel{client: client, listener: listener, remote: remote}
+	go t.serve()
+	return t, tunnelDSN, nil
+}
+
+func (t *sshTunnel) serve() {
+	for {
+		local, err := t.listener.Accept()
+		if err != nil {
+			return
+		}
+		go t.forward(local)
+	}
+}
+
+func (t *sshTunnel) forward(local net.Conn) {
+	remote, err := t.client.Dial("tcp", t.remote)
+	if err != nil {
+		local.Close()
+		return
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	pipe := func(dst, src net.Conn) {
+		defer wg.Done()
+		io.Copy(dst, src)
+		// close both side, so the other copy is also finished
+		dst.Close()
+		src.Close()
+	}
+	go pipe(remote, local)
+	go pipe(local, remote)
+	wg.Wait()
+}
+
+// close the local listener and the ssh connection, all forwarded connection is closed with the ssh connection
+func (t *sshTunnel) close() error {
+	t.listener.Close()
+	return t.client.Close()
+}
+
+// closeSSHTunnel close the ssh tunnel of db, if any
+func closeSSHTunnel(db *sqlx.DB) {
+	t, ok := _sshTunnels.Load(db)
+	if !ok {
+		return
+	}
+	_sshTunnels.Delete(db)
+	t.(*sshTunnel).close()
+}
+
+// Disconnect close the database returned by Connect and its ssh tunnel
+// Close of the *sqlx.DB only close the connection pool, so the tunnel is leaked when the database is closed that way,
+// use Disconnect or Close of the DB that wrap the database instead
+func Disconnect(db *sqlx.DB) error {
+	err := db.Close()
+	closeSSHTunnel(db)
+	return err
+}
+
+// replaceDSNAddress replace the database address in dsn with addr, and return the replaced address
+func replaceDSNAddress(driver, dsn, addr string) (string, string, error) {
+	switch driver {
+	case "mysql":
+		config, err := mysql.ParseDSN(dsn)
+		if err != nil {
+			return "", "", err
+		}
+		if config.Net != "tcp" {
+			return "", "", fmt.Errorf("sqldb: ssh tunnel is not supported for mysql network %s", config.Net)
+		}
+		remote := config.Addr
+		config.Addr = addr
+		return config.FormatDSN(), remote, nil
+	case "postgres":
+		if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+			return replacePostgresURLAddress(dsn, addr)
+		}
+		return replacePostgresKeyValueAddress(dsn, addr)
+	}
+	return "", "", fmt.Errorf("sqldb: ssh tunnel is not supported for driver %s", driver)
+}
+
+func replacePostgresURLAddress(dsn, addr string) (string, string, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", "", err
+	}
+	remote := u.Host
+	if remote == "" {
+		remote = "localhost"
+	}
+	if _, _, err := net.SplitHostPort(remote); err != nil {
+		remote = net.JoinHostPort(remote, "5432")
+	}
+	u.Host = addr
+	return u.String(), remote, nil
+}
+
+// replacePostgresKeyValueAddress replace host and port in key=value dsn, quoted value with space is not supported
+func replacePostgresKeyValueAddress(dsn, addr string) (string, string, error) {
+	localHost, localPort, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", "", err
+	}
+
+	host, port := "localhost", "5432"
+	fields := strings.Fields(dsn)
+	params := make([]string, 0, len(fields)+2)
+	for _, field := range fields {
+		switch {
+		case strings.HasPrefix(field, "host="):
+			host = strings.TrimPrefix(field, "host=")
+		case strings.HasPrefix(field, "port="):
+			port = strings.TrimPrefix(field, "port=")
+		default:
+			params = append(params, field)
+		}
+	}
+	params = append(params, "host="+localHost, "port="+localPort)
+	return strings.Join(params, " "), net.JoinHostPort(host, port), nil
+}
@@ -0,0 +1,97 @@
+package sqldb
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+)
+
+// defaultStatsHistorySize is the number of stats sample kept when StatsHistorySize is not set
+const defaultStatsHistorySize = 60
+
+// StatsSample is a snapshot of connection pool statistics
+type StatsSample struct {
+	Time      time.Time
+	Leader    sql.DBStats
+	Followers []sql.DBStats
+}
+
+// statsHistory is a ring buffer of stats sample
+type statsHistory struct {
+	mu      sync.Mutex
+	samples []StatsSample
+	// next is the index of the next sample, and the oldest sample when the buffer is full
+	next int
+	full bool
+	// now is used to get the sample time, replaced in tests
+	now func() time.Time
+}
+
+func (sh *statsHistory) add(sample StatsSample, size int) {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	if sh.samples == nil {
+		sh.samples = make([]StatsSample, size)
+	}
+	sh.samples[sh.next] = sample
+	sh.next = (sh.next + 1) % len(sh.samples)
+	if sh.next == 0 {
+		sh.full = true
+	}
+}
+
+// StatsHistory return the recorded stats sample ordered from the oldest
+// stats is only recorded when StatsSampleInterval is set
+func (db *DB) StatsHistory() []StatsSample {
+	sh := &db.statsHistory
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	if !sh.full {
+		history := make([]StatsSample, sh.next)
+		copy(history, sh.samples[:sh.next])
+		return history
+	}
+	history := make([]StatsSample, 0, len(sh.samples))
+	history = append(history, sh.samples[sh.next:]...)
+	return append(history, sh.samples[:sh.next]...)
+}
+
+// sampleStats record the current stats of leader and followers
+func (db *DB) sampleStats() {
+	now := time.Now
+	if db.statsHistory.now != nil {
+		now = db.statsHistory.now
+	}
+
+	followers := db.Followers()
+	sample := StatsSample{
+		Time:      now(),
+		Leader:    db.leader.Stats(),
+		Followers: make([]sql.DBStats, len(followers)),
+	}
+	for i, f := range followers {
+		sample.Followers[i] = f.Stats()
+	}
+
+	size := db.opts.StatsHistorySize
+	if size <= 0 {
+		size = defaultStatsHistorySize
+	}
+	db.statsHistory.add(sample, size)
+}
+
+func (db *DB) statsSampleLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-db.done:
+			return
+		case <-ticker.C:
+			db.sampleStats()
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package sqldb
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStatsHistory(t *testing.T) {
+	t.Parallel()
+
+	db, _, _ := newFakeDB(t, WithStatsHistory(0, 3))
+	start := time.Date(2019, 10, 17, 7, 0, 0, 0, time.UTC)
+	clock := &fakeClock{now: start}
+	db.statsHistory.now = clock.Now
+
+	if history := db.StatsHistory(); len(history) != 0 {
+		t.Fatalf("expecting empty history, got %d", len(history))
+	}
+
+	for i := 0; i < 2; i++ {
+		db.sampleStats()
+		clock.Add(time.Second)
+	}
+	if history := db.StatsHistory(); len(history) != 2 || !history[0].Time.Equal(start) {
+		t.Fatalf("expecting 2 samples from the start, got %+v", history)
+	}
+
+	for i := 0; i < 3; i++ {
+		db.sampleStats()
+		clock.Add(time.Second)
+	}
+	history := db.StatsHistory()
+	if len(history) != 3 {
+		t.Fatalf("expecting history to be bounded to 3, got %d", len(history))
+	}
+	for i, sample := range history {
+		expect := start.Add(time.Second * time.Duration(i+2))
+		if !sample.Time.Equal(expect) {
+			t.Errorf("sample %d: expecting time %s, got %s", i, expect, sample.Time)
+		}
+		if len(sample.Followers) != 1 {
+			t.Errorf("sample %d: expecting 1 follower stats, got %d", i, len(sample.Followers))
+		}
+	}
+}
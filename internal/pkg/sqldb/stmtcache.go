@@ -0,0 +1,176 @@
+package sqldb
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// stmtCacheKey is the prepared statement of query in the connection pool
+type stmtCacheKey struct {
+	handle *sqlx.DB
+	query  string
+}
+
+type stmtCacheEntry struct {
+	key    stmtCacheKey
+	target string
+	stmt   *sqlx.Stmt
+	// refs is the number of query using the statement, evicted statement is closed when the last query release it
+	refs    int
+	evicted bool
+}
+
+// stmtCache is the least-recently-used cache of prepared statement
+type stmtCache struct {
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[stmtCacheKey]*list.Element
+
+	hits, misses, evictions int64
+}
+
+// useStmtCache return true when query is executed using cached prepared statement
+// the cache is not used with TimeoutComment, as the comment make every query text different
+func (db *DB) useStmtCache() bool {
+	return db.opts.StatementCacheSize > 0 && !db.opts.TimeoutComment
+}
+
+// cachedStmt return the prepared statement of query in handle, the statement is prepared and cached when not found
+// release must be called when the query using the statement is finished, so the statement is not closed while it is used
+// the least-recently-used statement is evicted when the cache is full. database/sql prepare each statement at most
+// once per connection, so the cache size is also the maximum number of prepared statement of each server session
+func (db *DB) cachedStmt(ctx context.Context, handle *sqlx.DB, target, query string) (stmt *sqlx.Stmt, release func(), err error) {
+	key := stmtCacheKey{handle: handle, query: query}
+	c := &db.stmts
+
+	c.mu.Lock()
+	if elem, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(elem)
+		c.hits++
+		entry := c.acquire(elem)
+		c.mu.Unlock()
+		_sqldbStatementCacheCount.WithLabelValues(target, "hit").Inc()
+		return entry.stmt, func() { c.release(entry) }, nil
+	}
+	c.misses++
+	c.mu.Unlock()
+	_sqldbStatementCacheCount.WithLabelValues(target, "miss").Inc()
+
+	prepared, err := handle.PreparexContext(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c.mu.Lock()
+	if c.entries == nil {
+		c.lru = list.New()
+		c.entries = make(map[stmtCacheKey]*list.Element)
+	}
+	// the same query is prepared concurrently, use the cached statement
+	if elem, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(elem)
+		entry := c.acquire(elem)
+		c.mu.Unlock()
+		prepared.Close()
+		return entry.stmt, func() { c.release(entry) }, nil
+	}
+	entry := &stmtCacheEntry{key: key, target: target, stmt: prepared, refs: 1}
+	c.entries[key] = c.lru.PushFront(entry)
+	_sqldbStatementCacheGauge.WithLabelValues(target).Inc()
+
+	var closed []*stmtCacheEntry
+	for c.lru.Len() > db.opts.StatementCacheSize {
+		back := c.lru.Back()
+		_sqldbStatementCacheCount.WithLabelValues(back.Value.(*stmtCacheEntry).target, "eviction").Inc()
+		c.evictions++
+		if evicted := c.evict(back); evicted != nil {
+			closed = append(closed, evicted)
+		}
+	}
+	c.mu.Unlock()
+
+	// closing the statement deallocate it from every connection
+	for _, evicted := range closed {
+		evicted.stmt.Close()
+	}
+	return prepared, func() { c.release(entry) }, nil
+}
+
+// acquire the entry of elem, c.mu must be held
+func (c *stmtCache) acquire(elem *list.Element) *stmtCacheEntry {
+	entry := elem.Value.(*stmtCacheEntry)
+	entry.refs++
+	return entry
+}
+
+// release the entry acquired by cachedStmt, the evicted statement is closed when it is not used anymore
+func (c *stmtCache) release(entry *stmtCacheEntry) {
+	c.mu.Lock()
+	entry.refs--
+	closeStmt := entry.evicted && entry.refs == 0
+	c.mu.Unlock()
+	if closeStmt {
+		entry.stmt.Close()
+	}
+}
+
+// evict remove elem from the cache, return the entry when it is not used and can be closed right away, c.mu must be held
+// the gauge is decreased when the entry is evicted, as the statement is not reachable from the cache anymore
+func (c *stmtCache) evict(elem *list.Element) *stmtCacheEntry {
+	entry := c.lru.Remove(elem).(*stmtCacheEntry)
+	delete(c.entries, entry.key)
+	entry.evicted = true
+	_sqldbStatementCacheGauge.WithLabelValues(entry.target).Dec()
+	if entry.refs > 0 {
+		return nil
+	}
+	return entry
+}
+
+// StatementCacheStats return the number of statement cache hit, miss and eviction since the DB is created
+// low hit ratio means the cache is too small, or there are too many distinct query, for example query with inlined values
+func (db *DB) StatementCacheStats() (hits, misses, evictions int64) {
+	db.stmts.mu.Lock()
+	defer db.stmts.mu.Unlock()
+	return db.stmts.hits, db.stmts.misses, db.stmts.evictions
+}
+
+// purge remove all cached statement of handle, for example the follower that is removed by SetFollowers
+func (c *stmtCache) purge(handle *sqlx.DB) {
+	c.mu.Lock()
+	var closed []*stmtCacheEntry
+	for key, elem := range c.entries {
+		if key.handle != handle {
+			continue
+		}
+		if entry := c.evict(elem); entry != nil {
+			closed = append(closed, entry)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, entry := range closed {
+		entry.stmt.Close()
+	}
+}
+
+// close all cached statement, statement that is still used is closed when it is released
+func (c *stmtCache) close() {
+	c.mu.Lock()
+	var closed []*stmtCacheEntry
+	for _, elem := range c.entries {
+		if entry := c.evict(elem); entry != nil {
+			closed = append(closed, entry)
+		}
+	}
+	c.entries = nil
+	c.lru = nil
+	c.mu.Unlock()
+
+	for _, entry := range closed {
+		entry.stmt.Close()
+	}
+}
@@ -0,0 +1,156 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestStatementCache(t *testing.T) {
+	db, leader, follower := newFakeDB(t, WithStatementCache(2))
+	// a single connection, so each statement is prepared once
+	db.Follower().SetMaxOpenConns(1)
+	handler := func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}, rowsAffected: 1}
+	}
+	leader.setHandler(handler)
+	follower.setHandler(handler)
+	before := testutil.ToFloat64(_sqldbStatementCacheGauge.WithLabelValues(targetFollower))
+
+	query := func(n int) string {
+		return fmt.Sprintf("SELECT id FROM users WHERE id = %d", n)
+	}
+	get := func(n int) {
+		var id int
+		if err := db.GetContext(context.Background(), &id, query(n)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	get(1)
+	get(2)
+	get(1)
+	if n := follower.count("PREPARE " + query(1)); n != 1 {
+		t.Errorf("expecting cached statement to be prepared once, got %d", n)
+	}
+	if n := follower.count(query(1)); n != 2 {
+		t.Errorf("expecting cached statement to be executed twice, got %d", n)
+	}
+	if got := testutil.ToFloat64(_sqldbStatementCacheGauge.WithLabelValues(targetFollower)) - before; got != 2 {
+		t.Errorf("expecting 2 cached statements, got %v", got)
+	}
+
+	// statement 2 is the least recently used
+	get(3)
+	if follower.count("DEALLOCATE "+query(2)) != 1 {
+		t.Error("expecting least recently used statement to be deallocated")
+	}
+	if follower.count("DEALLOCATE "+query(1)) != 0 {
+		t.Error("expecting recently used statement to be kept")
+	}
+	if got := testutil.ToFloat64(_sqldbStatementCacheGauge.WithLabelValues(targetFollower)) - before; got != 2 {
+		t.Errorf("expecting cache to be bounded to 2 statements, got %v", got)
+	}
+	get(2)
+	if n := follower.count("PREPARE " + query(2)); n != 2 {
+		t.Errorf("expecting evicted statement to be prepared again, got %d", n)
+	}
+
+	// get 1, 2, 1, 3 and 2 is 1 hit, 4 miss and 2 eviction
+	hits, misses, evictions := db.StatementCacheStats()
+	if hits != 1 || misses != 4 || evictions != 2 {
+		t.Errorf("expecting 1 hit, 4 misses and 2 evictions, got %d hits, %d misses and %d evictions", hits, misses, evictions)
+	}
+	beforeHits := testutil.ToFloat64(_sqldbStatementCacheCount.WithLabelValues(targetFollower, "hit"))
+	for i := 0; i < 5; i++ {
+		get(2)
+	}
+	if hits, _, _ := db.StatementCacheStats(); hits != 6 {
+		t.Errorf("expecting repeated query to hit the cache, got %d hits", hits)
+	}
+	if got := testutil.ToFloat64(_sqldbStatementCacheCount.WithLabelValues(targetFollower, "hit")) - beforeHits; got != 5 {
+		t.Errorf("expecting 5 hits in metric, got %v", got)
+	}
+
+	if _, err := db.ExecContext(context.Background(), "UPDATE users SET name = 'a' WHERE id = 1"); err != nil {
+		t.Fatal(err)
+	}
+	if leader.count("PREPARE UPDATE users SET name = 'a' WHERE id = 1") != 1 {
+		t.Error("expecting exec to use cached statement")
+	}
+
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if follower.count("DEALLOCATE "+query(1)) != 1 || leader.count("DEALLOCATE UPDATE users SET name = 'a' WHERE id = 1") != 1 {
+		t.Error("expecting cached statements to be deallocated on close")
+	}
+	if got := testutil.ToFloat64(_sqldbStatementCacheGauge.WithLabelValues(targetFollower)) - before; got != 0 {
+		t.Errorf("expecting no cached statement after close, got %v", got)
+	}
+}
+
+func TestStatementCacheConcurrentEviction(t *testing.T) {
+	t.Parallel()
+
+	db, _, follower := newFakeDB(t, WithStatementCache(1))
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}}
+	})
+
+	// every query evict the statement of the other queries, in-use statement must not be closed
+	var wg sync.WaitGroup
+	errs := make(chan error, 8*50)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				var id int
+				if err := db.GetContext(context.Background(), &id, fmt.Sprintf("SELECT id FROM users WHERE id = %d", (i+j)%4)); err != nil {
+					errs <- err
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("expecting no error when the statement is evicted concurrently, got %v", err)
+	}
+	if _, _, evictions := db.StatementCacheStats(); evictions == 0 {
+		t.Error("expecting statements to be evicted")
+	}
+}
+
+func TestStatementCachePurgeRemovedFollower(t *testing.T) {
+	t.Parallel()
+
+	db, _, follower := newFakeDB(t, WithStatementCache(10))
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}}
+	})
+	const query = "SELECT id FROM users WHERE id = 1"
+	var id int
+	if err := db.GetContext(context.Background(), &id, query); err != nil {
+		t.Fatal(err)
+	}
+
+	_, newFollower := newFakeServer(t, "new-follower")
+	if err := db.SetFollowers([]*sqlx.DB{newFollower}); err != nil {
+		t.Fatal(err)
+	}
+	if n := follower.count("DEALLOCATE " + query); n != 1 {
+		t.Errorf("expecting the statement of removed follower to be deallocated, got %d", n)
+	}
+	db.stmts.mu.Lock()
+	defer db.stmts.mu.Unlock()
+	if n := len(db.stmts.entries); n != 0 {
+		t.Errorf("expecting removed follower statements to be purged, got %d", n)
+	}
+}
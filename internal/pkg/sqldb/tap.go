@@ -0,0 +1,104 @@
+package sqldb
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// tapBufferSize is the number of event buffered for each tap before the event is dropped
+const tapBufferSize = 1024
+
+// TapEvent is the query event written to a tap as newline-delimited JSON
+type TapEvent struct {
+	Time        time.Time `json:"time"`
+	Event       string    `json:"event"`
+	Fingerprint string    `json:"fingerprint"`
+	Target      string    `json:"target"`
+	DurationMS  float64   `json:"duration_ms,omitempty"`
+	Error       string    `json:"error,omitempty"`
+}
+
+type tap struct {
+	events chan TapEvent
+	done   chan struct{}
+}
+
+// taps is the list of attached tap
+type taps struct {
+	mu   sync.RWMutex
+	seq  uint64
+	taps map[uint64]*tap
+}
+
+// AttachTap stream query events to w as newline-delimited JSON, detach must be called to stop the stream
+// writing to the tap never block the query, event is dropped if the writer is slow
+func (db *DB) AttachTap(w io.Writer) (detach func()) {
+	t := &tap{
+		events: make(chan TapEvent, tapBufferSize),
+		done:   make(chan struct{}),
+	}
+
+	db.taps.mu.Lock()
+	if db.taps.taps == nil {
+		db.taps.taps = make(map[uint64]*tap)
+	}
+	db.taps.seq++
+	id := db.taps.seq
+	db.taps.taps[id] = t
+	db.taps.mu.Unlock()
+
+	go func() {
+		encoder := json.NewEncoder(w)
+		for {
+			select {
+			case <-t.done:
+				return
+			case event := <-t.events:
+				// stop writing when the writer is broken
+				if err := encoder.Encode(event); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			db.taps.mu.Lock()
+			delete(db.taps.taps, id)
+			db.taps.mu.Unlock()
+			close(t.done)
+		})
+	}
+}
+
+func (ts *taps) emit(event string, q *queryInfo, duration time.Duration, err error) {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+	if len(ts.taps) == 0 {
+		return
+	}
+
+	e := TapEvent{
+		Time:        time.Now(),
+		Event:       event,
+		Fingerprint: q.fingerprint(),
+		Target:      q.target,
+	}
+	if duration > 0 {
+		e.DurationMS = float64(duration) / float64(time.Millisecond)
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	for _, t := range ts.taps {
+		select {
+		case t.events <- e:
+		default:
+			// drop the event, the writer is too slow
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package sqldb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a buffer that safe to be written and read concurrently
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (sb *syncBuffer) Write(p []byte) (int, error) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.buf.Write(p)
+}
+
+func (sb *syncBuffer) String() string {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.buf.String()
+}
+
+func TestAttachTap(t *testing.T) {
+	t.Parallel()
+
+	db, _, _ := newFakeDB(t)
+	buf := &syncBuffer{}
+	detach := db.AttachTap(buf)
+
+	if _, err := db.Exec("UPDATE users SET name = ? WHERE id = 1", "name"); err != nil {
+		t.Fatal(err)
+	}
+
+	var events []TapEvent
+	deadline := time.Now().Add(time.Second * 3)
+	for len(events) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond * 10)
+		events = events[:0]
+		scanner := bufio.NewScanner(bytes.NewBufferString(buf.String()))
+		for scanner.Scan() {
+			var e TapEvent
+			if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+				t.Fatalf("invalid NDJSON line %s: %v", scanner.Text(), err)
+			}
+			events = append(events, e)
+		}
+	}
+	detach()
+
+	if len(events) != 2 {
+		t.Fatalf("expecting 2 events, got %d", len(events))
+	}
+	if events[0].Event != "start" || events[1].Event != "end" {
+		t.Errorf("expecting start and end event, got %s and %s", events[0].Event, events[1].Event)
+	}
+	if events[1].Fingerprint != "update users set name = ? where id = ?" || events[1].Target != targetLeader {
+		t.Errorf("unexpected event %+v", events[1])
+	}
+
+	// no event is written after detach
+	written := buf.String()
+	if _, err := db.Exec("UPDATE users SET name = ? WHERE id = 1", "name"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond * 20)
+	if buf.String() != written {
+		t.Error("expecting no event after detach")
+	}
+}
@@ -0,0 +1,48 @@
+package sqldb
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// withDefaultTimeout set the default timeout of the target when the context has no deadline
+// the timeout of the operation policy take precedence over the default timeout
+func (db *DB) withDefaultTimeout(ctx context.Context, target string) (context.Context, context.CancelFunc) {
+	timeout := db.defaultTimeout(target)
+	if p, ok := db.policy(ctx); ok && p.Timeout > 0 {
+		timeout = p.Timeout
+	}
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// defaultTimeout return the default timeout of the target, zero if not set
+func (db *DB) defaultTimeout(target string) time.Duration {
+	if target == targetLeader {
+		return db.opts.LeaderDefaultTimeout
+	}
+	return db.opts.FollowerDefaultTimeout
+}
+
+// withTimeoutComment prepend the remaining deadline of ctx to the query when TimeoutComment is enabled
+// the query is not changed when the context has no deadline or the deadline is already passed
+func (db *DB) withTimeoutComment(ctx context.Context, query string) string {
+	if !db.opts.TimeoutComment {
+		return query
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return query
+	}
+	remaining := time.Until(deadline).Milliseconds()
+	if remaining <= 0 {
+		return query
+	}
+	return fmt.Sprintf("/* timeout=%dms */ %s", remaining, query)
+}
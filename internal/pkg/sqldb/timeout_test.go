@@ -0,0 +1,130 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDefaultTimeout(t *testing.T) {
+	t.Parallel()
+
+	db, leader, follower := newFakeDB(t, WithDefaultTimeout(time.Second, time.Minute))
+
+	var (
+		mu        sync.Mutex
+		deadlines = make(map[string]time.Duration)
+	)
+	recordDeadline := func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		mu.Lock()
+		defer mu.Unlock()
+		if deadline, ok := ctx.Deadline(); ok {
+			deadlines[query] = time.Until(deadline)
+		} else {
+			deadlines[query] = -1
+		}
+		return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}}
+	}
+	leader.setHandler(recordDeadline)
+	follower.setHandler(recordDeadline)
+
+	var id int
+	if err := db.GetContext(context.Background(), &id, "SELECT id FROM users"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(context.Background(), "UPDATE users SET name = 'a' WHERE id = 1"); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	if err := db.GetContext(ctx, &id, "SELECT id FROM orders"); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		query string
+		min   time.Duration
+		max   time.Duration
+	}{
+		{query: "SELECT id FROM users", min: time.Second * 50, max: time.Minute},
+		{query: "UPDATE users SET name = 'a' WHERE id = 1", min: 0, max: time.Second},
+		{query: "SELECT id FROM orders", min: time.Minute * 59, max: time.Hour},
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for _, c := range cases {
+		got := deadlines[c.query]
+		if got <= c.min || got > c.max {
+			t.Errorf("%s: expecting timeout between %s and %s, got %s", c.query, c.min, c.max, got)
+		}
+	}
+}
+
+func TestTimeoutComment(t *testing.T) {
+	t.Parallel()
+
+	db, leader, follower := newFakeDB(t, WithTimeoutComment())
+	result := func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}}
+	}
+	leader.setHandler(result)
+	follower.setHandler(result)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	var id int
+	if err := db.GetContext(ctx, &id, "SELECT id FROM users"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(context.Background(), "UPDATE users SET name = 'a' WHERE id = 1"); err != nil {
+		t.Fatal(err)
+	}
+
+	queries := follower.Queries()
+	if len(queries) != 1 {
+		t.Fatalf("expecting 1 query, got %v", queries)
+	}
+	var remaining int
+	if _, err := fmt.Sscanf(queries[0], "/* timeout=%dms */ SELECT id FROM users", &remaining); err != nil {
+		t.Fatalf("expecting timeout comment, got %s", queries[0])
+	}
+	if remaining <= 4000 || remaining > 5000 {
+		t.Errorf("expecting remaining timeout between 4000ms and 5000ms, got %dms", remaining)
+	}
+	if got := leader.Queries(); len(got) != 1 || got[0] != "UPDATE users SET name = 'a' WHERE id = 1" {
+		t.Errorf("expecting no timeout comment without deadline, got %v", got)
+	}
+}
+
+func TestTimeoutCommentNamedQuery(t *testing.T) {
+	t.Parallel()
+
+	db, leader, _ := newFakeDB(t, WithTimeoutComment())
+	leader.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	arg := map[string]interface{}{"name": "a", "id": 1}
+	if _, err := db.NamedExecContext(ctx, "UPDATE users SET name = :name WHERE id = :id", arg); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Write(ctx, "UPDATE users SET name = :name WHERE id = :id", arg); err != nil {
+		t.Fatal(err)
+	}
+
+	queries := leader.Queries()
+	if len(queries) != 2 {
+		t.Fatalf("expecting 2 queries, got %v", queries)
+	}
+	for _, q := range queries {
+		var remaining int
+		if _, err := fmt.Sscanf(q, "/* timeout=%dms */ UPDATE users SET name = ? WHERE id = ?", &remaining); err != nil {
+			t.Errorf("expecting bound query with timeout comment, got %s", q)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// QueryTiming is the time spent by Get and Select in each phase
+type QueryTiming struct {
+	// Wait is the time waiting for a connection from the pool
+	Wait time.Duration
+	// Exec is the time until the database return the first response
+	Exec time.Duration
+	// Scan is the time reading and scanning the rows into destination
+	Scan time.Duration
+}
+
+type queryTimingKey struct{}
+
+// WithQueryTiming measure the phases of Get and Select using the context into the returned QueryTiming
+// the timing of all queries using the context is added up, read the timing after the queries are finished
+func WithQueryTiming(ctx context.Context) (context.Context, *QueryTiming) {
+	timing := &QueryTiming{}
+	return context.WithValue(ctx, queryTimingKey{}, timing), timing
+}
+
+func queryTimingFromContext(ctx context.Context) (*QueryTiming, bool) {
+	t, ok := ctx.Value(queryTimingKey{}).(*QueryTiming)
+	return t, ok
+}
+
+func (t *QueryTiming) add(phase *time.Duration, start time.Time) time.Time {
+	now := time.Now()
+	atomic.AddInt64((*int64)(phase), int64(now.Sub(start)))
+	return now
+}
+
+// queryTimed run the query on a dedicated connection, so the connection wait is measured apart from the execution
+// scan is called with the rows within the scan timeout and its duration is measured as Scan
+func queryTimed(ctx context.Context, reader *sqlx.DB, timing *QueryTiming, scanTimeout time.Duration, scan func(rows *sqlx.Rows) error, query string, args ...interface{}) error {
+	var (
+		conn  *sql.Conn
+		start time.Time
+	)
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+
+	return queryScan(ctx, scanTimeout, func(ctx context.Context) (*sqlx.Rows, error) {
+		start = time.Now()
+		var err error
+		if conn, err = reader.Conn(ctx); err != nil {
+			return nil, err
+		}
+		start = timing.add(&timing.Wait, start)
+
+		sqlRows, err := conn.QueryContext(ctx, query, args...)
+		start = timing.add(&timing.Exec, start)
+		if err != nil {
+			return nil, err
+		}
+		return &sqlx.Rows{Rows: sqlRows, Mapper: reader.Mapper}, nil
+	}, func(rows *sqlx.Rows) error {
+		defer timing.add(&timing.Scan, start)
+		return scan(rows)
+	})
+}
+
+// getTimed is GetContext that measure the time of each phase
+func getTimed(ctx context.Context, reader *sqlx.DB, timing *QueryTiming, dest interface{}, query string, args ...interface{}) error {
+	value := reflect.ValueOf(dest)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return fmt.Errorf("sqldb: destination must be a non-nil pointer, got %T", dest)
+	}
+
+	return queryTimed(ctx, reader, timing, 0, func(rows *sqlx.Rows) error {
+		// scan into a slice to reuse the sqlx mapping of both struct and scalar destination
+		slice := reflect.New(reflect.SliceOf(value.Elem().Type()))
+		if err := sqlx.StructScan(rows, slice.Interface()); err != nil {
+			return err
+		}
+		if slice.Elem().Len() == 0 {
+			return sql.ErrNoRows
+		}
+		value.Elem().Set(slice.Elem().Index(0))
+		return nil
+	}, query, args...)
+}
@@ -0,0 +1,73 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"time"
+)
+
+func TestQueryTiming(t *testing.T) {
+	t.Parallel()
+
+	const delay = time.Millisecond * 30
+	db, _, follower := newFakeDB(t)
+	rows := make([][]driver.Value, 1000)
+	for i := range rows {
+		rows[i] = []driver.Value{int64(i), "name"}
+	}
+	follower.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		if query == "SELECT id, name FROM users WHERE id = ?" {
+			return fakeResult{}
+		}
+		time.Sleep(delay)
+		return fakeResult{columns: []string{"id", "name"}, rows: rows}
+	})
+
+	// hold the only connection, so the query wait for the connection
+	db.Follower().SetMaxOpenConns(1)
+	conn, err := db.Follower().Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		time.Sleep(delay)
+		conn.Close()
+	}()
+
+	ctx, timing := WithQueryTiming(context.Background())
+	var users []struct {
+		ID   int64  `db:"id"`
+		Name string `db:"name"`
+	}
+	start := time.Now()
+	if err := db.SelectContext(ctx, &users, "SELECT id, name FROM users"); err != nil {
+		t.Fatal(err)
+	}
+	total := time.Since(start)
+	if len(users) != len(rows) {
+		t.Fatalf("expecting %d users, got %d", len(rows), len(users))
+	}
+
+	if timing.Wait < delay/2 {
+		t.Errorf("expecting connection wait of at least %s, got %s", delay/2, timing.Wait)
+	}
+	if timing.Exec < delay {
+		t.Errorf("expecting exec of at least %s, got %s", delay, timing.Exec)
+	}
+	if timing.Scan <= 0 {
+		t.Errorf("expecting scan to be measured, got %s", timing.Scan)
+	}
+	sum := timing.Wait + timing.Exec + timing.Scan
+	if sum > total || total-sum > time.Millisecond*20 {
+		t.Errorf("expecting sum of phases %s to approximate total %s", sum, total)
+	}
+
+	var user struct {
+		ID int64 `db:"id"`
+	}
+	if err := db.GetContext(ctx, &user, "SELECT id, name FROM users WHERE id = ?", 1); err != sql.ErrNoRows {
+		t.Errorf("expecting sql.ErrNoRows, got %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package sqldb
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+var (
+	errTruncateNotConfirmed = errors.New("sqldb: truncate is not confirmed, set TruncateOptions.Confirm to truncate")
+	errTruncateNoTables     = errors.New("sqldb: truncate tables cannot be empty")
+	errTruncateCascade      = errors.New("sqldb: truncate CASCADE is only supported by postgres")
+)
+
+// TruncateOptions of Truncate
+type TruncateOptions struct {
+	// Confirm must be true to truncate, this is to prevent accidental truncation
+	Confirm bool
+	// RestartIdentity reset the sequences owned by the tables, mysql always reset the auto increment
+	RestartIdentity bool
+	// Cascade also truncate tables that has foreign key to the tables, only supported by postgres
+	Cascade bool
+}
+
+// Truncate remove all rows of tables in leader
+// postgres truncate all tables in one statement, mysql truncate the tables one by one
+func (db *DB) Truncate(ctx context.Context, tables []string, opts TruncateOptions) error {
+	if !opts.Confirm {
+		return errTruncateNotConfirmed
+	}
+	if len(tables) == 0 {
+		return errTruncateNoTables
+	}
+	if err := validateIdentifier(tables...); err != nil {
+		return err
+	}
+
+	quoted := make([]string, len(tables))
+	for i, table := range tables {
+		quoted[i] = db.QuoteIdentifier(table)
+	}
+
+	if db.driver == "mysql" {
+		if opts.Cascade {
+			return errTruncateCascade
+		}
+		for _, table := range quoted {
+			if _, err := db.ExecContext(ctx, "TRUNCATE TABLE "+table); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	query := "TRUNCATE TABLE " + strings.Join(quoted, ", ")
+	if opts.RestartIdentity {
+		query += " RESTART IDENTITY"
+	}
+	if opts.Cascade {
+		query += " CASCADE"
+	}
+	_, err := db.ExecContext(ctx, query)
+	return err
+}
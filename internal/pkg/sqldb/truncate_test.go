@@ -0,0 +1,50 @@
+package sqldb
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestTruncate(t *testing.T) {
+	t.Parallel()
+
+	db, leader, _ := newFakeDB(t)
+	db.driver = "postgres"
+	ctx := context.Background()
+
+	if err := db.Truncate(ctx, []string{"users"}, TruncateOptions{}); err != errTruncateNotConfirmed {
+		t.Errorf("expecting errTruncateNotConfirmed, got %v", err)
+	}
+	if err := db.Truncate(ctx, []string{"users; DROP TABLE users"}, TruncateOptions{Confirm: true}); err == nil {
+		t.Error("expecting invalid identifier error")
+	}
+	if n := leader.count("TRUNCATE TABLE users"); n != 0 {
+		t.Fatalf("expecting no truncate without confirmation, got %d", n)
+	}
+
+	if err := db.Truncate(ctx, []string{"users", "orders"}, TruncateOptions{Confirm: true, RestartIdentity: true, Cascade: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Truncate(ctx, []string{"users"}, TruncateOptions{Confirm: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	db.driver = "mysql"
+	if err := db.Truncate(ctx, []string{"users"}, TruncateOptions{Confirm: true, Cascade: true}); err != errTruncateCascade {
+		t.Errorf("expecting errTruncateCascade, got %v", err)
+	}
+	if err := db.Truncate(ctx, []string{"users", "orders"}, TruncateOptions{Confirm: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []string{
+		"TRUNCATE TABLE users, orders RESTART IDENTITY CASCADE",
+		"TRUNCATE TABLE users",
+		"TRUNCATE TABLE users",
+		"TRUNCATE TABLE orders",
+	}
+	if queries := leader.Queries(); !reflect.DeepEqual(queries, expect) {
+		t.Errorf("expecting queries %v, got %v", expect, queries)
+	}
+}
@@ -0,0 +1,167 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/albertwidi/go-project-example/internal/pkg/log/logger"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var errTxIDNotSupported = errors.New("sqldb: transaction id is not supported for this driver")
+
+// Tx is sqlx transaction with additional helper
+type Tx struct {
+	*sqlx.Tx
+	driver string
+	// wrote is 1 after Exec or NamedExec is called in the transaction
+	wrote int32
+}
+
+// BeginTx begin a transaction in leader and return sqldb transaction object
+func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	tx, err := db.leader.BeginTxx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx, driver: db.driver}, nil
+}
+
+// TxID return the current transaction id, this is useful to correlate application logs with database logs
+// only postgres is supported
+func (tx *Tx) TxID(ctx context.Context) (int64, error) {
+	if tx.driver != "postgres" {
+		return 0, errTxIDNotSupported
+	}
+
+	var id int64
+	if err := tx.GetContext(ctx, &id, "SELECT txid_current()"); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// ErrLockTimeout is returned when a transaction cannot acquire a lock within the lock timeout
+var ErrLockTimeout = errors.New("sqldb: lock timeout")
+
+// LockTimeoutError wrap the driver error when a lock cannot be acquired within the lock timeout
+type LockTimeoutError struct {
+	Err error
+}
+
+// Error return the lock timeout message with the driver error
+func (e *LockTimeoutError) Error() string {
+	return ErrLockTimeout.Error() + ": " + e.Err.Error()
+}
+
+// Unwrap return the driver error
+func (e *LockTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// Is return true for ErrLockTimeout
+func (e *LockTimeoutError) Is(target error) bool {
+	return target == ErrLockTimeout
+}
+
+// TxOptions of WithTransaction
+type TxOptions struct {
+	Isolation sql.IsolationLevel
+	ReadOnly  bool
+	// LockTimeout abort the transaction when a lock cannot be acquired within the timeout
+	// this is set using SET LOCAL lock_timeout, only postgres is supported
+	LockTimeout time.Duration
+	// DeferConstraints defer all deferrable constraints to commit using SET CONSTRAINTS ALL DEFERRED, only postgres is supported
+	// constraint violated at commit is returned as ConstraintError
+	DeferConstraints bool
+}
+
+// WithTransaction run fn inside a transaction in leader
+// the transaction is committed when fn return nil, and rolled back when fn return error or panic
+// constraint violation reported by commit, for example deferred constraint, is returned as ConstraintError
+// reads and writes of DB using the context of fn go to the transaction instead of follower and leader
+func (db *DB) WithTransaction(ctx context.Context, opts *TxOptions, fn func(ctx context.Context, tx *Tx) error) (err error) {
+	if opts == nil {
+		opts = &TxOptions{}
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: opts.Isolation, ReadOnly: opts.ReadOnly})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	// the session setting is executed on the sqlx transaction, so it is not counted as write of the transaction
+	if opts.LockTimeout > 0 && db.driver == "postgres" {
+		query := fmt.Sprintf("SET LOCAL lock_timeout = %d", opts.LockTimeout.Milliseconds())
+		if _, err := tx.Tx.ExecContext(ctx, query); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if opts.DeferConstraints && db.driver == "postgres" {
+		if _, err := tx.Tx.ExecContext(ctx, "SET CONSTRAINTS ALL DEFERRED"); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := fn(withContextTx(ctx, db, tx), tx); err != nil {
+		tx.Rollback()
+		return lockTimeoutError(err)
+	}
+	if db.opts.WarnTransactionWithoutWrite && !opts.ReadOnly && atomic.LoadInt32(&tx.wrote) == 0 && db.opts.Logger != nil {
+		db.opts.Logger.Warnw("sqldb: transaction has no write, use a read-only transaction or read from follower", logger.KV{"operation": operationName(ctx)})
+	}
+	return db.constraintError(lockTimeoutError(tx.Commit()))
+}
+
+// Exec execute query in the transaction, the transaction is marked as written
+func (tx *Tx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return tx.ExecContext(context.Background(), query, args...)
+}
+
+// ExecContext execute query in the transaction, the transaction is marked as written
+func (tx *Tx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	atomic.StoreInt32(&tx.wrote, 1)
+	return tx.Tx.ExecContext(ctx, query, args...)
+}
+
+// NamedExec execute named query in the transaction, the transaction is marked as written
+func (tx *Tx) NamedExec(query string, arg interface{}) (sql.Result, error) {
+	return tx.NamedExecContext(context.Background(), query, arg)
+}
+
+// NamedExecContext execute named query in the transaction, the transaction is marked as written
+func (tx *Tx) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	atomic.StoreInt32(&tx.wrote, 1)
+	return tx.Tx.NamedExecContext(ctx, query, arg)
+}
+
+// lockTimeoutError return LockTimeoutError if err is caused by lock timeout
+func lockTimeoutError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var (
+		pqErr    *pq.Error
+		mysqlErr *mysql.MySQLError
+	)
+	// 55P03 is postgres lock_not_available, 1205 is mysql lock wait timeout exceeded
+	if (errors.As(err, &pqErr) && pqErr.Code == "55P03") || (errors.As(err, &mysqlErr) && mysqlErr.Number == 1205) {
+		return &LockTimeoutError{Err: err}
+	}
+	return err
+}
@@ -0,0 +1,268 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+func TestTxID(t *testing.T) {
+	t.Parallel()
+
+	db, leader, _ := newFakeDB(t)
+	leader.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		if query == "SELECT txid_current()" {
+			return fakeResult{columns: []string{"txid_current"}, rows: [][]driver.Value{{int64(1234)}}}
+		}
+		return fakeResult{}
+	})
+
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.TxID(context.Background()); err != errTxIDNotSupported {
+		t.Errorf("expecting error %v for non postgres driver, got %v", errTxIDNotSupported, err)
+	}
+
+	tx.driver = "postgres"
+	id, err := tx.TxID(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id <= 0 {
+		t.Errorf("expecting positive transaction id, got %d", id)
+	}
+}
+
+func TestWithTransactionLockTimeout(t *testing.T) {
+	t.Parallel()
+
+	db, leader, _ := newFakeDB(t)
+	db.driver = "postgres"
+	leader.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		if query == "SELECT * FROM users WHERE id = $1 FOR UPDATE" {
+			return fakeResult{err: &pq.Error{Code: "55P03", Message: "canceling statement due to lock timeout"}}
+		}
+		return fakeResult{}
+	})
+
+	err := db.WithTransaction(context.Background(), &TxOptions{LockTimeout: time.Millisecond * 50}, func(ctx context.Context, tx *Tx) error {
+		_, err := tx.ExecContext(ctx, "SELECT * FROM users WHERE id = $1 FOR UPDATE", 1)
+		return err
+	})
+	if !errors.Is(err, ErrLockTimeout) {
+		t.Errorf("expecting lock timeout error, got %v", err)
+	}
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != "55P03" {
+		t.Errorf("expecting the driver error to be unwrapped, got %v", err)
+	}
+
+	queries := leader.Queries()
+	expect := []string{"BEGIN", "SET LOCAL lock_timeout = 50", "SELECT * FROM users WHERE id = $1 FOR UPDATE", "ROLLBACK"}
+	if !reflect.DeepEqual(queries, expect) {
+		t.Errorf("expecting queries %v, got %v", expect, queries)
+	}
+}
+
+func TestWithTransactionCommit(t *testing.T) {
+	t.Parallel()
+
+	db, leader, _ := newFakeDB(t)
+	err := db.WithTransaction(context.Background(), nil, func(ctx context.Context, tx *Tx) error {
+		_, err := tx.ExecContext(ctx, "UPDATE users SET name = ? WHERE id = ?", "name", 1)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if queries := leader.Queries(); queries[len(queries)-1] != "COMMIT" {
+		t.Errorf("expecting transaction to be committed, got %v", queries)
+	}
+}
+
+func TestWithTransactionDeferredConstraint(t *testing.T) {
+	t.Parallel()
+
+	db, leader, _ := newFakeDB(t)
+	db.driver = "postgres"
+	leader.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		// postgres check deferred constraint at commit
+		if query == "COMMIT" {
+			return fakeResult{err: &pq.Error{Code: "23503", Constraint: "orders_user_id_fkey", Table: "orders", Message: "insert or update on table \"orders\" violates foreign key constraint"}}
+		}
+		return fakeResult{}
+	})
+
+	err := db.WithTransaction(context.Background(), &TxOptions{DeferConstraints: true}, func(ctx context.Context, tx *Tx) error {
+		_, err := tx.ExecContext(ctx, "INSERT INTO orders (user_id) VALUES ($1)", 404)
+		return err
+	})
+	var ce *ConstraintError
+	if !errors.As(err, &ce) {
+		t.Fatalf("expecting constraint error, got %v", err)
+	}
+	if ce.Kind != ConstraintForeignKey || ce.Constraint != "orders_user_id_fkey" || ce.Table != "orders" {
+		t.Errorf("expecting foreign key violation of orders_user_id_fkey, got %+v", ce)
+	}
+
+	queries := leader.Queries()
+	expect := []string{"BEGIN", "SET CONSTRAINTS ALL DEFERRED", "INSERT INTO orders (user_id) VALUES ($1)", "COMMIT"}
+	if !reflect.DeepEqual(queries, expect) {
+		t.Errorf("expecting queries %v, got %v", expect, queries)
+	}
+}
+
+func TestWithTransactionFollowerRead(t *testing.T) {
+	t.Parallel()
+
+	const query = "SELECT name FROM users WHERE id = 1"
+	db, leader, follower := newFakeDB(t)
+	leader.setHandler(func(ctx context.Context, q string, args []driver.NamedValue) fakeResult {
+		return fakeResult{columns: []string{"name"}, rows: [][]driver.Value{{"leader"}}}
+	})
+	follower.setHandler(func(ctx context.Context, q string, args []driver.NamedValue) fakeResult {
+		return fakeResult{columns: []string{"name"}, rows: [][]driver.Value{{"follower"}}}
+	})
+
+	err := db.WithTransaction(context.Background(), nil, func(ctx context.Context, tx *Tx) error {
+		var name string
+		if err := db.GetContext(ctx, &name, query); err != nil {
+			return err
+		}
+		if name != "leader" {
+			t.Errorf("expecting read inside transaction to use the transaction, got %s", name)
+		}
+
+		ctx = WithFollowerRead(ctx)
+		if err := db.GetContext(ctx, &name, query); err != ErrFollowerReadInTx {
+			t.Errorf("Get: expecting ErrFollowerReadInTx, got %v", err)
+		}
+		var names []string
+		if err := db.SelectContext(ctx, &names, query); err != ErrFollowerReadInTx {
+			t.Errorf("Select: expecting ErrFollowerReadInTx, got %v", err)
+		}
+		if _, err := db.QueryContext(ctx, query); err != ErrFollowerReadInTx {
+			t.Errorf("Query: expecting ErrFollowerReadInTx, got %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := follower.count(query); n != 0 {
+		t.Errorf("expecting no read to follower inside transaction, got %d", n)
+	}
+	expect := []string{"BEGIN", query, "COMMIT"}
+	if queries := leader.Queries(); !reflect.DeepEqual(queries, expect) {
+		t.Errorf("expecting queries %v, got %v", expect, queries)
+	}
+
+	// forced follower read outside transaction go to follower
+	var name string
+	if err := db.GetContext(WithFollowerRead(context.Background()), &name, query); err != nil {
+		t.Fatal(err)
+	}
+	if name != "follower" {
+		t.Errorf("expecting forced read to use follower, got %s", name)
+	}
+}
+
+func TestWithTransactionWithoutWrite(t *testing.T) {
+	t.Parallel()
+
+	l := &fakeLogger{}
+	db, leader, _ := newFakeDB(t, WithLogger(l), WithTransactionWithoutWriteWarning())
+	db.driver = "postgres"
+	leader.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{columns: []string{"name"}, rows: [][]driver.Value{{"name"}}}
+	})
+	const warning = "sqldb: transaction has no write"
+
+	// session setting is not a write
+	err := db.WithTransaction(WithOperationName(context.Background(), "get_user"), &TxOptions{LockTimeout: time.Second}, func(ctx context.Context, tx *Tx) error {
+		var name string
+		return tx.GetContext(ctx, &name, "SELECT name FROM users WHERE id = 1")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !l.contains(warning) || !l.contains("operation:get_user") {
+		t.Error("expecting transaction without write to be logged")
+	}
+
+	l = &fakeLogger{}
+	db.opts.Logger = l
+	err = db.WithTransaction(context.Background(), nil, func(ctx context.Context, tx *Tx) error {
+		var name string
+		if err := tx.GetContext(ctx, &name, "SELECT name FROM users WHERE id = 1"); err != nil {
+			return err
+		}
+		_, err := db.ExecContext(ctx, "UPDATE users SET name = 'a' WHERE id = 1")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.contains(warning) {
+		t.Error("expecting transaction with write not to be logged")
+	}
+}
+
+func TestWithTransactionReadHelpers(t *testing.T) {
+	t.Parallel()
+
+	db, leader, follower := newFakeDB(t)
+	handler := func(ctx context.Context, q string, args []driver.NamedValue) fakeResult {
+		return fakeResult{columns: []string{"total"}, rows: [][]driver.Value{{int64(1)}, {int64(2)}}}
+	}
+	leader.setHandler(handler)
+	follower.setHandler(handler)
+
+	reads := map[string]func(ctx context.Context) error{
+		"Reduce": func(ctx context.Context) error {
+			total := int64(0)
+			return db.Reduce(ctx, &total, func(total, n int64) int64 { return total + n }, "SELECT total FROM reduce")
+		},
+		"QueryColumnar": func(ctx context.Context) error {
+			_, err := db.QueryColumnar(ctx, "SELECT total FROM columnar")
+			return err
+		},
+		"Columns": func(ctx context.Context) error {
+			_, err := db.Columns(ctx, "SELECT total FROM columns")
+			return err
+		},
+		"Iterate": func(ctx context.Context) error {
+			return db.Iterate(ctx, func(rows *sqlx.Rows) error { return nil }, "SELECT total FROM iterate")
+		},
+	}
+	err := db.WithTransaction(context.Background(), nil, func(ctx context.Context, tx *Tx) error {
+		for name, read := range reads {
+			if err := read(ctx); err != nil {
+				t.Errorf("%s: %v", name, err)
+			}
+			if err := read(WithFollowerRead(ctx)); err != ErrFollowerReadInTx {
+				t.Errorf("%s: expecting ErrFollowerReadInTx, got %v", name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if queries := follower.Queries(); len(queries) != 0 {
+		t.Errorf("expecting no read to follower inside transaction, got %v", queries)
+	}
+	if n := len(leader.Queries()); n != len(reads)+2 {
+		t.Errorf("expecting every read to use the transaction, got %v", leader.Queries())
+	}
+}
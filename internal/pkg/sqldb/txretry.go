@@ -0,0 +1,121 @@
+package sqldb
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrTxRetryBudgetExhausted is matched by the error of WithTransactionRetry when the retry budget is exhausted
+var ErrTxRetryBudgetExhausted = errors.New("sqldb: transaction retry budget exhausted")
+
+// TxRetryBudgetError wrap the last transaction error when the retry budget is exhausted
+type TxRetryBudgetError struct {
+	Attempts int
+	Err      error
+}
+
+// Error return the budget exhausted message with the last error
+func (e *TxRetryBudgetError) Error() string {
+	return ErrTxRetryBudgetExhausted.Error() + ": " + e.Err.Error()
+}
+
+// Unwrap return the last transaction error
+func (e *TxRetryBudgetError) Unwrap() error {
+	return e.Err
+}
+
+// Is return true for ErrTxRetryBudgetExhausted
+func (e *TxRetryBudgetError) Is(target error) bool {
+	return target == ErrTxRetryBudgetExhausted
+}
+
+// TxRetryOptions of WithTransactionRetry
+// the backoff of each retry is a random duration between zero and min(MaxBackoff, BaseBackoff * 2^(retry-1)),
+// so the first retry wait up to BaseBackoff
+type TxRetryOptions struct {
+	// BaseBackoff is the maximum backoff of the first retry, default to 100ms
+	BaseBackoff time.Duration
+	// MaxBackoff is the maximum backoff of one retry, default to 5 seconds
+	MaxBackoff time.Duration
+	// MaxTotal is the maximum duration from the first attempt to the start of the last attempt
+	// the retry budget is unlimited when zero, the retry is then only stopped by the context
+	MaxTotal time.Duration
+
+	// now and sleep is replaced in tests
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// list of TxRetryOptions default
+const (
+	defaultTxRetryBaseBackoff = time.Millisecond * 100
+	defaultTxRetryMaxBackoff  = time.Second * 5
+)
+
+// backoff return the full jitter backoff of the attempt, the jitter is from rnd
+func (ro TxRetryOptions) backoff(attempt int, rnd *lockedRand) time.Duration {
+	base, max := ro.BaseBackoff, ro.MaxBackoff
+	if base <= 0 {
+		base = defaultTxRetryBaseBackoff
+	}
+	if max <= 0 {
+		max = defaultTxRetryMaxBackoff
+	}
+	return fullJitterBackoff(base, max, attempt, rnd)
+}
+
+// fullJitterBackoff return a random duration between zero and min(max, base * 2^(attempt-1))
+func fullJitterBackoff(base, max time.Duration, attempt int, rnd *lockedRand) time.Duration {
+	backoff := max
+	if shift := uint(attempt - 1); shift < 32 {
+		if b := base << shift; b > 0 && b < backoff {
+			backoff = b
+		}
+	}
+	if backoff <= 0 {
+		return 0
+	}
+	return time.Duration(rnd.Int63n(int64(backoff)))
+}
+
+// WithTransactionRetry run fn with WithTransaction, and retry the whole transaction on deadlock or serialization failure
+// the error is wrapped with TxRetryBudgetError when the next retry would start after retry.MaxTotal, if MaxTotal is set
+// fn might be called more than once, so it should not have side effect outside the transaction. fn is called once with WithNoRetry
+func (db *DB) WithTransactionRetry(ctx context.Context, opts *TxOptions, retry TxRetryOptions, fn func(ctx context.Context, tx *Tx) error) error {
+	now := retry.now
+	if now == nil {
+		now = time.Now
+	}
+	sleep := retry.sleep
+	if sleep == nil {
+		sleep = sleepContext
+	}
+
+	start := now()
+	for attempt := 1; ; attempt++ {
+		err := db.WithTransaction(ctx, opts, fn)
+		if err == nil || !ClassifyRetry(err).Retryable || noRetryFromContext(ctx) {
+			return err
+		}
+
+		backoff := retry.backoff(attempt, db.rand)
+		if retry.MaxTotal > 0 && now().Add(backoff).Sub(start) > retry.MaxTotal {
+			return &TxRetryBudgetError{Attempts: attempt, Err: err}
+		}
+		if err := sleep(ctx, backoff); err != nil {
+			return err
+		}
+	}
+}
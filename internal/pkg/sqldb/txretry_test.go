@@ -0,0 +1,143 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func TestWithTransactionRetry(t *testing.T) {
+	t.Parallel()
+
+	const query = "UPDATE accounts SET balance = balance - 1 WHERE id = 1"
+	db, leader, _ := newFakeDB(t)
+
+	var (
+		mu        sync.Mutex
+		deadlocks int
+	)
+	leader.setHandler(func(ctx context.Context, q string, args []driver.NamedValue) fakeResult {
+		mu.Lock()
+		defer mu.Unlock()
+		if q == query && deadlocks > 0 {
+			deadlocks--
+			return fakeResult{err: &pq.Error{Code: "40P01"}}
+		}
+		return fakeResult{rowsAffected: 1}
+	})
+
+	newRetry := func(clock *fakeClock) TxRetryOptions {
+		return TxRetryOptions{
+			BaseBackoff: time.Millisecond * 100,
+			MaxBackoff:  time.Second,
+			MaxTotal:    time.Second * 5,
+			now:         clock.Now,
+			sleep: func(ctx context.Context, d time.Duration) error {
+				if d > time.Second {
+					t.Errorf("expecting backoff to be capped by MaxBackoff, got %s", d)
+				}
+				// every attempt take 100ms
+				clock.Add(d + time.Millisecond*100)
+				return nil
+			},
+		}
+	}
+	fn := func(ctx context.Context, tx *Tx) error {
+		_, err := tx.ExecContext(ctx, query)
+		return err
+	}
+
+	// retried until success
+	mu.Lock()
+	deadlocks = 2
+	mu.Unlock()
+	if err := db.WithTransactionRetry(context.Background(), nil, newRetry(&fakeClock{now: time.Now()}), fn); err != nil {
+		t.Fatalf("expecting retry to succeed, got %v", err)
+	}
+	if n := leader.count(query); n != 3 {
+		t.Errorf("expecting 3 attempts, got %d", n)
+	}
+
+	// sustained deadlock stop when the budget is exhausted
+	mu.Lock()
+	deadlocks = 1000
+	mu.Unlock()
+	clock := &fakeClock{now: time.Now()}
+	start := clock.Now()
+	err := db.WithTransactionRetry(context.Background(), nil, newRetry(clock), fn)
+	if !errors.Is(err, ErrTxRetryBudgetExhausted) {
+		t.Fatalf("expecting ErrTxRetryBudgetExhausted, got %v", err)
+	}
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != "40P01" {
+		t.Errorf("expecting the last deadlock error to be wrapped, got %v", err)
+	}
+	// the last attempt is started within the budget
+	if elapsed := clock.Now().Sub(start); elapsed > time.Second*5+time.Millisecond*100 {
+		t.Errorf("expecting retries to stop within the budget, elapsed %s", elapsed)
+	}
+	var budgetErr *TxRetryBudgetError
+	if !errors.As(err, &budgetErr) || budgetErr.Attempts < 2 {
+		t.Errorf("expecting more than one attempt, got %+v", budgetErr)
+	}
+
+	// zero MaxTotal doesn't limit the retry, and the first retry wait up to BaseBackoff
+	mu.Lock()
+	deadlocks = 3
+	mu.Unlock()
+	unlimited := newRetry(&fakeClock{now: time.Now()})
+	unlimited.MaxTotal = 0
+	var backoffs []time.Duration
+	sleep := unlimited.sleep
+	unlimited.sleep = func(ctx context.Context, d time.Duration) error {
+		backoffs = append(backoffs, d)
+		return sleep(ctx, d)
+	}
+	if err := db.WithTransactionRetry(context.Background(), nil, unlimited, fn); err != nil {
+		t.Fatalf("expecting retry without budget to succeed, got %v", err)
+	}
+	if len(backoffs) != 3 || backoffs[0] > unlimited.BaseBackoff {
+		t.Errorf("expecting 3 retries with the first backoff up to %s, got %v", unlimited.BaseBackoff, backoffs)
+	}
+
+	// BaseBackoff alone wait a non-zero backoff up to the default MaxBackoff
+	mu.Lock()
+	deadlocks = 4
+	mu.Unlock()
+	baseOnly := TxRetryOptions{BaseBackoff: time.Millisecond * 100}
+	backoffs = nil
+	baseOnly.sleep = func(ctx context.Context, d time.Duration) error {
+		backoffs = append(backoffs, d)
+		return nil
+	}
+	if err := db.WithTransactionRetry(context.Background(), nil, baseOnly, fn); err != nil {
+		t.Fatalf("expecting retry with BaseBackoff only to succeed, got %v", err)
+	}
+	var total time.Duration
+	for _, d := range backoffs {
+		if d > defaultTxRetryMaxBackoff {
+			t.Errorf("expecting backoff to be capped by the default MaxBackoff, got %s", d)
+		}
+		total += d
+	}
+	if len(backoffs) != 4 || total == 0 {
+		t.Errorf("expecting 4 non-zero backoffs, got %v", backoffs)
+	}
+
+	// non-retryable error is returned immediately
+	before := leader.count("BEGIN")
+	err = db.WithTransactionRetry(context.Background(), nil, newRetry(&fakeClock{now: time.Now()}), func(ctx context.Context, tx *Tx) error {
+		return errors.New("invalid input")
+	})
+	if err == nil || errors.Is(err, ErrTxRetryBudgetExhausted) {
+		t.Errorf("expecting non-retryable error, got %v", err)
+	}
+	if n := leader.count("BEGIN") - before; n != 1 {
+		t.Errorf("expecting 1 attempt for non-retryable error, got %d", n)
+	}
+}
@@ -0,0 +1,104 @@
+package sqldb
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	errNoUnitOfWork      = errors.New("sqldb: context has no unit of work")
+	errUnitOfWorkDone    = errors.New("sqldb: unit of work is already committed or rolled back")
+	errUnitOfWorkOtherDB = errors.New("sqldb: unit of work transaction is began in other DB")
+)
+
+type unitOfWorkKey struct{}
+
+// unitOfWork is the lazily began transaction of WithUnitOfWork context
+type unitOfWork struct {
+	// ctx is the context of the transaction, the transaction is rolled back by database/sql when it is done
+	ctx context.Context
+
+	mu   sync.Mutex
+	db   *DB
+	tx   *Tx
+	done bool
+}
+
+// WithUnitOfWork return a context that begin a transaction in leader on the first write using the context
+// after the transaction is began, all reads and writes using the context go to the transaction until
+// CommitUnitOfWork or RollbackUnitOfWork is called. Reads before the first write are routed as usual
+func WithUnitOfWork(ctx context.Context) context.Context {
+	uow := &unitOfWork{}
+	ctx = context.WithValue(ctx, unitOfWorkKey{}, uow)
+	uow.ctx = ctx
+	return ctx
+}
+
+// CommitUnitOfWork commit the transaction of the unit of work, nothing is committed when there is no write
+func CommitUnitOfWork(ctx context.Context) error {
+	return endUnitOfWork(ctx, func(tx *Tx) error {
+		return tx.Commit()
+	})
+}
+
+// RollbackUnitOfWork rollback the transaction of the unit of work, nothing is rolled back when there is no write
+func RollbackUnitOfWork(ctx context.Context) error {
+	return endUnitOfWork(ctx, func(tx *Tx) error {
+		return tx.Rollback()
+	})
+}
+
+func endUnitOfWork(ctx context.Context, end func(tx *Tx) error) error {
+	uow, ok := ctx.Value(unitOfWorkKey{}).(*unitOfWork)
+	if !ok {
+		return errNoUnitOfWork
+	}
+	uow.mu.Lock()
+	defer uow.mu.Unlock()
+	if uow.done {
+		return errUnitOfWorkDone
+	}
+	uow.done = true
+	if uow.tx == nil {
+		return nil
+	}
+	return uow.db.constraintError(lockTimeoutError(end(uow.tx)))
+}
+
+// unitOfWorkTx return the transaction of the context unit of work, the transaction is began when begin is true
+// ok is false when the context has no unit of work, or the transaction is not began and begin is false
+// the transaction of WithTransaction context is always returned first
+func (db *DB) unitOfWorkTx(ctx context.Context, begin bool) (tx *Tx, ok bool, err error) {
+	if tx, ok := db.contextTx(ctx); ok {
+		return tx, true, nil
+	}
+	uow, ok := ctx.Value(unitOfWorkKey{}).(*unitOfWork)
+	if !ok {
+		return nil, false, nil
+	}
+	uow.mu.Lock()
+	defer uow.mu.Unlock()
+
+	switch {
+	case uow.tx != nil && !uow.done:
+		if uow.db != db {
+			if begin {
+				return nil, false, errUnitOfWorkOtherDB
+			}
+			return nil, false, nil
+		}
+		return uow.tx, true, nil
+	case !begin:
+		return nil, false, nil
+	case uow.done:
+		return nil, false, errUnitOfWorkDone
+	}
+
+	tx, err = db.BeginTx(uow.ctx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	uow.db, uow.tx = db, tx
+	return tx, true, nil
+}
@@ -0,0 +1,154 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"sync"
+	"testing"
+)
+
+func TestUnitOfWork(t *testing.T) {
+	t.Parallel()
+
+	db, leader, follower := newFakeDB(t)
+	var (
+		mu    sync.Mutex
+		conns = make(map[string]int64)
+	)
+	handler := func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		mu.Lock()
+		conns[query] = fakeConnID(ctx)
+		mu.Unlock()
+		return fakeResult{columns: []string{"id"}, rows: [][]driver.Value{{int64(1)}}, rowsAffected: 1}
+	}
+	leader.setHandler(handler)
+	follower.setHandler(handler)
+
+	ctx := WithUnitOfWork(context.Background())
+	var id int
+	// read before the first write doesn't begin the transaction
+	if err := db.GetContext(ctx, &id, "SELECT id FROM users WHERE id = 1"); err != nil {
+		t.Fatal(err)
+	}
+	if follower.count("SELECT id FROM users WHERE id = 1") != 1 || leader.count("BEGIN") != 0 {
+		t.Fatal("expecting read before the first write to be routed to follower without transaction")
+	}
+
+	if _, err := db.ExecContext(ctx, "UPDATE users SET name = 'a' WHERE id = 1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, "INSERT INTO audits (user_id) VALUES (1)"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.GetContext(ctx, &id, "SELECT id FROM audits WHERE user_id = 1"); err != nil {
+		t.Fatal(err)
+	}
+	if n := leader.count("BEGIN"); n != 1 {
+		t.Errorf("expecting one transaction to be began on the first write, got %d", n)
+	}
+	if leader.count("SELECT id FROM audits WHERE user_id = 1") != 1 {
+		t.Error("expecting read after the first write to use the transaction")
+	}
+	mu.Lock()
+	tx := conns["UPDATE users SET name = 'a' WHERE id = 1"]
+	if conns["INSERT INTO audits (user_id) VALUES (1)"] != tx || conns["SELECT id FROM audits WHERE user_id = 1"] != tx {
+		t.Errorf("expecting all queries to use the transaction connection, got %v", conns)
+	}
+	mu.Unlock()
+	if leader.count("COMMIT") != 0 {
+		t.Fatal("expecting transaction to not be committed before CommitUnitOfWork")
+	}
+
+	if err := CommitUnitOfWork(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if leader.count("COMMIT") != 1 {
+		t.Error("expecting transaction to be committed")
+	}
+	if _, err := db.ExecContext(ctx, "UPDATE users SET name = 'b' WHERE id = 1"); err != errUnitOfWorkDone {
+		t.Errorf("expecting errUnitOfWorkDone, got %v", err)
+	}
+	if err := CommitUnitOfWork(ctx); err != errUnitOfWorkDone {
+		t.Errorf("expecting errUnitOfWorkDone, got %v", err)
+	}
+
+	// rollback discard the writes
+	ctx = WithUnitOfWork(context.Background())
+	if _, err := db.ExecContext(ctx, "DELETE FROM users WHERE id = 1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := RollbackUnitOfWork(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if leader.count("ROLLBACK") != 1 || leader.count("COMMIT") != 1 {
+		t.Error("expecting transaction to be rolled back")
+	}
+
+	// unit of work without write doesn't begin transaction
+	ctx = WithUnitOfWork(context.Background())
+	if err := CommitUnitOfWork(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n := leader.count("BEGIN"); n != 2 {
+		t.Errorf("expecting no transaction for unit of work without write, got %d", n)
+	}
+	if err := CommitUnitOfWork(context.Background()); err != errNoUnitOfWork {
+		t.Errorf("expecting errNoUnitOfWork, got %v", err)
+	}
+}
+
+func TestUnitOfWorkUpsertAndCallFunction(t *testing.T) {
+	t.Parallel()
+
+	db, leader, _ := newFakeDB(t, WithWriteFunctions("archive_orders"))
+	var (
+		mu    sync.Mutex
+		conns = make(map[string]int64)
+	)
+	leader.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		mu.Lock()
+		conns[query] = fakeConnID(ctx)
+		mu.Unlock()
+		return fakeResult{columns: []string{"email", "name"}, rows: [][]driver.Value{{"a@example.com", "a"}}, rowsAffected: 1}
+	})
+
+	ctx := WithUnitOfWork(context.Background())
+	if _, err := db.ExecContext(ctx, "UPDATE users SET name = 'a' WHERE id = 1"); err != nil {
+		t.Fatal(err)
+	}
+	values := map[string]interface{}{"email": "a@example.com", "name": "a"}
+	if _, err := db.Upsert(ctx, "users", values, []string{"email"}, []string{"name"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	var u struct {
+		Email string `db:"email"`
+		Name  string `db:"name"`
+	}
+	if _, err := db.Upsert(ctx, "users", values, []string{"email"}, []string{"name"}, &u); err != nil {
+		t.Fatal(err)
+	}
+	var rows []struct {
+		Email string `db:"email"`
+		Name  string `db:"name"`
+	}
+	if err := db.CallFunction(ctx, &rows, "archive_orders"); err != nil {
+		t.Fatal(err)
+	}
+	if n := leader.count("BEGIN"); n != 1 {
+		t.Errorf("expecting Upsert and CallFunction to not begin another transaction, got %d", n)
+	}
+	if n := leader.count("COMMIT"); n != 0 {
+		t.Errorf("expecting no commit before CommitUnitOfWork, got %d", n)
+	}
+	mu.Lock()
+	tx := conns["UPDATE users SET name = 'a' WHERE id = 1"]
+	for q, id := range conns {
+		if q != "BEGIN" && id != tx {
+			t.Errorf("expecting %s to use the unit of work transaction", q)
+		}
+	}
+	mu.Unlock()
+	if err := CommitUnitOfWork(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
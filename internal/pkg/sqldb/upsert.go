@@ -0,0 +1,259 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/reflectx"
+)
+
+var (
+	errUpsertNoValues       = errors.New("sqldb: upsert values cannot be empty")
+	errUpsertNoConflictCols = errors.New("sqldb: upsert conflict columns cannot be empty")
+)
+
+// upsertInsertedColumn is the column returned by postgres upsert to tell whether the row is inserted
+const upsertInsertedColumn = "sqldb_upsert_inserted"
+
+// UpsertResult tell whether the upsert inserted a new row or the row is already exist
+type UpsertResult struct {
+	Inserted bool
+	// Conflicted is true when the row is already exist, the row is updated or left untouched with DO NOTHING
+	Conflicted bool
+}
+
+func newUpsertResult(inserted bool) UpsertResult {
+	return UpsertResult{Inserted: inserted, Conflicted: !inserted}
+}
+
+// Upsert insert values into table, or update the updateCols when the conflictCols is conflicted
+// nothing is updated on conflict when updateCols is empty
+// when dest is not nil, the resulting row is scanned into dest including the server defaults
+// postgres use RETURNING *, while mysql select the row by conflictCols in the same transaction
+// postgres use xmax to tell inserted from updated row, other drivers use the number of affected rows
+// inside WithTransaction or a unit of work, the upsert run in that transaction instead of a new one
+func (db *DB) Upsert(ctx context.Context, table string, values map[string]interface{}, conflictCols, updateCols []string, dest interface{}) (UpsertResult, error) {
+	if len(values) == 0 {
+		return UpsertResult{}, errUpsertNoValues
+	}
+	if len(conflictCols) == 0 {
+		return UpsertResult{}, errUpsertNoConflictCols
+	}
+
+	columns := make([]string, 0, len(values))
+	for col := range values {
+		columns = append(columns, col)
+	}
+	sort.Strings(columns)
+	if err := validateIdentifier(table); err != nil {
+		return UpsertResult{}, err
+	}
+	if err := validateIdentifier(columns...); err != nil {
+		return UpsertResult{}, err
+	}
+	if err := validateIdentifier(conflictCols...); err != nil {
+		return UpsertResult{}, err
+	}
+	if err := validateIdentifier(updateCols...); err != nil {
+		return UpsertResult{}, err
+	}
+	for _, col := range conflictCols {
+		if _, ok := values[col]; !ok {
+			return UpsertResult{}, fmt.Errorf("sqldb: upsert conflict column %s is not in values", col)
+		}
+	}
+
+	args := make([]interface{}, len(columns))
+	for i, col := range columns {
+		args[i] = values[col]
+	}
+	query := db.upsertQuery(table, columns, 1, conflictCols, updateCols)
+
+	uowTx, inTx, err := db.unitOfWorkTx(ctx, true)
+	if err != nil {
+		return UpsertResult{}, err
+	}
+
+	var result UpsertResult
+	err = db.run(ctx, &queryInfo{query: query, args: args, target: targetLeader, write: true}, func(ctx context.Context) error {
+		if inTx {
+			atomic.StoreInt32(&uowTx.wrote, 1)
+			var err error
+			if dest == nil {
+				result, err = db.upsertExec(ctx, uowTx, query, args)
+			} else {
+				result, err = db.upsertReturning(ctx, uowTx.Tx, table, query, args, values, conflictCols, dest)
+			}
+			return err
+		}
+		if dest == nil {
+			var err error
+			result, err = db.upsertExec(ctx, db.leader, query, args)
+			return err
+		}
+
+		tx, err := db.leader.BeginTxx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		result, err = db.upsertReturning(ctx, tx, table, query, args, values, conflictCols, dest)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		return tx.Commit()
+	})
+	return result, err
+}
+
+// upsertExec execute the upsert without returning the row
+func (db *DB) upsertExec(ctx context.Context, handle sqlx.ExtContext, query string, args []interface{}) (UpsertResult, error) {
+	if db.driver == "postgres" {
+		var inserted bool
+		err := handle.QueryRowxContext(ctx, query+" RETURNING (xmax = 0) AS "+upsertInsertedColumn, args...).Scan(&inserted)
+		// DO NOTHING doesn't return the existing row
+		if err == sql.ErrNoRows {
+			return newUpsertResult(false), nil
+		}
+		if err != nil {
+			return UpsertResult{}, err
+		}
+		return newUpsertResult(inserted), nil
+	}
+
+	res, err := handle.ExecContext(ctx, query, args...)
+	if err != nil {
+		return UpsertResult{}, err
+	}
+	// mysql return 1 for inserted row, 2 for updated row and 0 for untouched row
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return UpsertResult{}, err
+	}
+	return newUpsertResult(affected == 1), nil
+}
+
+func (db *DB) upsertReturning(ctx context.Context, tx *sqlx.Tx, table, query string, args []interface{}, values map[string]interface{}, conflictCols []string, dest interface{}) (UpsertResult, error) {
+	var result UpsertResult
+	if db.driver == "postgres" {
+		inserted, err := upsertScanReturning(ctx, tx, query+" RETURNING *, (xmax = 0) AS "+upsertInsertedColumn, args, dest)
+		if err == nil {
+			return newUpsertResult(inserted), nil
+		}
+		// DO NOTHING doesn't return the existing row, select the row by the conflict columns
+		if err != sql.ErrNoRows {
+			return UpsertResult{}, err
+		}
+		result = newUpsertResult(false)
+	} else {
+		var err error
+		if result, err = db.upsertExec(ctx, tx, query, args); err != nil {
+			return UpsertResult{}, err
+		}
+	}
+
+	where := make([]string, len(conflictCols))
+	whereArgs := make([]interface{}, len(conflictCols))
+	for i, col := range conflictCols {
+		where[i] = db.QuoteIdentifier(col) + " = ?"
+		whereArgs[i] = values[col]
+	}
+	selectQuery := db.Rebind(fmt.Sprintf("SELECT * FROM %s WHERE %s", db.QuoteIdentifier(table), strings.Join(where, " AND ")))
+	return result, tx.GetContext(ctx, dest, selectQuery, whereArgs...)
+}
+
+// upsertScanReturning scan the returned row into dest struct, and the upsertInsertedColumn into inserted
+// sql.ErrNoRows is returned when no row is returned
+func upsertScanReturning(ctx context.Context, tx *sqlx.Tx, query string, args []interface{}, dest interface{}) (bool, error) {
+	value := reflect.ValueOf(dest)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return false, fmt.Errorf("sqldb: Upsert destination must be a pointer to a struct, got %T", dest)
+	}
+
+	rows, err := tx.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return false, err
+		}
+		return false, sql.ErrNoRows
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return false, err
+	}
+	var (
+		inserted   bool
+		traversals = tx.Mapper.TraversalsByName(value.Elem().Type(), columns)
+		fields     = make([]interface{}, len(columns))
+	)
+	for i, col := range columns {
+		if col == upsertInsertedColumn {
+			fields[i] = &inserted
+			continue
+		}
+		if len(traversals[i]) == 0 {
+			return false, fmt.Errorf("sqldb: missing destination name %s in %T", col, dest)
+		}
+		fields[i] = reflectx.FieldByIndexes(value.Elem(), traversals[i]).Addr().Interface()
+	}
+	if err := rows.Scan(fields...); err != nil {
+		return false, err
+	}
+	return inserted, rows.Close()
+}
+
+// upsertQuery build a rebound upsert query for rowCount rows of columns
+func (db *DB) upsertQuery(table string, columns []string, rowCount int, conflictCols, updateCols []string) string {
+	quotedColumns := make([]string, len(columns))
+	for i, col := range columns {
+		quotedColumns[i] = db.QuoteIdentifier(col)
+	}
+	placeholder := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+	placeholders := make([]string, rowCount)
+	for i := range placeholders {
+		placeholders[i] = placeholder
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", db.QuoteIdentifier(table), strings.Join(quotedColumns, ", "), strings.Join(placeholders, ", "))
+
+	if db.driver == "mysql" {
+		sets := make([]string, 0, len(updateCols))
+		for _, col := range updateCols {
+			quoted := db.QuoteIdentifier(col)
+			sets = append(sets, fmt.Sprintf("%s = VALUES(%s)", quoted, quoted))
+		}
+		// assign the conflict column to itself to do nothing on conflict without ignoring other errors
+		if len(sets) == 0 {
+			quoted := db.QuoteIdentifier(conflictCols[0])
+			sets = append(sets, fmt.Sprintf("%s = %s", quoted, quoted))
+		}
+		return db.Rebind(query + " ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", "))
+	}
+
+	quotedConflict := make([]string, len(conflictCols))
+	for i, col := range conflictCols {
+		quotedConflict[i] = db.QuoteIdentifier(col)
+	}
+	query += fmt.Sprintf(" ON CONFLICT (%s)", strings.Join(quotedConflict, ", "))
+	if len(updateCols) == 0 {
+		return db.Rebind(query + " DO NOTHING")
+	}
+	sets := make([]string, len(updateCols))
+	for i, col := range updateCols {
+		quoted := db.QuoteIdentifier(col)
+		sets[i] = fmt.Sprintf("%s = EXCLUDED.%s", quoted, quoted)
+	}
+	return db.Rebind(query + " DO UPDATE SET " + strings.Join(sets, ", "))
+}
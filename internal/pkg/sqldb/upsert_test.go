@@ -0,0 +1,159 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUpsertQuery(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		driver     string
+		updateCols []string
+		expect     string
+	}{
+		{
+			driver:     "postgres",
+			updateCols: []string{"name"},
+			expect:     "INSERT INTO users (email, name) VALUES ($1, $2) ON CONFLICT (email) DO UPDATE SET name = EXCLUDED.name",
+		},
+		{
+			driver: "postgres",
+			expect: "INSERT INTO users (email, name) VALUES ($1, $2) ON CONFLICT (email) DO NOTHING",
+		},
+		{
+			driver:     "mysql",
+			updateCols: []string{"name"},
+			expect:     "INSERT INTO users (email, name) VALUES (?, ?) ON DUPLICATE KEY UPDATE name = VALUES(name)",
+		},
+		{
+			driver: "mysql",
+			expect: "INSERT INTO users (email, name) VALUES (?, ?) ON DUPLICATE KEY UPDATE email = email",
+		},
+	}
+
+	for _, c := range cases {
+		db := &DB{driver: c.driver}
+		if got := db.upsertQuery("users", []string{"email", "name"}, 1, []string{"email"}, c.updateCols); got != c.expect {
+			t.Errorf("%s: expecting %s, got %s", c.driver, c.expect, got)
+		}
+	}
+}
+
+func TestUpsertReturning(t *testing.T) {
+	t.Parallel()
+
+	createdAt := time.Date(2019, 10, 17, 7, 1, 46, 0, time.UTC)
+	returning := func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+		return fakeResult{
+			columns: []string{"email", "name", "created_at"},
+			rows:    [][]driver.Value{{"a@example.com", "a", createdAt}},
+		}
+	}
+
+	type user struct {
+		Email     string    `db:"email"`
+		Name      string    `db:"name"`
+		CreatedAt time.Time `db:"created_at"`
+	}
+
+	for _, name := range []string{"postgres", "mysql"} {
+		db, leader, _ := newFakeDB(t)
+		db.driver = name
+		leader.setHandler(returning)
+
+		var u user
+		values := map[string]interface{}{"email": "a@example.com", "name": "a"}
+		if _, err := db.Upsert(context.Background(), "users", values, []string{"email"}, []string{"name"}, &u); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !u.CreatedAt.Equal(createdAt) || u.Email != "a@example.com" {
+			t.Errorf("%s: expecting server default to be returned, got %+v", name, u)
+		}
+
+		queries := strings.Join(leader.Queries(), "\n")
+		if name == "postgres" && !strings.Contains(queries, "RETURNING *") {
+			t.Errorf("%s: expecting RETURNING *, got %s", name, queries)
+		}
+		if name == "mysql" && !strings.Contains(queries, "SELECT * FROM users WHERE email = ?") {
+			t.Errorf("%s: expecting select by conflict columns, got %s", name, queries)
+		}
+		if !strings.Contains(queries, "BEGIN") || !strings.Contains(queries, "COMMIT") {
+			t.Errorf("%s: expecting upsert in a transaction, got %s", name, queries)
+		}
+	}
+}
+
+func TestUpsertResult(t *testing.T) {
+	t.Parallel()
+
+	type user struct {
+		Email string `db:"email"`
+		Name  string `db:"name"`
+	}
+
+	cases := []struct {
+		name     string
+		driver   string
+		existing bool
+		dest     bool
+		expect   UpsertResult
+	}{
+		{name: "postgres new row", driver: "postgres", expect: UpsertResult{Inserted: true}},
+		{name: "postgres existing row", driver: "postgres", existing: true, expect: UpsertResult{Conflicted: true}},
+		{name: "postgres new row with dest", driver: "postgres", dest: true, expect: UpsertResult{Inserted: true}},
+		{name: "postgres existing row with dest", driver: "postgres", existing: true, dest: true, expect: UpsertResult{Conflicted: true}},
+		{name: "mysql new row", driver: "mysql", expect: UpsertResult{Inserted: true}},
+		{name: "mysql existing row", driver: "mysql", existing: true, expect: UpsertResult{Conflicted: true}},
+	}
+
+	for _, c := range cases {
+		db, leader, _ := newFakeDB(t)
+		db.driver = c.driver
+		existing := c.existing
+		leader.setHandler(func(ctx context.Context, query string, args []driver.NamedValue) fakeResult {
+			switch {
+			case strings.HasSuffix(query, "RETURNING (xmax = 0) AS "+upsertInsertedColumn):
+				// DO NOTHING return no row on conflict
+				if existing {
+					return fakeResult{columns: []string{upsertInsertedColumn}}
+				}
+				return fakeResult{columns: []string{upsertInsertedColumn}, rows: [][]driver.Value{{true}}}
+			case strings.Contains(query, "RETURNING *"):
+				if existing {
+					return fakeResult{columns: []string{"email", "name", upsertInsertedColumn}}
+				}
+				return fakeResult{columns: []string{"email", "name", upsertInsertedColumn}, rows: [][]driver.Value{{"a@example.com", "a", true}}}
+			case strings.HasPrefix(query, "SELECT"):
+				return fakeResult{columns: []string{"email", "name"}, rows: [][]driver.Value{{"a@example.com", "old"}}}
+			case strings.HasPrefix(query, "INSERT"):
+				if existing {
+					return fakeResult{rowsAffected: 0}
+				}
+				return fakeResult{rowsAffected: 1}
+			}
+			return fakeResult{}
+		})
+
+		var dest interface{}
+		u := &user{}
+		if c.dest {
+			dest = u
+		}
+		values := map[string]interface{}{"email": "a@example.com", "name": "a"}
+		result, err := db.Upsert(context.Background(), "users", values, []string{"email"}, nil, dest)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if result != c.expect {
+			t.Errorf("%s: expecting %+v, got %+v", c.name, c.expect, result)
+		}
+		if c.dest && u.Email != "a@example.com" {
+			t.Errorf("%s: expecting row to be scanned, got %+v", c.name, u)
+		}
+	}
+}
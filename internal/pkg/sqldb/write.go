@@ -0,0 +1,69 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+)
+
+// Write execute the query with NamedExecContext when the query has named parameter like :name, and ExecContext otherwise
+// named query require arg to be a struct or map. For positional query, arg is the only argument, a []interface{} is the list
+// of arguments, and nil is no argument
+func (db *DB) Write(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	if hasNamedParameter(query) {
+		if !namedArg(arg) {
+			return nil, fmt.Errorf("sqldb: Write named query require a struct or map argument, got %T", arg)
+		}
+		return db.NamedExecContext(ctx, query, arg)
+	}
+
+	switch args := arg.(type) {
+	case nil:
+		return db.ExecContext(ctx, query)
+	case []interface{}:
+		return db.ExecContext(ctx, query, args...)
+	}
+	return db.ExecContext(ctx, query, arg)
+}
+
+// hasNamedParameter return true when query contain :name outside of quoted string, postgres cast like ::text is not a parameter
+func hasNamedParameter(query string) bool {
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == ':' && i+1 < len(query):
+			if query[i+1] == ':' {
+				i++
+				continue
+			}
+			if next := query[i+1]; next == '_' || (next >= 'a' && next <= 'z') || (next >= 'A' && next <= 'Z') {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// namedArg return true when arg can be used by NamedExec, struct that is a driver value like time.Time is not named
+func namedArg(arg interface{}) bool {
+	if _, ok := arg.(driver.Valuer); ok {
+		return false
+	}
+	t := reflect.TypeOf(arg)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil {
+		return false
+	}
+	return t.Kind() == reflect.Map || (t.Kind() == reflect.Struct && t != _timeType)
+}
@@ -0,0 +1,66 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	db, leader, _ := newFakeDB(t)
+	var args [][]driver.NamedValue
+	leader.setHandler(func(ctx context.Context, query string, a []driver.NamedValue) fakeResult {
+		values := make([]driver.NamedValue, len(a))
+		copy(values, a)
+		args = append(args, values)
+		return fakeResult{rowsAffected: 1}
+	})
+
+	type user struct {
+		ID   int64  `db:"id"`
+		Name string `db:"name"`
+	}
+	if _, err := db.Write(context.Background(), "UPDATE users SET name = :name WHERE id = :id", user{ID: 1, Name: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Write(context.Background(), "UPDATE users SET name = :name WHERE id = :id", map[string]interface{}{"id": int64(2), "name": "bob"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Write(context.Background(), "UPDATE users SET name = ? WHERE id = ?", []interface{}{"carol", int64(3)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Write(context.Background(), "DELETE FROM users WHERE id = ?", int64(4)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Write(context.Background(), "UPDATE users SET name = 'a:b', tags = NULL::text[] WHERE id = 5", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []string{
+		"UPDATE users SET name = ? WHERE id = ?",
+		"UPDATE users SET name = ? WHERE id = ?",
+		"UPDATE users SET name = ? WHERE id = ?",
+		"DELETE FROM users WHERE id = ?",
+		"UPDATE users SET name = 'a:b', tags = NULL::text[] WHERE id = 5",
+	}
+	if queries := leader.Queries(); !reflect.DeepEqual(queries, expect) {
+		t.Errorf("expecting queries %v, got %v", expect, queries)
+	}
+	expectArgs := [][]interface{}{{"alice", int64(1)}, {"bob", int64(2)}, {"carol", int64(3)}, {int64(4)}, {}}
+	for i, a := range args {
+		values := make([]interface{}, len(a))
+		for j := range a {
+			values[j] = a[j].Value
+		}
+		if !reflect.DeepEqual(values, expectArgs[i]) {
+			t.Errorf("query %d: expecting args %v, got %v", i, expectArgs[i], values)
+		}
+	}
+
+	if _, err := db.Write(context.Background(), "UPDATE users SET name = :name WHERE id = :id", int64(1)); err == nil {
+		t.Error("expecting error for named query with positional argument")
+	}
+}